@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"os"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/format"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/hooks"
 	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/log"
 	"github.com/charmbracelet/crush/internal/pubsub"
@@ -24,6 +26,7 @@ import (
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/shell"
 )
 
 type App struct {
@@ -50,26 +53,30 @@ type App struct {
 
 	// global context and cleanup functions
 	globalCtx    context.Context
-	cleanupFuncs []func()
+	cleanupFuncs *csync.Slice[func()]
 }
 
 // New initializes a new applcation instance.
 func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	q := db.New(conn)
-	sessions := session.NewService(q)
+	sessions := session.NewService(q, conn)
 	messages := message.NewService(q)
 	files := history.NewService(q, conn)
 	skipPermissionsRequests := cfg.Permissions != nil && cfg.Permissions.SkipRequests
 	allowedTools := []string{}
+	var permissionRules []config.PermissionRule
 	if cfg.Permissions != nil && cfg.Permissions.AllowedTools != nil {
 		allowedTools = cfg.Permissions.AllowedTools
 	}
+	if cfg.Permissions != nil {
+		permissionRules = cfg.Permissions.Rules
+	}
 
 	app := &App{
 		Sessions:    sessions,
 		Messages:    messages,
 		History:     files,
-		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools),
+		Permissions: permission.NewPermissionService(q, cfg.WorkingDir(), skipPermissionsRequests, allowedTools, permissionRules),
 		LSPClients:  make(map[string]*lsp.Client),
 
 		globalCtx: ctx,
@@ -77,6 +84,7 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 		config: cfg,
 
 		watcherCancelFuncs: csync.NewSlice[context.CancelFunc](),
+		cleanupFuncs:       csync.NewSlice[func()](),
 
 		events:          make(chan tea.Msg, 100),
 		serviceEventsWG: &sync.WaitGroup{},
@@ -105,16 +113,20 @@ func (app *App) Config() *config.Config {
 }
 
 // RunNonInteractive handles the execution flow when a prompt is provided via
-// CLI flag.
-func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool) error {
+// CLI flag. The prompt continues the session with the given ID, or a new
+// session when it is empty. The output format selects between plain text
+// and structured JSON output.
+func (app *App) RunNonInteractive(ctx context.Context, sessionID, prompt string, quiet bool, outputFormat OutputFormat) error {
 	slog.Info("Running in non-interactive mode")
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	structured := outputFormat != "" && outputFormat != OutputFormatText
+
 	// Start spinner if not in quiet mode.
 	var spinner *format.Spinner
-	if !quiet {
+	if !quiet && !structured {
 		spinner = format.NewSpinner(ctx, cancel, "Generating")
 		spinner.Start()
 	}
@@ -128,32 +140,49 @@ func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool
 	}
 	defer stopSpinner()
 
-	const maxPromptLengthForTitle = 100
-	titlePrefix := "Non-interactive: "
-	var titleSuffix string
-
-	if len(prompt) > maxPromptLengthForTitle {
-		titleSuffix = prompt[:maxPromptLengthForTitle] + "..."
+	var sess session.Session
+	if sessionID != "" {
+		var err error
+		sess, err = app.Sessions.Get(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to get session %s: %w", sessionID, err)
+		}
+		slog.Info("Continuing session for non-interactive run", "session_id", sess.ID)
 	} else {
-		titleSuffix = prompt
-	}
-	title := titlePrefix + titleSuffix
+		const maxPromptLengthForTitle = 100
+		titlePrefix := "Non-interactive: "
+		var titleSuffix string
+
+		if len(prompt) > maxPromptLengthForTitle {
+			titleSuffix = prompt[:maxPromptLengthForTitle] + "..."
+		} else {
+			titleSuffix = prompt
+		}
+		title := titlePrefix + titleSuffix
 
-	sess, err := app.Sessions.Create(ctx, title)
-	if err != nil {
-		return fmt.Errorf("failed to create session for non-interactive mode: %w", err)
+		var err error
+		sess, err = app.Sessions.Create(ctx, title)
+		if err != nil {
+			return fmt.Errorf("failed to create session for non-interactive mode: %w", err)
+		}
+		slog.Info("Created session for non-interactive run", "session_id", sess.ID)
 	}
-	slog.Info("Created session for non-interactive run", "session_id", sess.ID)
 
-	// Automatically approve all permission requests for this non-interactive session
+	// Automatically approve the permission requests for this non-interactive
+	// session that the permission rules don't decide.
 	app.Permissions.AutoApproveSession(sess.ID)
 
+	if structured {
+		return app.runStructured(ctx, os.Stdout, outputFormat, sess.ID, prompt)
+	}
+
 	done, err := app.CoderAgent.Run(ctx, sess.ID, prompt)
 	if err != nil {
 		return fmt.Errorf("failed to start agent processing stream: %w", err)
 	}
 
 	messageEvents := app.Messages.Subscribe(ctx)
+	deltaEvents := app.Messages.SubscribeDeltas(ctx)
 	messageReadBytes := make(map[string]int)
 
 	for {
@@ -200,6 +229,18 @@ func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool
 				messageReadBytes[msg.ID] = len(content)
 			}
 
+		case event := <-deltaEvents:
+			delta := event.Payload
+			if delta.SessionID != sess.ID || delta.Type != message.ContentDelta {
+				continue
+			}
+			// Missed deltas are printed with the next update of the message.
+			if part, ok := delta.Since(messageReadBytes[delta.MessageID]); ok && part != "" {
+				stopSpinner()
+				fmt.Print(part)
+				messageReadBytes[delta.MessageID] += len(part)
+			}
+
 		case <-ctx.Done():
 			stopSpinner()
 			return ctx.Err()
@@ -216,16 +257,20 @@ func (app *App) setupEvents() {
 	app.eventsCtx = ctx
 	setupSubscriber(ctx, app.serviceEventsWG, "sessions", app.Sessions.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "messages", app.Messages.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "message-deltas", app.Messages.SubscribeDeltas, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions", app.Permissions.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions-notifications", app.Permissions.SubscribeNotifications, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "history", app.History.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "mcp", agent.SubscribeMCPEvents, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "lsp", SubscribeLSPEvents, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "background", shell.SubscribeBackgroundEvents, app.events)
+	app.setupPermissionHooks(ctx)
+	app.setupSessionShells(ctx)
 	cleanupFunc := func() {
 		cancel()
 		app.serviceEventsWG.Wait()
 	}
-	app.cleanupFuncs = append(app.cleanupFuncs, cleanupFunc)
+	app.cleanupFuncs.Append(cleanupFunc)
 }
 
 func setupSubscriber[T any](
@@ -261,6 +306,40 @@ func setupSubscriber[T any](
 	})
 }
 
+// setupPermissionHooks runs the permission request hooks when a tool call
+// asks the user for permission.
+func (app *App) setupPermissionHooks(ctx context.Context) {
+	runner := hooks.New(app.config.Hooks, app.config.WorkingDir())
+	if runner == nil {
+		return
+	}
+	app.serviceEventsWG.Go(func() {
+		for event := range app.Permissions.Subscribe(ctx) {
+			request := event.Payload
+			err := runner.Run(ctx, hooks.Input{
+				Event:      hooks.PermissionRequest,
+				SessionID:  request.SessionID,
+				Permission: &request,
+			})
+			if err != nil {
+				slog.Warn("Hook failed", "event", hooks.PermissionRequest, "error", err)
+			}
+		}
+	})
+}
+
+// setupSessionShells forgets the shell of a session when the session is
+// deleted.
+func (app *App) setupSessionShells(ctx context.Context) {
+	app.serviceEventsWG.Go(func() {
+		for event := range app.Sessions.Subscribe(ctx) {
+			if event.Type == pubsub.DeletedEvent {
+				shell.RemoveSessionShell(event.Payload.ID)
+			}
+		}
+	})
+}
+
 func (app *App) InitCoderAgent() error {
 	coderAgentCfg := app.config.Agents["coder"]
 	if coderAgentCfg.ID == "" {
@@ -282,7 +361,7 @@ func (app *App) InitCoderAgent() error {
 	}
 
 	// Add MCP client cleanup to shutdown process
-	app.cleanupFuncs = append(app.cleanupFuncs, agent.CloseMCPClients)
+	app.cleanupFuncs.Append(agent.CloseMCPClients)
 
 	setupSubscriber(app.eventsCtx, app.serviceEventsWG, "coderAgent", app.CoderAgent.Subscribe, app.events)
 	return nil
@@ -295,9 +374,15 @@ func (app *App) Subscribe(program *tea.Program) {
 		program.Quit()
 	})
 
+	app.SubscribeFunc(program.Send)
+}
+
+// SubscribeFunc calls fn with every service event until the application
+// shuts down. It is how frontends other than the TUI consume events.
+func (app *App) SubscribeFunc(fn func(msg tea.Msg)) {
 	app.tuiWG.Add(1)
 	tuiCtx, tuiCancel := context.WithCancel(app.globalCtx)
-	app.cleanupFuncs = append(app.cleanupFuncs, func() {
+	app.cleanupFuncs.Append(func() {
 		slog.Debug("Cancelling TUI message handler")
 		tuiCancel()
 		app.tuiWG.Wait()
@@ -314,7 +399,7 @@ func (app *App) Subscribe(program *tea.Program) {
 				slog.Debug("TUI message channel closed")
 				return
 			}
-			program.Send(msg)
+			fn(msg)
 		}
 	}
 }
@@ -329,6 +414,9 @@ func (app *App) Shutdown() {
 		cancel()
 	}
 
+	// Stop the commands the agent left running in the background.
+	shell.KillBackgroundJobs()
+
 	// Wait for all LSP watchers to finish.
 	app.lspWatcherWG.Wait()
 
@@ -348,7 +436,7 @@ func (app *App) Shutdown() {
 	}
 
 	// Call call cleanup functions.
-	for _, cleanup := range app.cleanupFuncs {
+	for cleanup := range app.cleanupFuncs.Seq() {
 		if cleanup != nil {
 			cleanup()
 		}
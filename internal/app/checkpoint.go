@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+)
+
+// Checkpoint is a point a session can be reverted to, along with the prompt
+// that was sent at that point.
+type Checkpoint struct {
+	history.Checkpoint
+	Prompt string
+}
+
+// Checkpoints returns the checkpoints of a session, oldest first.
+func (app *App) Checkpoints(ctx context.Context, sessionID string) ([]Checkpoint, error) {
+	checkpoints, err := app.History.ListCheckpoints(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	result := make([]Checkpoint, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		msg, err := app.Messages.Get(ctx, cp.MessageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get checkpoint message: %w", err)
+		}
+		result = append(result, Checkpoint{
+			Checkpoint: cp,
+			Prompt:     msg.Content().Text,
+		})
+	}
+	return result, nil
+}
+
+// RevertSession restores the files of a session to the state they had right
+// before the given user message was sent, and removes that message and
+// everything after it from the conversation. An empty messageID reverts the
+// latest checkpoint. It returns the paths of the restored files.
+func (app *App) RevertSession(ctx context.Context, sessionID, messageID string) ([]string, error) {
+	if app.CoderAgent != nil && app.CoderAgent.IsSessionBusy(sessionID) {
+		return nil, fmt.Errorf("session %s is busy", sessionID)
+	}
+
+	var checkpoint history.Checkpoint
+	if messageID == "" {
+		checkpoints, err := app.History.ListCheckpoints(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+		}
+		if len(checkpoints) == 0 {
+			return nil, errors.New("session has no checkpoints")
+		}
+		checkpoint = checkpoints[len(checkpoints)-1]
+	} else {
+		var err error
+		checkpoint, err = app.History.GetCheckpointByMessage(ctx, messageID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no checkpoint for message %s", messageID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get checkpoint: %w", err)
+		}
+		if checkpoint.SessionID != sessionID {
+			return nil, fmt.Errorf("message %s does not belong to session %s", messageID, sessionID)
+		}
+	}
+
+	restored, err := app.History.RevertToCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		return restored, fmt.Errorf("failed to restore files: %w", err)
+	}
+	if err := app.truncateSession(ctx, sessionID, checkpoint.MessageID); err != nil {
+		return restored, err
+	}
+	return restored, nil
+}
+
+// truncateSession deletes the given message and every message after it,
+// together with the task sessions spawned by them.
+func (app *App) truncateSession(ctx context.Context, sessionID, messageID string) error {
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list messages: %w", err)
+	}
+	idx := -1
+	for i, msg := range msgs {
+		if msg.ID == messageID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("message %s not found in session", messageID)
+	}
+
+	removed := make(map[string]bool)
+	for _, msg := range msgs[idx:] {
+		for _, call := range msg.ToolCalls() {
+			if call.Name != agent.AgentToolName {
+				continue
+			}
+			// Task sessions use the tool call ID as their session ID.
+			if _, err := app.Sessions.Get(ctx, call.ID); err == nil {
+				if err := app.Sessions.Delete(ctx, call.ID); err != nil {
+					return fmt.Errorf("failed to delete task session: %w", err)
+				}
+			}
+		}
+		if err := app.Messages.Delete(ctx, msg.ID); err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+		removed[msg.ID] = true
+	}
+
+	session, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.SummaryMessageID != "" && removed[session.SummaryMessageID] {
+		session.SummaryMessageID = ""
+		if _, err := app.Sessions.Save(ctx, session); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// ExportFormat is the file format of an exported session.
+type ExportFormat string
+
+const (
+	ExportFormatMarkdown ExportFormat = "md"
+	ExportFormatJSON     ExportFormat = "json"
+	ExportFormatHTML     ExportFormat = "html"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []ExportFormat{ExportFormatMarkdown, ExportFormatJSON, ExportFormatHTML}
+
+// TranscriptVersion is the version of the JSON export format.
+const TranscriptVersion = 1
+
+// Transcript is a session with its messages and the task sessions started
+// from it.
+type Transcript struct {
+	Session  session.Session   `json:"session"`
+	Messages []message.Message `json:"messages"`
+	// Files is the file history of the session, oldest version first.
+	Files []history.File `json:"files,omitempty"`
+	// Tasks are the sessions of the agent tool calls, by tool call ID.
+	Tasks map[string]Transcript `json:"tasks,omitempty"`
+}
+
+// transcriptFile is the JSON export of a session.
+type transcriptFile struct {
+	Version    int   `json:"version"`
+	ExportedAt int64 `json:"exported_at"`
+	Transcript
+}
+
+// Transcript loads a session with its messages, file history and task
+// sessions.
+func (app *App) Transcript(ctx context.Context, sessionID string) (Transcript, error) {
+	sess, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return Transcript{}, err
+	}
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	files, err := app.History.ListBySession(ctx, sessionID)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to list file history: %w", err)
+	}
+
+	t := Transcript{Session: sess, Messages: msgs, Files: files}
+	for _, msg := range msgs {
+		for _, call := range msg.ToolCalls() {
+			if call.Name != agent.AgentToolName {
+				continue
+			}
+			// Task sessions use the tool call ID as their session ID.
+			task, err := app.Transcript(ctx, call.ID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return Transcript{}, err
+			}
+			if t.Tasks == nil {
+				t.Tasks = make(map[string]Transcript)
+			}
+			t.Tasks[call.ID] = task
+		}
+	}
+	return t, nil
+}
+
+// ExportSession writes the transcript of a session to w.
+func (app *App) ExportSession(ctx context.Context, w io.Writer, sessionID string, format ExportFormat) error {
+	t, err := app.Transcript(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	return WriteTranscript(w, t, format)
+}
+
+// WriteTranscript writes a transcript to w in the given format.
+func WriteTranscript(w io.Writer, t Transcript, format ExportFormat) error {
+	switch format {
+	case ExportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transcriptFile{
+			Version:    TranscriptVersion,
+			ExportedAt: time.Now().Unix(),
+			Transcript: t,
+		})
+	case ExportFormatMarkdown:
+		var sb strings.Builder
+		writeMarkdownSession(&sb, newExportSession(t))
+		_, err := io.WriteString(w, sb.String())
+		return err
+	case ExportFormatHTML:
+		return htmlTemplate.Execute(w, newExportSession(t))
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportSession is a session prepared for the Markdown and HTML exports.
+type exportSession struct {
+	Session session.Session
+	Entries []exportEntry
+}
+
+type exportEntry struct {
+	Role      message.MessageRole
+	Model     string
+	CreatedAt time.Time
+	Text      string
+	Reasoning string
+	Calls     []exportCall
+}
+
+type exportCall struct {
+	Name      string
+	Input     string
+	HasResult bool
+	Result    string
+	IsError   bool
+	Diff      string
+	Task      *exportSession
+}
+
+func newExportSession(t Transcript) exportSession {
+	results := make(map[string]message.ToolResult)
+	for _, msg := range t.Messages {
+		for _, result := range msg.ToolResults() {
+			results[result.ToolCallID] = result
+		}
+	}
+
+	s := exportSession{Session: t.Session}
+	for _, msg := range t.Messages {
+		// Tool results are shown next to their tool call.
+		if msg.Role == message.Tool {
+			continue
+		}
+		entry := exportEntry{
+			Role:      msg.Role,
+			Model:     msg.Model,
+			CreatedAt: time.Unix(msg.CreatedAt, 0),
+			Text:      strings.TrimSpace(msg.Content().Text),
+			Reasoning: strings.TrimSpace(msg.ReasoningContent().Thinking),
+		}
+		for _, tc := range msg.ToolCalls() {
+			call := exportCall{
+				Name:  tc.Name,
+				Input: indentJSON(tc.Input),
+			}
+			if result, ok := results[tc.ID]; ok {
+				call.HasResult = true
+				call.Result = result.Content
+				call.IsError = result.IsError
+				if !result.IsError {
+					call.Diff = toolDiff(tc, result)
+				}
+			}
+			if task, ok := t.Tasks[tc.ID]; ok {
+				taskSession := newExportSession(task)
+				call.Task = &taskSession
+			}
+			entry.Calls = append(entry.Calls, call)
+		}
+		if entry.Text == "" && entry.Reasoning == "" && len(entry.Calls) == 0 {
+			continue
+		}
+		s.Entries = append(s.Entries, entry)
+	}
+	return s
+}
+
+// toolDiff returns the diff of the changes made by a tool call that edits
+// files.
+func toolDiff(call message.ToolCall, result message.ToolResult) string {
+	var input struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal([]byte(call.Input), &input); err != nil {
+		return ""
+	}
+	switch call.Name {
+	case tools.EditToolName, tools.MultiEditToolName:
+		var meta tools.EditResponseMetadata
+		if err := json.Unmarshal([]byte(result.Metadata), &meta); err != nil {
+			return ""
+		}
+		if meta.OldContent == meta.NewContent {
+			return ""
+		}
+		d, _, _ := diff.GenerateDiff(meta.OldContent, meta.NewContent, input.FilePath)
+		return d
+	case tools.WriteToolName:
+		var meta tools.WriteResponseMetadata
+		if err := json.Unmarshal([]byte(result.Metadata), &meta); err != nil {
+			return ""
+		}
+		return meta.Diff
+	case tools.RenameToolName, tools.CodeActionToolName:
+		var meta tools.WorkspaceEditResponseMetadata
+		if err := json.Unmarshal([]byte(result.Metadata), &meta); err != nil {
+			return ""
+		}
+		var diffs []string
+		for _, file := range meta.Files {
+			d, _, _ := diff.GenerateDiff(file.OldContent, file.NewContent, file.FilePath)
+			diffs = append(diffs, d)
+		}
+		return strings.Join(diffs, "\n")
+	}
+	return ""
+}
+
+func indentJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
+
+func roleTitle(entry exportEntry) string {
+	switch entry.Role {
+	case message.User:
+		return "User"
+	case message.Assistant:
+		if entry.Model != "" {
+			return fmt.Sprintf("Assistant (%s)", entry.Model)
+		}
+		return "Assistant"
+	default:
+		return string(entry.Role)
+	}
+}
+
+func writeMarkdownSession(sb *strings.Builder, s exportSession) {
+	fmt.Fprintf(sb, "# %s\n\n", s.Session.Title)
+	fmt.Fprintf(sb, "- Session: `%s`\n", s.Session.ID)
+	fmt.Fprintf(sb, "- Created: %s\n", time.Unix(s.Session.CreatedAt, 0).Format(time.DateTime))
+	fmt.Fprintf(sb, "- Tokens: %d prompt, %d completion\n", s.Session.PromptTokens, s.Session.CompletionTokens)
+	fmt.Fprintf(sb, "- Cost: $%.4f\n", s.Session.Cost)
+
+	for _, entry := range s.Entries {
+		fmt.Fprintf(sb, "\n## %s · %s\n\n", roleTitle(entry), entry.CreatedAt.Format(time.TimeOnly))
+		if entry.Reasoning != "" {
+			fmt.Fprintf(sb, "<details>\n<summary>Reasoning</summary>\n\n%s\n\n</details>\n\n", entry.Reasoning)
+		}
+		if entry.Text != "" {
+			sb.WriteString(entry.Text + "\n\n")
+		}
+		for _, call := range entry.Calls {
+			fmt.Fprintf(sb, "### Tool: %s\n\n", call.Name)
+			sb.WriteString(fenced(call.Input, "json"))
+			switch {
+			case call.Task != nil:
+				var task strings.Builder
+				writeMarkdownSession(&task, *call.Task)
+				for line := range strings.Lines(strings.TrimSpace(task.String())) {
+					sb.WriteString(strings.TrimRight("> "+line, " \n") + "\n")
+				}
+				sb.WriteString("\n")
+			case call.Diff != "":
+				sb.WriteString(fenced(call.Diff, "diff"))
+			case call.HasResult:
+				label := "Result"
+				if call.IsError {
+					label = "Error"
+				}
+				fmt.Fprintf(sb, "**%s**\n\n", label)
+				sb.WriteString(fenced(call.Result, ""))
+			}
+		}
+	}
+}
+
+// fenced returns content in a Markdown code block, with a fence longer than
+// any run of backticks in content.
+func fenced(content, lang string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fmt.Sprintf("%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// diffLine is a line of a diff and the class used to color it.
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+func diffLines(d string) []diffLine {
+	var lines []diffLine
+	for line := range strings.Lines(d) {
+		line = strings.TrimSuffix(line, "\n")
+		var class string
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			class = "file"
+		case strings.HasPrefix(line, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(line, "+"):
+			class = "add"
+		case strings.HasPrefix(line, "-"):
+			class = "del"
+		}
+		lines = append(lines, diffLine{Class: class, Text: line})
+	}
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"role":      roleTitle,
+	"diffLines": diffLines,
+	"datetime":  func(unix int64) string { return time.Unix(unix, 0).Format(time.DateTime) },
+	"time":      func(t time.Time) string { return t.Format(time.TimeOnly) },
+	"cost":      func(cost float64) string { return fmt.Sprintf("$%.4f", cost) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Session.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+pre { background: #f6f6f6; padding: .75rem; overflow-x: auto; white-space: pre-wrap; }
+.meta { color: #666; font-size: .9rem; }
+.entry { border-top: 1px solid #ddd; padding-top: .5rem; margin-top: 1rem; }
+.user h2 { color: #6b50ff; }
+.text { white-space: pre-wrap; }
+.task { border-left: 3px solid #ccc; padding-left: 1rem; margin-left: .5rem; }
+.error { color: #c0392b; }
+.add { color: #1e7e34; }
+.del { color: #c0392b; }
+.hunk { color: #6f42c1; }
+.file { font-weight: bold; }
+</style>
+</head>
+<body>
+{{template "session" .}}
+</body>
+</html>
+{{define "session"}}
+<h1>{{.Session.Title}}</h1>
+<p class="meta">
+Session <code>{{.Session.ID}}</code> · created {{datetime .Session.CreatedAt}}<br>
+Tokens: {{.Session.PromptTokens}} prompt, {{.Session.CompletionTokens}} completion · Cost: {{cost .Session.Cost}}
+</p>
+{{range .Entries}}
+<div class="entry {{.Role}}">
+<h2>{{role .}} <span class="meta">{{time .CreatedAt}}</span></h2>
+{{if .Reasoning}}<details><summary>Reasoning</summary><div class="text">{{.Reasoning}}</div></details>{{end}}
+{{if .Text}}<div class="text">{{.Text}}</div>{{end}}
+{{range .Calls}}
+<h3>Tool: {{.Name}}</h3>
+<pre>{{.Input}}</pre>
+{{if .Task}}<div class="task">{{template "session" .Task}}</div>
+{{else if .Diff}}<pre>{{range diffLines .Diff}}<span class="{{.Class}}">{{.Text}}</span>
+{{end}}</pre>
+{{else if .HasResult}}<p{{if .IsError}} class="error"{{end}}><strong>{{if .IsError}}Error{{else}}Result{{end}}</strong></p>
+<pre>{{.Result}}</pre>
+{{end}}
+{{end}}
+</div>
+{{end}}
+{{end}}`))
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func testTranscript() Transcript {
+	editMeta, _ := json.Marshal(tools.EditResponseMetadata{OldContent: "a\n", NewContent: "b\n"})
+	return Transcript{
+		Session: session.Session{ID: "s1", Title: "Fix it", PromptTokens: 10, CompletionTokens: 5, Cost: 0.5},
+		Messages: []message.Message{
+			{ID: "u1", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "fix main.go"}}},
+			{ID: "a1", Role: message.Assistant, Model: "m", Parts: []message.ContentPart{
+				message.ReasoningContent{Thinking: "look first"},
+				message.TextContent{Text: "On it"},
+				message.ToolCall{ID: "c1", Name: tools.EditToolName, Input: `{"file_path":"main.go"}`, Finished: true},
+				message.ToolCall{ID: "c2", Name: agent.AgentToolName, Input: `{"prompt":"find tests"}`, Finished: true},
+			}},
+			{ID: "t1", Role: message.Tool, Parts: []message.ContentPart{
+				message.ToolResult{ToolCallID: "c1", Content: "edited", Metadata: string(editMeta)},
+				message.ToolResult{ToolCallID: "c2", Content: "found"},
+			}},
+		},
+		Tasks: map[string]Transcript{
+			"c2": {
+				Session: session.Session{ID: "c2", Title: "Task", Cost: 0.1},
+				Messages: []message.Message{
+					{ID: "u2", Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "find tests"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteTranscript(t *testing.T) {
+	t.Parallel()
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, WriteTranscript(&buf, testTranscript(), ExportFormatMarkdown))
+		out := buf.String()
+		require.Contains(t, out, "# Fix it")
+		require.Contains(t, out, "- Tokens: 10 prompt, 5 completion")
+		require.Contains(t, out, "- Cost: $0.5000")
+		require.Contains(t, out, "## Assistant (m)")
+		require.Contains(t, out, "<summary>Reasoning</summary>\n\nlook first")
+		require.Contains(t, out, "```diff\n--- a/main.go\n+++ b/main.go")
+		require.Contains(t, out, "-a\n+b\n")
+		require.Contains(t, out, "> # Task\n")
+		require.Contains(t, out, "> - Cost: $0.1000\n")
+	})
+
+	t.Run("html", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		tr := testTranscript()
+		tr.Messages[0].Parts = []message.ContentPart{message.TextContent{Text: "<script>"}}
+		require.NoError(t, WriteTranscript(&buf, tr, ExportFormatHTML))
+		out := buf.String()
+		require.Contains(t, out, "<h1>Fix it</h1>")
+		require.Contains(t, out, `<span class="add">&#43;b</span>`)
+		require.Contains(t, out, `<div class="task">`)
+		require.Contains(t, out, "&lt;script&gt;")
+		require.NotContains(t, out, "<script>")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, WriteTranscript(&buf, testTranscript(), ExportFormatJSON))
+		var file transcriptFile
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+		require.Equal(t, TranscriptVersion, file.Version)
+		require.Equal(t, "s1", file.Session.ID)
+		require.Len(t, file.Messages, 3)
+		require.Equal(t, "On it", file.Messages[1].Content().Text)
+		require.Contains(t, file.Tasks, "c2")
+	})
+
+	t.Run("fence", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, "````\na ``` b\n````\n\n", fenced("a ``` b", ""))
+	})
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/google/uuid"
+)
+
+// ImportSession recreates a session exported as JSON, with its messages,
+// file history and task sessions, under new IDs.
+func (app *App) ImportSession(ctx context.Context, r io.Reader) (session.Session, error) {
+	var file transcriptFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return session.Session{}, fmt.Errorf("invalid transcript: %w", err)
+	}
+	if file.Version != TranscriptVersion {
+		return session.Session{}, fmt.Errorf("unsupported transcript version %d", file.Version)
+	}
+	if file.Session.ID == "" {
+		return session.Session{}, errors.New("invalid transcript: no session")
+	}
+
+	imp := &importer{app: app}
+	sess, err := imp.importTranscript(ctx, file.Transcript, "", "")
+	if err != nil {
+		imp.rollback()
+		return session.Session{}, err
+	}
+	return sess, nil
+}
+
+// importer tracks the sessions created by an import, to remove them when it
+// fails.
+type importer struct {
+	app     *App
+	created []string
+}
+
+// importTranscript creates the session of t. Task sessions are created with
+// the given ID under their parent session.
+func (imp *importer) importTranscript(ctx context.Context, t Transcript, id, parentID string) (session.Session, error) {
+	var (
+		sess session.Session
+		err  error
+	)
+	if parentID == "" {
+		sess, err = imp.app.Sessions.Create(ctx, t.Session.Title)
+	} else {
+		sess, err = imp.app.Sessions.CreateTaskSession(ctx, id, parentID, t.Session.Title)
+	}
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to create session: %w", err)
+	}
+	imp.created = append(imp.created, sess.ID)
+
+	// Tool calls get new IDs, since task sessions are keyed by them.
+	callIDs := make(map[string]string)
+	for _, msg := range t.Messages {
+		for _, call := range msg.ToolCalls() {
+			callIDs[call.ID] = uuid.New().String()
+		}
+	}
+
+	messageIDs := make(map[string]string, len(t.Messages))
+	for _, msg := range t.Messages {
+		created, err := imp.app.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+			Role:     msg.Role,
+			Parts:    importParts(msg, callIDs),
+			Model:    msg.Model,
+			Provider: msg.Provider,
+		})
+		if err != nil {
+			return session.Session{}, fmt.Errorf("failed to create message: %w", err)
+		}
+		messageIDs[msg.ID] = created.ID
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range t.Files {
+		create := imp.app.History.CreateVersion
+		if !seen[file.Path] {
+			create = imp.app.History.Create
+			seen[file.Path] = true
+		}
+		if _, err := create(ctx, sess.ID, file.Path, file.Content); err != nil {
+			return session.Session{}, fmt.Errorf("failed to create file history: %w", err)
+		}
+	}
+
+	for oldID, task := range t.Tasks {
+		newID, ok := callIDs[oldID]
+		if !ok {
+			continue
+		}
+		if _, err := imp.importTranscript(ctx, task, newID, sess.ID); err != nil {
+			return session.Session{}, err
+		}
+	}
+
+	// Creating messages updated the session, so get it again before saving
+	// the totals of the original.
+	sess, err = imp.app.Sessions.Get(ctx, sess.ID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.PromptTokens = t.Session.PromptTokens
+	sess.CompletionTokens = t.Session.CompletionTokens
+	sess.Cost = t.Session.Cost
+	sess.SummaryMessageID = messageIDs[t.Session.SummaryMessageID]
+	sess, err = imp.app.Sessions.Save(ctx, sess)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to save session: %w", err)
+	}
+	return sess, nil
+}
+
+// rollback removes the sessions created so far, children first.
+func (imp *importer) rollback() {
+	ctx := context.Background()
+	for _, id := range slices.Backward(imp.created) {
+		_ = imp.app.Sessions.Delete(ctx, id)
+	}
+}
+
+// importParts returns the parts of msg with the new tool call IDs. The
+// finish part of non-assistant messages is added again when they are
+// created.
+func importParts(msg message.Message, callIDs map[string]string) []message.ContentPart {
+	parts := make([]message.ContentPart, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch p := part.(type) {
+		case message.Finish:
+			if msg.Role != message.Assistant {
+				continue
+			}
+		case message.ToolCall:
+			p.ID = callIDs[p.ID]
+			part = p
+		case message.ToolResult:
+			if id, ok := callIDs[p.ToolCallID]; ok {
+				p.ToolCallID = id
+			}
+			part = p
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	app := &App{
+		Sessions: session.NewService(q, conn),
+		Messages: message.NewService(q),
+		History:  history.NewService(q, conn),
+	}
+
+	sess, err := app.Sessions.Create(ctx, "Shared")
+	require.NoError(t, err)
+	_, err = app.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "look around"}},
+	})
+	require.NoError(t, err)
+	_, err = app.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.Assistant,
+		Model: "m",
+		Parts: []message.ContentPart{
+			message.ReasoningContent{Thinking: "delegate"},
+			message.ToolCall{ID: "call-1", Name: agent.AgentToolName, Input: `{"prompt":"list files"}`, Finished: true},
+			message.Finish{Reason: message.FinishReasonToolUse},
+		},
+	})
+	require.NoError(t, err)
+	_, err = app.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.Tool,
+		Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Content: "main.go"}},
+	})
+	require.NoError(t, err)
+	task, err := app.Sessions.CreateTaskSession(ctx, "call-1", sess.ID, "list files")
+	require.NoError(t, err)
+	_, err = app.Messages.Create(ctx, task.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "list files"}},
+	})
+	require.NoError(t, err)
+	_, err = app.History.Create(ctx, sess.ID, "/work/main.go", "v0")
+	require.NoError(t, err)
+	_, err = app.History.CreateVersion(ctx, sess.ID, "/work/main.go", "v1")
+	require.NoError(t, err)
+	sess.PromptTokens, sess.CompletionTokens, sess.Cost = 100, 20, 0.25
+	_, err = app.Sessions.Save(ctx, sess)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, app.ExportSession(ctx, &buf, sess.ID, ExportFormatJSON))
+	exported := buf.String()
+
+	for range 2 {
+		imported, err := app.ImportSession(ctx, bytes.NewBufferString(exported))
+		require.NoError(t, err)
+		require.NotEqual(t, sess.ID, imported.ID)
+		require.Equal(t, "Shared", imported.Title)
+		require.Equal(t, int64(100), imported.PromptTokens)
+		require.Equal(t, 0.25, imported.Cost)
+
+		tr, err := app.Transcript(ctx, imported.ID)
+		require.NoError(t, err)
+		require.Len(t, tr.Messages, 3)
+		require.Equal(t, "look around", tr.Messages[0].Content().Text)
+		require.Len(t, tr.Messages[0].Parts, 2, "the finish part is not duplicated")
+		require.Equal(t, "delegate", tr.Messages[1].ReasoningContent().Thinking)
+		require.Equal(t, message.FinishReasonToolUse, tr.Messages[1].FinishReason())
+
+		callID := tr.Messages[1].ToolCalls()[0].ID
+		require.NotEqual(t, "call-1", callID)
+		require.Equal(t, callID, tr.Messages[2].ToolResults()[0].ToolCallID)
+		require.Contains(t, tr.Tasks, callID)
+		require.Equal(t, "list files", tr.Tasks[callID].Messages[0].Content().Text)
+
+		require.Len(t, tr.Files, 2)
+		require.Equal(t, "v0", tr.Files[0].Content)
+		require.Equal(t, "v1", tr.Files[1].Content)
+	}
+
+	_, err = app.ImportSession(ctx, bytes.NewBufferString(`{"version": 99}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,350 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// OutputFormat controls how non-interactive runs report their progress.
+type OutputFormat string
+
+const (
+	// OutputFormatText prints the assistant's text as it streams.
+	OutputFormatText OutputFormat = "text"
+	// OutputFormatJSON prints a single JSON object with the final result.
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatStreamJSON prints one JSON event per line while the agent
+	// runs, followed by the final result.
+	OutputFormatStreamJSON OutputFormat = "stream-json"
+)
+
+// OutputFormats lists the supported output formats.
+var OutputFormats = []OutputFormat{OutputFormatText, OutputFormatJSON, OutputFormatStreamJSON}
+
+// RunEvent is a line of stream-json output.
+type RunEvent struct {
+	Type       string          `json:"type"`
+	SessionID  string          `json:"session_id,omitempty"`
+	MessageID  string          `json:"message_id,omitempty"`
+	Text       string          `json:"text,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Content    string          `json:"content,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	IsError    bool            `json:"is_error,omitempty"`
+	Granted    *bool           `json:"granted,omitempty"`
+}
+
+// RunResult is the final event of a structured non-interactive run.
+type RunResult struct {
+	Type         string               `json:"type"`
+	SessionID    string               `json:"session_id"`
+	IsError      bool                 `json:"is_error"`
+	Error        string               `json:"error,omitempty"`
+	FinishReason message.FinishReason `json:"finish_reason,omitempty"`
+	Result       string               `json:"result"`
+	ToolCalls    int                  `json:"tool_calls"`
+	ToolErrors   int                  `json:"tool_errors"`
+	Usage        RunUsage             `json:"usage"`
+	Cost         float64              `json:"cost"`
+	DurationMS   int64                `json:"duration_ms"`
+}
+
+// RunUsage is the token usage of a session.
+type RunUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Run event types.
+const (
+	RunEventStart      = "start"
+	RunEventText       = "text_delta"
+	RunEventReasoning  = "reasoning_delta"
+	RunEventToolCall   = "tool_call"
+	RunEventToolResult = "tool_result"
+	RunEventPermission = "permission"
+	RunEventResult     = "result"
+)
+
+// runOutput turns the events of a session into structured output.
+type runOutput struct {
+	format    OutputFormat
+	enc       *json.Encoder
+	sessionID string
+	started   time.Time
+
+	readText      map[string]int
+	readReasoning map[string]int
+	toolCalls     map[string]message.ToolCall
+	toolResults   map[string]bool
+	toolErrors    int
+
+	// pendingPermissions holds the permission decisions that arrived before
+	// their tool call, by tool call ID.
+	pendingPermissions map[string]permission.PermissionNotification
+}
+
+func newRunOutput(w io.Writer, format OutputFormat, sessionID string) *runOutput {
+	return &runOutput{
+		format:        format,
+		enc:           json.NewEncoder(w),
+		sessionID:     sessionID,
+		started:       time.Now(),
+		readText:      make(map[string]int),
+		readReasoning: make(map[string]int),
+		toolCalls:     make(map[string]message.ToolCall),
+		toolResults:   make(map[string]bool),
+
+		pendingPermissions: make(map[string]permission.PermissionNotification),
+	}
+}
+
+func (o *runOutput) emit(ev RunEvent) error {
+	if o.format != OutputFormatStreamJSON {
+		return nil
+	}
+	return o.enc.Encode(ev)
+}
+
+func (o *runOutput) start() error {
+	return o.emit(RunEvent{Type: RunEventStart, SessionID: o.sessionID})
+}
+
+// handleMessage emits the parts of msg that were not reported yet.
+func (o *runOutput) handleMessage(msg message.Message) error {
+	if msg.SessionID != o.sessionID {
+		return nil
+	}
+	switch msg.Role {
+	case message.Assistant:
+		if thinking := msg.ReasoningContent().Thinking; len(thinking) > o.readReasoning[msg.ID] {
+			delta := thinking[o.readReasoning[msg.ID]:]
+			o.readReasoning[msg.ID] = len(thinking)
+			if err := o.emit(RunEvent{Type: RunEventReasoning, MessageID: msg.ID, Text: delta}); err != nil {
+				return err
+			}
+		}
+		if text := msg.Content().Text; len(text) > o.readText[msg.ID] {
+			delta := text[o.readText[msg.ID]:]
+			o.readText[msg.ID] = len(text)
+			if err := o.emit(RunEvent{Type: RunEventText, MessageID: msg.ID, Text: delta}); err != nil {
+				return err
+			}
+		}
+		for _, call := range msg.ToolCalls() {
+			if _, ok := o.toolCalls[call.ID]; ok || !call.Finished {
+				continue
+			}
+			o.toolCalls[call.ID] = call
+			if err := o.emit(RunEvent{
+				Type:       RunEventToolCall,
+				MessageID:  msg.ID,
+				ToolCallID: call.ID,
+				Name:       call.Name,
+				Input:      rawJSON(call.Input),
+			}); err != nil {
+				return err
+			}
+			if n, ok := o.pendingPermissions[call.ID]; ok {
+				delete(o.pendingPermissions, call.ID)
+				if err := o.handlePermission(n); err != nil {
+					return err
+				}
+			}
+		}
+	case message.Tool:
+		for _, result := range msg.ToolResults() {
+			if o.toolResults[result.ToolCallID] {
+				continue
+			}
+			o.toolResults[result.ToolCallID] = true
+			if result.IsError {
+				o.toolErrors++
+			}
+			name := result.Name
+			if name == "" {
+				name = o.toolCalls[result.ToolCallID].Name
+			}
+			if err := o.emit(RunEvent{
+				Type:       RunEventToolResult,
+				MessageID:  msg.ID,
+				ToolCallID: result.ToolCallID,
+				Name:       name,
+				Content:    result.Content,
+				Metadata:   rawJSON(result.Metadata),
+				IsError:    result.IsError,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// handleDelta emits the text of a streamed delta that was not reported yet.
+// Missed deltas are reported with the next update of the message.
+func (o *runOutput) handleDelta(delta message.Delta) error {
+	if delta.SessionID != o.sessionID {
+		return nil
+	}
+	var (
+		read map[string]int
+		typ  string
+	)
+	switch delta.Type {
+	case message.ContentDelta:
+		read, typ = o.readText, RunEventText
+	case message.ReasoningDelta:
+		read, typ = o.readReasoning, RunEventReasoning
+	default:
+		return nil
+	}
+	text, ok := delta.Since(read[delta.MessageID])
+	if !ok || text == "" {
+		return nil
+	}
+	read[delta.MessageID] += len(text)
+	return o.emit(RunEvent{Type: typ, MessageID: delta.MessageID, Text: text})
+}
+
+// handlePermission reports permission decisions for tool calls of the
+// session. Decisions arriving before their tool call are reported along with
+// it.
+func (o *runOutput) handlePermission(n permission.PermissionNotification) error {
+	if !n.Granted && !n.Denied {
+		return nil
+	}
+	call, ok := o.toolCalls[n.ToolCallID]
+	if !ok {
+		o.pendingPermissions[n.ToolCallID] = n
+		return nil
+	}
+	granted := n.Granted
+	return o.emit(RunEvent{
+		Type:       RunEventPermission,
+		ToolCallID: n.ToolCallID,
+		Name:       call.Name,
+		Granted:    &granted,
+	})
+}
+
+// finish reports the final result of the run.
+func (o *runOutput) finish(sess session.Session, result agent.AgentEvent) error {
+	res := RunResult{
+		Type:       RunEventResult,
+		SessionID:  o.sessionID,
+		ToolCalls:  len(o.toolCalls),
+		ToolErrors: o.toolErrors,
+		Usage: RunUsage{
+			PromptTokens:     sess.PromptTokens,
+			CompletionTokens: sess.CompletionTokens,
+		},
+		Cost:       sess.Cost,
+		DurationMS: time.Since(o.started).Milliseconds(),
+	}
+	if result.Error != nil {
+		res.IsError = true
+		res.Error = result.Error.Error()
+	} else {
+		res.Result = result.Message.Content().Text
+		res.FinishReason = result.Message.FinishReason()
+	}
+	return o.enc.Encode(res)
+}
+
+// rawJSON returns s as raw JSON if it is valid JSON, or as a JSON string
+// otherwise.
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// runStructured runs the agent and reports its progress with the given
+// structured output format.
+func (app *App) runStructured(ctx context.Context, w io.Writer, format OutputFormat, sessionID, prompt string) error {
+	out := newRunOutput(w, format, sessionID)
+	messageEvents := app.Messages.Subscribe(ctx)
+	deltaEvents := app.Messages.SubscribeDeltas(ctx)
+	permissionEvents := app.Permissions.SubscribeNotifications(ctx)
+
+	if err := out.start(); err != nil {
+		return err
+	}
+	done, err := app.CoderAgent.Run(ctx, sessionID, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to start agent processing stream: %w", err)
+	}
+
+	for {
+		select {
+		case result := <-done:
+			// Report what is still buffered before the final result.
+			for drained := false; !drained; {
+				select {
+				case event := <-messageEvents:
+					if err := out.handleMessage(event.Payload); err != nil {
+						return err
+					}
+				case event := <-deltaEvents:
+					if err := out.handleDelta(event.Payload); err != nil {
+						return err
+					}
+				default:
+					drained = true
+				}
+			}
+			if result.Error == nil {
+				if err := out.handleMessage(result.Message); err != nil {
+					return err
+				}
+			}
+
+			sess, err := app.Sessions.Get(context.Background(), sessionID)
+			if err != nil {
+				return fmt.Errorf("failed to get session: %w", err)
+			}
+			if err := out.finish(sess, result); err != nil {
+				return err
+			}
+			if result.Error != nil && !errors.Is(result.Error, context.Canceled) && !errors.Is(result.Error, agent.ErrRequestCancelled) {
+				return fmt.Errorf("agent processing failed: %w", result.Error)
+			}
+			return nil
+		case event := <-messageEvents:
+			if event.Type == pubsub.DeletedEvent {
+				continue
+			}
+			if err := out.handleMessage(event.Payload); err != nil {
+				return err
+			}
+		case event := <-deltaEvents:
+			if err := out.handleDelta(event.Payload); err != nil {
+				return err
+			}
+		case event := <-permissionEvents:
+			if err := out.handlePermission(event.Payload); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &ev))
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestRunOutput_StreamJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	out := newRunOutput(&buf, OutputFormatStreamJSON, "s1")
+	require.NoError(t, out.start())
+
+	assistant := message.Message{ID: "a1", SessionID: "s1", Role: message.Assistant}
+	assistant.Parts = []message.ContentPart{message.TextContent{Text: "Hel"}}
+	require.NoError(t, out.handleMessage(assistant))
+	assistant.Parts = []message.ContentPart{
+		message.TextContent{Text: "Hello"},
+		message.ToolCall{ID: "c1", Name: "bash", Input: `{"command":"ls"}`, Finished: true},
+	}
+	require.NoError(t, out.handleMessage(assistant))
+	// Events of other sessions are ignored.
+	require.NoError(t, out.handleMessage(message.Message{ID: "x", SessionID: "other", Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "nope"}}}))
+
+	require.NoError(t, out.handlePermission(permission.PermissionNotification{ToolCallID: "c1", Granted: true}))
+	require.NoError(t, out.handleMessage(message.Message{
+		ID:        "t1",
+		SessionID: "s1",
+		Role:      message.Tool,
+		Parts: []message.ContentPart{
+			message.ToolResult{ToolCallID: "c1", Content: "exit 1", Metadata: `{"exit_code":1}`, IsError: true},
+		},
+	}))
+
+	final := assistant
+	final.Parts = []message.ContentPart{message.TextContent{Text: "Hello"}, message.Finish{Reason: message.FinishReasonEndTurn}}
+	require.NoError(t, out.finish(session.Session{ID: "s1", PromptTokens: 10, CompletionTokens: 5, Cost: 0.5}, agent.AgentEvent{Message: final}))
+
+	events := decodeLines(t, &buf)
+	types := make([]string, len(events))
+	for i, ev := range events {
+		types[i] = ev["type"].(string)
+	}
+	require.Equal(t, []string{
+		RunEventStart,
+		RunEventText,
+		RunEventText,
+		RunEventToolCall,
+		RunEventPermission,
+		RunEventToolResult,
+		RunEventResult,
+	}, types)
+	require.Equal(t, "lo", events[2]["text"])
+	require.Equal(t, map[string]any{"command": "ls"}, events[3]["input"])
+	require.Equal(t, true, events[4]["granted"])
+	require.Equal(t, "bash", events[5]["name"])
+	require.Equal(t, map[string]any{"exit_code": float64(1)}, events[5]["metadata"])
+
+	result := events[6]
+	require.Equal(t, "s1", result["session_id"])
+	require.Equal(t, "Hello", result["result"])
+	require.Equal(t, false, result["is_error"])
+	require.Equal(t, float64(1), result["tool_errors"])
+	require.Equal(t, 0.5, result["cost"])
+	require.Equal(t, map[string]any{"prompt_tokens": float64(10), "completion_tokens": float64(5)}, result["usage"])
+}
+
+func TestRunOutput_JSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	out := newRunOutput(&buf, OutputFormatJSON, "s1")
+	require.NoError(t, out.start())
+	require.NoError(t, out.handleMessage(message.Message{
+		ID:        "a1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "partial"}},
+	}))
+	require.NoError(t, out.finish(session.Session{ID: "s1"}, agent.AgentEvent{Error: errors.New("boom")}))
+
+	events := decodeLines(t, &buf)
+	require.Len(t, events, 1)
+	require.Equal(t, RunEventResult, events[0]["type"])
+	require.Equal(t, true, events[0]["is_error"])
+	require.Equal(t, "boom", events[0]["error"])
+}
+
+func TestRunOutput_Deltas(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	out := newRunOutput(&buf, OutputFormatStreamJSON, "s1")
+	delta := func(typ message.DeltaType, offset int, content string) message.Delta {
+		return message.Delta{MessageID: "a1", SessionID: "s1", Type: typ, Offset: offset, Content: content}
+	}
+	require.NoError(t, out.handleDelta(delta(message.ReasoningDelta, 0, "Hmm")))
+	require.NoError(t, out.handleDelta(delta(message.ContentDelta, 0, "Hel")))
+	require.NoError(t, out.handleDelta(delta(message.ContentDelta, 3, "lo")))
+	// Text already reported by a message update is not reported again.
+	require.NoError(t, out.handleMessage(message.Message{
+		ID:        "a1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "Hello wor"}},
+	}))
+	require.NoError(t, out.handleDelta(delta(message.ContentDelta, 5, " world")))
+
+	events := decodeLines(t, &buf)
+	require.Len(t, events, 5)
+	require.Equal(t, RunEventReasoning, events[0]["type"])
+	require.Equal(t, "Hmm", events[0]["text"])
+	var text strings.Builder
+	for _, ev := range events[1:] {
+		require.Equal(t, RunEventText, ev["type"])
+		text.WriteString(ev["text"].(string))
+	}
+	require.Equal(t, "Hello world", text.String())
+	require.Equal(t, "ld", events[4]["text"])
+}
+
+func TestRunOutput_PermissionBeforeToolCall(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	out := newRunOutput(&buf, OutputFormatStreamJSON, "s1")
+	// The decision is reported once the tool call is.
+	require.NoError(t, out.handlePermission(permission.PermissionNotification{ToolCallID: "c1", Denied: true}))
+	require.Empty(t, buf.String())
+	require.NoError(t, out.handleMessage(message.Message{
+		ID:        "a1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts: []message.ContentPart{
+			message.ToolCall{ID: "c1", Name: "bash", Input: `{"command":"rm -rf /"}`, Finished: true},
+		},
+	}))
+
+	events := decodeLines(t, &buf)
+	require.Len(t, events, 2)
+	require.Equal(t, RunEventToolCall, events[0]["type"])
+	require.Equal(t, RunEventPermission, events[1]["type"])
+	require.Equal(t, "c1", events[1]["tool_call_id"])
+	require.Equal(t, "bash", events[1]["name"])
+	require.Equal(t, false, events[1]["granted"])
+}
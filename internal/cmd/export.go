@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <session-id>",
+	Short: "Export a session transcript",
+	Long: `Export the conversation of a session, including reasoning, tool calls, file
+diffs and the task sessions started from it, as Markdown, JSON or HTML.`,
+	Example: `
+# Print a session as Markdown
+crush export <session-id>
+
+# Save a session as HTML
+crush export <session-id> --format html --output session.html
+
+# Save a session as JSON, for crush import
+crush export <session-id> -f json -o session.json
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if !slices.Contains(app.ExportFormats, app.ExportFormat(format)) {
+			return fmt.Errorf("invalid format %q, must be one of md, json or html", format)
+		}
+
+		appInstance, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer appInstance.Shutdown()
+
+		ctx := cmd.Context()
+		if _, err := appInstance.Sessions.Get(ctx, args[0]); err != nil {
+			return sessionLookupError(args[0], err)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := appInstance.ExportSession(ctx, w, args[0], app.ExportFormat(format)); err != nil {
+			return fmt.Errorf("failed to export session: %w", err)
+		}
+		if output != "" {
+			fmt.Fprintf(os.Stderr, "Exported session to %s\n", output)
+		}
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", string(app.ExportFormatMarkdown), "Export format: md, json or html")
+	exportCmd.Flags().StringP("output", "o", "", "File to write to instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file.json>",
+	Short: "Import a session exported as JSON",
+	Long: `Recreate a session exported with crush export --format json, including its
+messages, task sessions and file history, under new IDs. The imported session
+can be continued like any other. Use - to read the transcript from stdin.`,
+	Example: `
+# Import a session shared by a teammate
+crush import session.json
+
+# Continue the imported session
+crush run --continue "Pick up where we left off"
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open transcript: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		sess, err := app.ImportSession(cmd.Context(), r)
+		if err != nil {
+			return fmt.Errorf("failed to import session: %w", err)
+		}
+		fmt.Printf("Imported session %s: %s\n", sess.ID, sess.Title)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var permissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "Manage the permissions that are always allowed",
+	Long: `List and revoke the permissions granted with "Allow for Session" or
+"Allow for Project". Grants of the working directory and global grants are
+listed.`,
+	Example: `
+# List the permissions that are always allowed
+crush permissions list
+
+# Ask for a permission again
+crush permissions revoke <grant-id>
+  `,
+}
+
+var permissionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the permissions that are always allowed",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		grants, err := app.Permissions.ListGrants(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(grants) == 0 {
+			fmt.Println("No permissions are always allowed")
+			return nil
+		}
+		for _, grant := range grants {
+			fmt.Printf("%s  %s  %-7s  %s:%s  %s\n",
+				grant.ID,
+				time.Unix(grant.CreatedAt, 0).Format(time.DateTime),
+				grant.Scope,
+				grant.ToolName,
+				grant.Action,
+				cmp.Or(grant.Command, grant.Path),
+			)
+		}
+		return nil
+	},
+}
+
+var permissionsRevokeCmd = &cobra.Command{
+	Use:   "revoke <grant-id>",
+	Short: "Revoke a permission that is always allowed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		if err := app.Permissions.RevokeGrant(cmd.Context(), args[0]); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("grant %s not found", args[0])
+			}
+			return err
+		}
+		fmt.Printf("Revoked grant %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	permissionsCmd.AddCommand(permissionsListCmd, permissionsRevokeCmd)
+	rootCmd.AddCommand(permissionsCmd)
+}
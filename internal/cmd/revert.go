@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <session-id>",
+	Short: "Revert a session to a checkpoint",
+	Long: `Restore the files changed in a session to the state they had before a prompt
+was sent, and remove that prompt and everything after it from the session.
+A checkpoint is recorded for every prompt. Without --to, the latest one is used.`,
+	Example: `
+# List the checkpoints of a session
+crush revert <session-id> --list
+
+# Undo the last prompt of a session
+crush revert <session-id>
+
+# Revert to the checkpoint of a specific prompt
+crush revert <session-id> --to <message-id>
+  `,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		to, _ := cmd.Flags().GetString("to")
+		sessionID := args[0]
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		ctx := cmd.Context()
+		if _, err := app.Sessions.Get(ctx, sessionID); err != nil {
+			return fmt.Errorf("session %s not found", sessionID)
+		}
+
+		if list {
+			checkpoints, err := app.Checkpoints(ctx, sessionID)
+			if err != nil {
+				return err
+			}
+			for _, cp := range checkpoints {
+				prompt, _, _ := strings.Cut(strings.TrimSpace(cp.Prompt), "\n")
+				fmt.Printf("%s  %s  %d files  %s\n",
+					cp.MessageID,
+					time.Unix(cp.CreatedAt, 0).Format(time.DateTime),
+					len(cp.Files),
+					prompt,
+				)
+			}
+			return nil
+		}
+
+		restored, err := app.RevertSession(ctx, sessionID, to)
+		if err != nil {
+			return err
+		}
+		if len(restored) == 0 {
+			fmt.Println("Session reverted, no files changed")
+			return nil
+		}
+		fmt.Printf("Session reverted, restored %d files:\n", len(restored))
+		for _, path := range restored {
+			fmt.Println("  " + path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	revertCmd.Flags().String("to", "", "Message ID of the prompt to revert to")
+	revertCmd.Flags().BoolP("list", "l", false, "List the checkpoints of the session")
+	rootCmd.AddCommand(revertCmd)
+}
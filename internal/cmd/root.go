@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/tui"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/charmbracelet/crush/internal/version"
 	"github.com/charmbracelet/fang"
 	"github.com/charmbracelet/x/term"
@@ -65,6 +66,10 @@ crush -y
 		}
 		defer app.Shutdown()
 
+		if err := keybindings.Configure(config.Get().Keybindings); err != nil {
+			return fmt.Errorf("invalid keybindings: %w", err)
+		}
+
 		// Set up the TUI.
 		program := tea.NewProgram(
 			tui.New(app),
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/crush/internal/app"
 	"github.com/spf13/cobra"
 )
 
@@ -22,17 +26,32 @@ echo "What is this code doing?" | crush run
 
 # Run with quiet mode (no spinner)
 crush run -q "Generate a README for this project"
+
+# Print one JSON event per line, for scripts and CI
+crush run --output-format stream-json "Fix the failing tests"
+
+# Continue the most recent session
+crush run --continue "Now add tests for it"
+
+# Continue a specific session
+crush run --session <session-id> "Summarize what you changed"
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		quiet, _ := cmd.Flags().GetBool("quiet")
+		outputFormat, _ := cmd.Flags().GetString("output-format")
+		sessionID, _ := cmd.Flags().GetString("session")
+		continueLatest, _ := cmd.Flags().GetBool("continue")
+		if !slices.Contains(app.OutputFormats, app.OutputFormat(outputFormat)) {
+			return fmt.Errorf("invalid output format %q, must be one of text, json or stream-json", outputFormat)
+		}
 
-		app, err := setupApp(cmd)
+		appInstance, err := setupApp(cmd)
 		if err != nil {
 			return err
 		}
-		defer app.Shutdown()
+		defer appInstance.Shutdown()
 
-		if !app.Config().IsConfigured() {
+		if !appInstance.Config().IsConfigured() {
 			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
 		}
 
@@ -48,11 +67,27 @@ crush run -q "Generate a README for this project"
 			return fmt.Errorf("no prompt provided")
 		}
 
+		if continueLatest {
+			latest, err := appInstance.Sessions.GetLatest(cmd.Context())
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				slog.Info("No session to continue, starting a new one")
+			case err != nil:
+				return fmt.Errorf("failed to get the latest session: %w", err)
+			default:
+				sessionID = latest.ID
+			}
+		}
+
 		// Run non-interactive flow using the App method
-		return app.RunNonInteractive(cmd.Context(), prompt, quiet)
+		return appInstance.RunNonInteractive(cmd.Context(), sessionID, prompt, quiet, app.OutputFormat(outputFormat))
 	},
 }
 
 func init() {
 	runCmd.Flags().BoolP("quiet", "q", false, "Hide spinner")
+	runCmd.Flags().StringP("output-format", "o", string(app.OutputFormatText), "Output format: text, json or stream-json")
+	runCmd.Flags().StringP("session", "s", "", "Continue the session with this ID")
+	runCmd.Flags().BoolP("continue", "C", false, "Continue the most recent session")
+	runCmd.MarkFlagsMutuallyExclusive("session", "continue")
 }
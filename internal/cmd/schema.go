@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/invopop/jsonschema"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,9 @@ var schemaCmd = &cobra.Command{
 	Hidden: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		reflector := new(jsonschema.Reflector)
-		bts, err := json.MarshalIndent(reflector.Reflect(&config.Config{}), "", "  ")
+		schema := reflector.Reflect(&config.Config{})
+		addKeybindingActions(schema)
+		bts, err := json.MarshalIndent(schema, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal schema: %w", err)
 		}
@@ -25,6 +28,24 @@ var schemaCmd = &cobra.Command{
 	},
 }
 
+// addKeybindingActions restricts the keys of the keybindings section to the
+// IDs of the configurable actions, which the config package does not know.
+func addKeybindingActions(schema *jsonschema.Schema) {
+	cfg, ok := schema.Definitions["Config"]
+	if !ok {
+		return
+	}
+	property, ok := cfg.Properties.Get("keybindings")
+	if !ok {
+		return
+	}
+	ids := make([]any, 0, len(keybindings.Actions))
+	for _, action := range keybindings.Actions {
+		ids = append(ids, action.ID)
+	}
+	property.PropertyNames = &jsonschema.Schema{Enum: ids}
+}
+
 func init() {
 	rootCmd.AddCommand(schemaCmd)
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/charmbracelet/crush/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the Crush API over HTTP",
+	Long: `Start Crush without the TUI and expose sessions, messages, agent runs and
+permission requests over a local HTTP API. Application events are streamed as
+server-sent events from /v1/events.`,
+	Example: `
+# Serve on the default address, with a generated bearer token
+crush serve
+
+# Serve on a custom address, with a chosen bearer token
+crush serve --addr 127.0.0.1:9000 --token secret
+
+# Follow the event stream
+curl -N -H "Authorization: Bearer secret" http://127.0.0.1:9000/v1/events
+  `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		token, _ := cmd.Flags().GetString("token")
+		token = cmp.Or(token, os.Getenv("CRUSH_SERVER_TOKEN"))
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		if !app.Config().IsConfigured() {
+			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
+		}
+
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+
+		srv := server.New(app, token)
+		fmt.Fprintf(os.Stderr, "Listening on http://%s\n", ln.Addr())
+		if token == "" {
+			fmt.Fprintf(os.Stderr, "Token: %s\n", srv.Token())
+		}
+		return srv.Serve(cmd.Context(), ln)
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:4000", "Address to listen on")
+	serveCmd.Flags().String("token", "", "Bearer token required by every request (defaults to $CRUSH_SERVER_TOKEN, or a generated one)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage sessions",
+	Long: `List, show and delete the sessions of the current project. Sessions created
+with crush run are listed next to the interactive ones.`,
+	Example: `
+# List the sessions of the project
+crush sessions list
+
+# Show the conversation of a session
+crush sessions show <session-id>
+
+# Delete a session
+crush sessions delete <session-id>
+  `,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		sessions, err := app.Sessions.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return printJSON(sessions)
+		}
+		for _, sess := range sessions {
+			fmt.Printf("%s  %s  %3d messages  %s\n",
+				sess.ID,
+				time.Unix(sess.UpdatedAt, 0).Format(time.DateTime),
+				sess.MessageCount,
+				sess.Title,
+			)
+		}
+		return nil
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <session-id>",
+	Short: "Show the conversation of a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		ctx := cmd.Context()
+		sess, err := app.Sessions.Get(ctx, args[0])
+		if err != nil {
+			return sessionLookupError(args[0], err)
+		}
+		messages, err := app.Messages.List(ctx, sess.ID)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return printJSON(struct {
+				Session  session.Session   `json:"session"`
+				Messages []message.Message `json:"messages"`
+			}{sess, messages})
+		}
+
+		fmt.Printf("%s\n%s  %d messages  %d tokens  $%.4f\n",
+			sess.Title,
+			sess.ID,
+			sess.MessageCount,
+			sess.PromptTokens+sess.CompletionTokens,
+			sess.Cost,
+		)
+		for _, msg := range messages {
+			fmt.Println()
+			printMessage(msg)
+		}
+		return nil
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:   "delete <session-id>",
+	Short: "Delete a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		if err := app.Sessions.Delete(cmd.Context(), args[0]); err != nil {
+			return sessionLookupError(args[0], err)
+		}
+		fmt.Printf("Deleted session %s\n", args[0])
+		return nil
+	},
+}
+
+// printMessage prints the text, tool calls and tool results of a message.
+func printMessage(msg message.Message) {
+	switch msg.Role {
+	case message.User:
+		fmt.Println("> " + strings.ReplaceAll(strings.TrimSpace(msg.Content().Text), "\n", "\n> "))
+	case message.Assistant:
+		if text := strings.TrimSpace(msg.Content().Text); text != "" {
+			fmt.Println(text)
+		}
+		for _, call := range msg.ToolCalls() {
+			fmt.Printf("[%s] %s\n", call.Name, call.Input)
+		}
+	case message.Tool:
+		for _, result := range msg.ToolResults() {
+			status := "ok"
+			if result.IsError {
+				status = "error"
+			}
+			content, _, _ := strings.Cut(strings.TrimSpace(result.Content), "\n")
+			fmt.Printf("[%s %s] %s\n", result.Name, status, content)
+		}
+	}
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func sessionLookupError(id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("session %s not found", id)
+	}
+	return err
+}
+
+func init() {
+	sessionsListCmd.Flags().Bool("json", false, "Print the sessions as JSON")
+	sessionsShowCmd.Flags().Bool("json", false, "Print the session and its messages as JSON")
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsDeleteCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
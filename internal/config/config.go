@@ -1,12 +1,14 @@
 package config
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -14,6 +16,8 @@ import (
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/env"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/shell"
 	"github.com/tidwall/sjson"
 )
 
@@ -74,7 +78,7 @@ type ProviderConfig struct {
 	// The provider's API endpoint.
 	BaseURL string `json:"base_url,omitempty" jsonschema:"description=Base URL for the provider's API,format=uri,example=https://api.openai.com/v1"`
 	// The provider type, e.g. "openai", "anthropic", etc. if empty it defaults to openai.
-	Type catwalk.Type `json:"type,omitempty" jsonschema:"description=Provider type that determines the API format,enum=openai,enum=anthropic,enum=gemini,enum=azure,enum=vertexai,default=openai"`
+	Type catwalk.Type `json:"type,omitempty" jsonschema:"description=Provider type that determines the API format,enum=openai,enum=anthropic,enum=gemini,enum=azure,enum=vertexai,enum=local,default=openai"`
 	// The provider's API key.
 	APIKey string `json:"api_key,omitempty" jsonschema:"description=API key for authentication with the provider,example=$OPENAI_API_KEY"`
 	// Marks the provider as disabled.
@@ -96,6 +100,10 @@ type ProviderConfig struct {
 
 	// The provider models
 	Models []catwalk.Model `json:"models,omitempty" jsonschema:"description=List of models available from this provider"`
+
+	// Models without native tool calling, they are given the tools in the
+	// system prompt instead.
+	PromptToolModels []string `json:"prompt_tool_models,omitempty" jsonschema:"description=IDs of the models that get tools through the system prompt instead of native tool calling; discovered for local providers"`
 }
 
 type MCPType string
@@ -117,26 +125,104 @@ type MCPConfig struct {
 
 	// TODO: maybe make it possible to get the value from the env
 	Headers map[string]string `json:"headers,omitempty" jsonschema:"description=HTTP headers for HTTP/SSE MCP servers"`
+	OAuth   *MCPOAuthConfig   `json:"oauth,omitempty" jsonschema:"description=OAuth authorization for HTTP/SSE MCP servers"`
+}
+
+type MCPOAuthConfig struct {
+	ClientID     string   `json:"client_id,omitempty" jsonschema:"description=OAuth client ID registered with the authorization server; registered dynamically when empty"`
+	ClientSecret string   `json:"client_secret,omitempty" jsonschema:"description=OAuth client secret for confidential clients"`
+	Scopes       []string `json:"scopes,omitempty" jsonschema:"description=OAuth scopes to request,example=read,example=write"`
+	RedirectPort int      `json:"redirect_port,omitempty" jsonschema:"description=Local port receiving the authorization callback,default=19876"`
+	MetadataURL  string   `json:"metadata_url,omitempty" jsonschema:"description=URL of the authorization server metadata; discovered from the MCP server when empty,format=uri"`
+}
+
+// DefaultMCPOAuthRedirectPort is the local port receiving the OAuth callback
+// when none is configured.
+const DefaultMCPOAuthRedirectPort = 19876
+
+// RedirectURI returns the loopback URI the authorization server redirects
+// the browser to.
+func (o MCPOAuthConfig) RedirectURI() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/callback", cmp.Or(o.RedirectPort, DefaultMCPOAuthRedirectPort))
+}
+
+// ResolvedClientSecret returns the client secret with its shell variables
+// resolved.
+func (o MCPOAuthConfig) ResolvedClientSecret() string {
+	if o.ClientSecret == "" {
+		return ""
+	}
+	secret, err := NewShellVariableResolver(env.New()).ResolveValue(o.ClientSecret)
+	if err != nil {
+		slog.Error("error resolving client secret variable", "error", err)
+		return o.ClientSecret
+	}
+	return secret
 }
 
 type LSPConfig struct {
-	Disabled  bool              `json:"enabled,omitempty" jsonschema:"description=Whether this LSP server is disabled,default=false"`
-	Command   string            `json:"command" jsonschema:"required,description=Command to execute for the LSP server,example=gopls"`
-	Args      []string          `json:"args,omitempty" jsonschema:"description=Arguments to pass to the LSP server command"`
-	Env       map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables to set to the LSP server command"`
-	Options   any               `json:"options,omitempty" jsonschema:"description=LSP server-specific configuration options"`
-	FileTypes []string          `json:"filetypes,omitempty" jsonschema:"description=File types this LSP server handles,example=go,example=mod,example=rs,example=c,example=js,example=ts"`
+	Disabled      bool              `json:"enabled,omitempty" jsonschema:"description=Whether this LSP server is disabled,default=false"`
+	Command       string            `json:"command" jsonschema:"required,description=Command to execute for the LSP server,example=gopls"`
+	Args          []string          `json:"args,omitempty" jsonschema:"description=Arguments to pass to the LSP server command"`
+	Env           map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables to set to the LSP server command"`
+	Options       any               `json:"options,omitempty" jsonschema:"description=LSP server-specific configuration options"`
+	FileTypes     []string          `json:"filetypes,omitempty" jsonschema:"description=File types this LSP server handles,example=go,example=mod,example=rs,example=c,example=js,example=ts"`
+	FormatOnWrite bool              `json:"format_on_write,omitempty" jsonschema:"description=Format files and organize imports through this LSP server before the edit tools save them,default=false"`
 }
 
 type TUIOptions struct {
 	CompactMode bool   `json:"compact_mode,omitempty" jsonschema:"description=Enable compact mode for the TUI interface,default=false"`
 	DiffMode    string `json:"diff_mode,omitempty" jsonschema:"description=Diff mode for the TUI interface,enum=unified,enum=split"`
-	// Here we can add themes later or any TUI related options
+	Theme       string `json:"theme,omitempty" jsonschema:"description=Name of the TUI theme: a built-in theme or one from the themes directory next to the global config,default=charmtone,example=light,example=high-contrast,example=solarized-dark"`
 }
 
 type Permissions struct {
-	AllowedTools []string `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"` // Tools that don't require permission prompts
-	SkipRequests bool     `json:"-"`                                                                                                                              // Automatically accept all permissions (YOLO mode)
+	AllowedTools []string         `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"`                          // Tools that don't require permission prompts
+	Rules        []PermissionRule `json:"rules,omitempty" jsonschema:"description=Ordered permission rules. The first rule matching a tool call decides whether it is allowed or denied or asked"` // Policy rules, evaluated in order
+	SkipRequests bool             `json:"-"`                                                                                                                                                       // Automatically accept all permissions (YOLO mode)
+}
+
+// Hooks are shell commands run on agent events. They receive a JSON
+// description of the event on stdin.
+type Hooks struct {
+	PreToolUse        []Hook `json:"pre_tool_use,omitempty" jsonschema:"description=Commands run before a tool call; exiting with code 2 blocks the call and returns stderr to the model"`
+	PostToolUse       []Hook `json:"post_tool_use,omitempty" jsonschema:"description=Commands run after a tool call; exiting with code 2 returns stderr to the model with the tool result"`
+	TurnEnd           []Hook `json:"turn_end,omitempty" jsonschema:"description=Commands run when the model ends its turn"`
+	SessionStart      []Hook `json:"session_start,omitempty" jsonschema:"description=Commands run before the first prompt of a session"`
+	PermissionRequest []Hook `json:"permission_request,omitempty" jsonschema:"description=Commands run when a tool call asks the user for permission"`
+}
+
+// Hook is a shell command run on an agent event.
+type Hook struct {
+	Command string   `json:"command" jsonschema:"required,description=Shell command to run,example=gofmt -l -w ."`
+	Tools   []string `json:"tools,omitempty" jsonschema:"description=Tool names the hook applies to with support for * wildcards; all the tools by default,example=edit,example=mcp_*"`
+	Timeout int      `json:"timeout,omitempty" jsonschema:"description=Timeout in seconds,default=60"`
+}
+
+// Sandbox restricts the commands of the bash tool on Linux.
+type Sandbox struct {
+	Enabled       bool     `json:"enabled,omitempty" jsonschema:"description=Run the bash tool commands in a sandbox on Linux,default=false"`
+	AllowNetwork  bool     `json:"allow_network,omitempty" jsonschema:"description=Let sandboxed commands open TCP connections. UDP and Unix sockets are never restricted,default=false"`
+	WritablePaths []string `json:"writable_paths,omitempty" jsonschema:"description=Paths sandboxed commands can write to on top of the working directory and the temporary directories,example=~/.cache/go-build"`
+}
+
+type PermissionDecision string
+
+const (
+	PermissionAllow PermissionDecision = "allow"
+	PermissionDeny  PermissionDecision = "deny"
+	PermissionAsk   PermissionDecision = "ask"
+)
+
+// PermissionRule matches tool calls and decides what happens to them. Empty
+// fields match anything; all the non-empty fields must match.
+type PermissionRule struct {
+	Name     string             `json:"name,omitempty" jsonschema:"description=Name of the rule shown when it denies a tool call,example=no-vendor-edits"`
+	Decision PermissionDecision `json:"decision" jsonschema:"required,description=What to do with matching tool calls,enum=allow,enum=deny,enum=ask"`
+	Tools    []string           `json:"tools,omitempty" jsonschema:"description=Tool names the rule applies to with support for * wildcards,example=bash,example=edit,example=mcp_*"`
+	Actions  []string           `json:"actions,omitempty" jsonschema:"description=Tool actions the rule applies to,example=execute,example=write"`
+	Paths    []string           `json:"paths,omitempty" jsonschema:"description=Glob patterns of paths relative to the working directory where ** matches any number of directories,example=vendor/**,example=**/*.go"`
+	Commands []string           `json:"commands,omitempty" jsonschema:"description=Bash command patterns where * matches any text. Every command of a pipeline or list must match for allow rules,example=go test ./...,example=make *"`
 }
 
 type Options struct {
@@ -146,6 +232,7 @@ type Options struct {
 	DebugLSP             bool        `json:"debug_lsp,omitempty" jsonschema:"description=Enable debug logging for LSP servers,default=false"`
 	DisableAutoSummarize bool        `json:"disable_auto_summarize,omitempty" jsonschema:"description=Disable automatic conversation summarization,default=false"`
 	DataDirectory        string      `json:"data_directory,omitempty" jsonschema:"description=Directory for storing application data (relative to working directory),default=.crush,example=.crush"` // Relative to the cwd
+	MaxParallelTools     int         `json:"max_parallel_tools,omitempty" jsonschema:"description=Maximum number of read-only tool calls executed concurrently in a single turn,default=4,minimum=1,example=8"`
 }
 
 type MCPs map[string]MCPConfig
@@ -256,6 +343,12 @@ type Config struct {
 
 	Permissions *Permissions `json:"permissions,omitempty" jsonschema:"description=Permission settings for tool usage"`
 
+	Hooks *Hooks `json:"hooks,omitempty" jsonschema:"description=Shell commands run on agent events"`
+
+	Sandbox *Sandbox `json:"sandbox,omitempty" jsonschema:"description=Sandbox settings for the bash tool"`
+
+	Keybindings map[string][]string `json:"keybindings,omitempty" jsonschema:"description=Keys of the TUI actions by action ID; an empty list disables the action"`
+
 	// Internal
 	workingDir string `json:"-"`
 	// TODO: most likely remove this concept when I come back to it
@@ -270,6 +363,26 @@ func (c *Config) WorkingDir() string {
 	return c.workingDir
 }
 
+// ShellSandbox returns the sandbox of the bash tool commands, or nil when it
+// is disabled.
+func (c *Config) ShellSandbox() *shell.Sandbox {
+	if c.Sandbox == nil || !c.Sandbox.Enabled {
+		return nil
+	}
+	paths := []string{c.workingDir}
+	for _, path := range c.Sandbox.WritablePaths {
+		path = home.Long(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.workingDir, path)
+		}
+		paths = append(paths, path)
+	}
+	return &shell.Sandbox{
+		WritablePaths: paths,
+		AllowNetwork:  c.Sandbox.AllowNetwork,
+	}
+}
+
 func (c *Config) EnabledProviders() []ProviderConfig {
 	var enabled []ProviderConfig
 	for p := range c.Providers.Seq() {
@@ -339,6 +452,14 @@ func (c *Config) SetCompactMode(enabled bool) error {
 	return c.SetConfigField("options.tui.compact_mode", enabled)
 }
 
+func (c *Config) SetTheme(name string) error {
+	if c.Options == nil {
+		c.Options = &Options{}
+	}
+	c.Options.TUI.Theme = name
+	return c.SetConfigField("options.tui.theme", name)
+}
+
 func (c *Config) Resolve(key string) (string, error) {
 	if c.resolver == nil {
 		return "", fmt.Errorf("no variable resolver configured")
@@ -479,6 +600,12 @@ func (c *ProviderConfig) TestConnection(resolver VariableResolver) error {
 			baseURL = "https://generativelanguage.googleapis.com"
 		}
 		testURL = baseURL + "/v1beta/models?key=" + url.QueryEscape(apiKey)
+	case TypeLocal:
+		baseURL, _ := resolver.ResolveValue(c.BaseURL)
+		testURL = LocalServerURL(baseURL) + "/v1/models"
+		if apiKey != "" {
+			headers["Authorization"] = "Bearer " + apiKey
+		}
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
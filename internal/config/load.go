@@ -68,7 +68,13 @@ func Load(workingDir, dataDir string, debug bool) (*Config, error) {
 	// Load known providers, this loads the config from catwalk
 	providers, err := Providers()
 	if err != nil || len(providers) == 0 {
-		return nil, fmt.Errorf("failed to load providers: %w", err)
+		// Local providers work without the known providers, on machines
+		// without network access.
+		if !cfg.hasLocalProviders() {
+			return nil, fmt.Errorf("failed to load providers: %w", err)
+		}
+		slog.Warn("Failed to load providers, using the local providers only", "error", err)
+		providers = nil
 	}
 	cfg.knownProviders = providers
 
@@ -92,6 +98,16 @@ func Load(workingDir, dataDir string, debug bool) (*Config, error) {
 	return cfg, nil
 }
 
+// hasLocalProviders returns whether the config has enabled local providers.
+func (c *Config) hasLocalProviders() bool {
+	for _, p := range c.Providers.Seq2() {
+		if p.Type == TypeLocal && !p.Disable {
+			return true
+		}
+	}
+	return false
+}
+
 func PushPopCrushEnv() func() {
 	found := []string{}
 	for _, ev := range os.Environ() {
@@ -265,7 +281,7 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			c.Providers.Del(id)
 			continue
 		}
-		if providerConfig.APIKey == "" {
+		if providerConfig.APIKey == "" && providerConfig.Type != TypeLocal {
 			slog.Warn("Provider is missing API key, this might be OK for local providers", "provider", id)
 		}
 		if providerConfig.BaseURL == "" {
@@ -273,19 +289,22 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			c.Providers.Del(id)
 			continue
 		}
+		if providerConfig.Type == TypeLocal {
+			configureLocalProvider(&providerConfig, resolver)
+		}
 		if len(providerConfig.Models) == 0 {
 			slog.Warn("Skipping custom provider because the provider has no models", "provider", id)
 			c.Providers.Del(id)
 			continue
 		}
-		if providerConfig.Type != catwalk.TypeOpenAI && providerConfig.Type != catwalk.TypeAnthropic {
+		if providerConfig.Type != catwalk.TypeOpenAI && providerConfig.Type != catwalk.TypeAnthropic && providerConfig.Type != TypeLocal {
 			slog.Warn("Skipping custom provider because the provider type is not supported", "provider", id, "type", providerConfig.Type)
 			c.Providers.Del(id)
 			continue
 		}
 
 		apiKey, err := resolver.ResolveValue(providerConfig.APIKey)
-		if apiKey == "" || err != nil {
+		if (apiKey == "" || err != nil) && providerConfig.Type != TypeLocal {
 			slog.Warn("Provider is missing API key, this might be OK for local providers", "provider", id)
 		}
 		baseURL, err := resolver.ResolveValue(providerConfig.BaseURL)
@@ -588,6 +607,12 @@ func globalConfig() string {
 	return filepath.Join(home.Dir(), ".config", appName, fmt.Sprintf("%s.json", appName))
 }
 
+// GlobalThemesDir returns the directory user themes are loaded from, next to
+// the global config file.
+func GlobalThemesDir() string {
+	return filepath.Join(filepath.Dir(globalConfig()), "themes")
+}
+
 // GlobalConfigData returns the path to the main data directory for the application.
 // this config is used when the app overrides configurations instead of updating the global config.
 func GlobalConfigData() string {
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// TypeLocal is the type of the providers serving models from a local Ollama or
+// llama.cpp server. Their models are discovered from the server.
+const TypeLocal catwalk.Type = "local"
+
+const (
+	// localRequestTimeout bounds each request to a local server, and
+	// localDialTimeout the connection, so that a server that is down does
+	// not hold up startup.
+	localRequestTimeout = 5 * time.Second
+	localDialTimeout    = time.Second
+	// localDefaultContextWindow is used when the server does not report the
+	// context window of a model.
+	localDefaultContextWindow = 4096
+	localMaxDefaultMaxTokens  = 16384
+)
+
+// localClient is the HTTP client for local servers.
+var localClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{Timeout: localDialTimeout}).DialContext,
+	},
+}
+
+// errNotOllama is returned when the server does not serve the Ollama API.
+var errNotOllama = errors.New("not an Ollama server")
+
+// LocalServerURL returns the root URL of a local model server, without the
+// path of its OpenAI compatible API.
+func LocalServerURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1")
+}
+
+// localModel is a model found on a local server.
+type localModel struct {
+	model catwalk.Model
+	// tools is whether the model supports native tool calling.
+	tools bool
+}
+
+// DiscoverLocalModels lists the models installed on an Ollama or llama.cpp
+// server, along with the IDs of the models without native tool calling.
+func DiscoverLocalModels(ctx context.Context, baseURL string, headers map[string]string) (models []catwalk.Model, promptToolModels []string, err error) {
+	root := LocalServerURL(baseURL)
+	found, err := discoverOllamaModels(ctx, root, headers)
+	if errors.Is(err, errNotOllama) {
+		found, err = discoverLlamaCppModels(ctx, root, headers)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range found {
+		models = append(models, m.model)
+		if !m.tools {
+			promptToolModels = append(promptToolModels, m.model.ID)
+		}
+	}
+	return models, promptToolModels, nil
+}
+
+func newLocalModel(id string, contextWindow int64, tools, images bool) localModel {
+	if contextWindow <= 0 {
+		contextWindow = localDefaultContextWindow
+	}
+	return localModel{
+		model: catwalk.Model{
+			ID:               id,
+			Name:             id,
+			ContextWindow:    contextWindow,
+			DefaultMaxTokens: min(contextWindow/4, localMaxDefaultMaxTokens),
+			SupportsImages:   images,
+		},
+		tools: tools,
+	}
+}
+
+func discoverOllamaModels(ctx context.Context, root string, headers map[string]string) ([]localModel, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	status, err := localRequest(ctx, http.MethodGet, root+"/api/tags", headers, nil, &tags)
+	if status == http.StatusNotFound {
+		return nil, errNotOllama
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]localModel, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		var show struct {
+			Capabilities []string       `json:"capabilities"`
+			ModelInfo    map[string]any `json:"model_info"`
+			Parameters   string         `json:"parameters"`
+			Template     string         `json:"template"`
+		}
+		body := map[string]string{"model": tag.Name}
+		if _, err := localRequest(ctx, http.MethodPost, root+"/api/show", headers, body, &show); err != nil {
+			slog.Warn("Skipping Ollama model", "model", tag.Name, "error", err)
+			continue
+		}
+
+		// The context window the server runs the model with, or the one the
+		// model was trained with.
+		contextWindow := ollamaParameter(show.Parameters, "num_ctx")
+		if contextWindow == 0 {
+			for key, value := range show.ModelInfo {
+				if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+					contextWindow = int64(n)
+				}
+			}
+		}
+		// Servers older than the capabilities field use the tools in the
+		// template of the model.
+		tools := slices.Contains(show.Capabilities, "tools") ||
+			(show.Capabilities == nil && strings.Contains(show.Template, ".Tools"))
+		images := slices.Contains(show.Capabilities, "vision")
+		models = append(models, newLocalModel(tag.Name, contextWindow, tools, images))
+	}
+	return models, nil
+}
+
+// ollamaParameter returns the value of an integer parameter in the
+// parameters of an Ollama model, one "name value" pair per line.
+func ollamaParameter(parameters, name string) int64 {
+	for line := range strings.Lines(parameters) {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == name {
+			n, _ := strconv.ParseInt(fields[1], 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+func discoverLlamaCppModels(ctx context.Context, root string, headers map[string]string) ([]localModel, error) {
+	var list struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Meta struct {
+				NCtxTrain int64 `json:"n_ctx_train"`
+			} `json:"meta"`
+		} `json:"data"`
+	}
+	if _, err := localRequest(ctx, http.MethodGet, root+"/v1/models", headers, nil, &list); err != nil {
+		return nil, err
+	}
+
+	// A llama.cpp server runs a single model, its properties apply to all
+	// the listed models.
+	var props struct {
+		DefaultGenerationSettings struct {
+			NCtx int64 `json:"n_ctx"`
+		} `json:"default_generation_settings"`
+		ChatTemplate     string `json:"chat_template"`
+		ChatTemplateCaps *struct {
+			SupportsTools bool `json:"supports_tools"`
+		} `json:"chat_template_caps"`
+		Modalities struct {
+			Vision bool `json:"vision"`
+		} `json:"modalities"`
+	}
+	if _, err := localRequest(ctx, http.MethodGet, root+"/props", headers, nil, &props); err != nil {
+		slog.Debug("Failed to get llama.cpp server properties", "url", root, "error", err)
+	}
+	tools := strings.Contains(props.ChatTemplate, "tools")
+	if props.ChatTemplateCaps != nil {
+		tools = props.ChatTemplateCaps.SupportsTools
+	}
+
+	models := make([]localModel, 0, len(list.Data))
+	for _, m := range list.Data {
+		contextWindow := props.DefaultGenerationSettings.NCtx
+		if contextWindow == 0 {
+			contextWindow = m.Meta.NCtxTrain
+		}
+		models = append(models, newLocalModel(m.ID, contextWindow, tools, props.Modalities.Vision))
+	}
+	return models, nil
+}
+
+// localRequest sends a request with an optional JSON body to a local server
+// and decodes its JSON response, returning the status code of the response.
+func localRequest(ctx context.Context, method, url string, headers map[string]string, body, result any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, localRequestTimeout)
+	defer cancel()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := localClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid response from %s: %w", url, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// configureLocalProvider adds the models discovered on the server of a local
+// provider to the models it declares, which take precedence.
+func configureLocalProvider(providerConfig *ProviderConfig, resolver VariableResolver) {
+	baseURL, err := resolver.ResolveValue(providerConfig.BaseURL)
+	if err != nil || baseURL == "" {
+		return
+	}
+	headers := make(map[string]string, len(providerConfig.ExtraHeaders)+1)
+	for key, value := range providerConfig.ExtraHeaders {
+		if resolved, err := resolver.ResolveValue(value); err == nil {
+			headers[key] = resolved
+		}
+	}
+	if apiKey, err := resolver.ResolveValue(providerConfig.APIKey); err == nil && apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+
+	models, promptToolModels, err := DiscoverLocalModels(context.Background(), baseURL, headers)
+	if err != nil {
+		slog.Warn("Failed to discover local models", "provider", providerConfig.ID, "url", baseURL, "error", err)
+		return
+	}
+
+	for _, model := range models {
+		if slices.ContainsFunc(providerConfig.Models, func(m catwalk.Model) bool { return m.ID == model.ID }) {
+			continue
+		}
+		providerConfig.Models = append(providerConfig.Models, model)
+		if slices.Contains(promptToolModels, model.ID) && !slices.Contains(providerConfig.PromptToolModels, model.ID) {
+			providerConfig.PromptToolModels = append(providerConfig.PromptToolModels, model.ID)
+		}
+	}
+	slog.Info("Discovered local models", "provider", providerConfig.ID, "count", len(models))
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/require"
+)
+
+func newOllamaStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [{"name": "qwen3:8b"}, {"name": "gemma3:4b"}, {"name": "llama2:7b"}, {"name": "broken:1b"}]}`))
+	})
+	mux.HandleFunc("POST /api/show", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Model {
+		case "qwen3:8b":
+			w.Write([]byte(`{
+				"capabilities": ["completion", "tools"],
+				"model_info": {"qwen3.context_length": 40960},
+				"parameters": "num_ctx 32768\ntemperature 0.6"
+			}`))
+		case "gemma3:4b":
+			w.Write([]byte(`{
+				"capabilities": ["completion", "vision"],
+				"model_info": {"gemma3.context_length": 131072}
+			}`))
+		case "llama2:7b":
+			w.Write([]byte(`{
+				"model_info": {"llama.context_length": 4096},
+				"template": "{{ if .Tools }}{{ .Tools }}{{ end }}"
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newLlamaCppStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [{"id": "phi-4.gguf", "meta": {"n_ctx_train": 16384}}]}`))
+	})
+	mux.HandleFunc("GET /props", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"default_generation_settings": {"n_ctx": 8192},
+			"chat_template": "{% for message in messages %}{% endfor %}"
+		}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiscoverLocalModels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ollama", func(t *testing.T) {
+		t.Parallel()
+		srv := newOllamaStub(t)
+
+		models, promptToolModels, err := DiscoverLocalModels(t.Context(), srv.URL+"/v1/", nil)
+		require.NoError(t, err)
+		// broken:1b, which the server fails to show, is skipped.
+		require.Equal(t, []catwalk.Model{
+			{ID: "qwen3:8b", Name: "qwen3:8b", ContextWindow: 32768, DefaultMaxTokens: 8192},
+			{ID: "gemma3:4b", Name: "gemma3:4b", ContextWindow: 131072, DefaultMaxTokens: 16384, SupportsImages: true},
+			{ID: "llama2:7b", Name: "llama2:7b", ContextWindow: 4096, DefaultMaxTokens: 1024},
+		}, models)
+		require.Equal(t, []string{"gemma3:4b"}, promptToolModels)
+	})
+
+	t.Run("llama.cpp", func(t *testing.T) {
+		t.Parallel()
+		srv := newLlamaCppStub(t)
+
+		models, promptToolModels, err := DiscoverLocalModels(t.Context(), srv.URL, nil)
+		require.NoError(t, err)
+		require.Equal(t, []catwalk.Model{
+			{ID: "phi-4.gguf", Name: "phi-4.gguf", ContextWindow: 8192, DefaultMaxTokens: 2048},
+		}, models)
+		require.Equal(t, []string{"phi-4.gguf"}, promptToolModels)
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+
+		_, _, err := DiscoverLocalModels(t.Context(), srv.URL, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestConfig_configureProvidersLocal(t *testing.T) {
+	t.Parallel()
+	srv := newOllamaStub(t)
+
+	cfg := &Config{
+		Providers: csync.NewMapFrom(map[string]ProviderConfig{
+			"ollama": {
+				Type:    TypeLocal,
+				BaseURL: srv.URL + "/v1",
+				Models: []catwalk.Model{{
+					ID:            "qwen3:8b",
+					Name:          "Qwen 3",
+					ContextWindow: 8192,
+				}},
+			},
+		}),
+	}
+	cfg.setDefaults("/tmp", "")
+
+	env := env.NewFromMap(map[string]string{})
+	resolver := NewEnvironmentVariableResolver(env)
+	require.NoError(t, cfg.configureProviders(env, resolver, []catwalk.Provider{}))
+
+	pc, ok := cfg.Providers.Get("ollama")
+	require.True(t, ok)
+	require.Len(t, pc.Models, 3)
+	require.Equal(t, "Qwen 3", pc.Models[0].Name)
+	require.Equal(t, int64(8192), pc.Models[0].ContextWindow)
+	require.Equal(t, []string{"gemma3:4b"}, pc.PromptToolModels)
+}
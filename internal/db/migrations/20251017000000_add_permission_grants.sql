@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Permission grants remember the tool calls the user always allows. Session
+-- grants are removed with their session, project grants apply to one working
+-- directory and global grants apply everywhere.
+CREATE TABLE IF NOT EXISTS permission_grants (
+    id TEXT PRIMARY KEY,
+    scope TEXT NOT NULL CHECK (scope IN ('session', 'project', 'global')),
+    session_id TEXT,
+    project TEXT NOT NULL DEFAULT '',
+    tool_name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    path TEXT NOT NULL DEFAULT '',
+    command TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in seconds
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_permission_grants_tool ON permission_grants (tool_name, action);
+CREATE INDEX IF NOT EXISTS idx_permission_grants_session_id ON permission_grants (session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_permission_grants_session_id;
+DROP INDEX IF EXISTS idx_permission_grants_tool;
+DROP TABLE IF EXISTS permission_grants;
+-- +goose StatementEnd
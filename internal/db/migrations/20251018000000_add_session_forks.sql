@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Forks are sessions copied from another session up to one of its messages.
+-- They link to the original session through parent_session_id, like task
+-- sessions, but are listed with the top level sessions.
+ALTER TABLE sessions ADD COLUMN forked_from_message_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN forked_from_message_id;
+-- +goose StatementEnd
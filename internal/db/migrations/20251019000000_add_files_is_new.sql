@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- is_new marks the initial version of a file created by the agent, which
+-- reverting removes, unlike the initial version of an existing empty file.
+ALTER TABLE files ADD COLUMN is_new BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN is_new;
+-- +goose StatementEnd
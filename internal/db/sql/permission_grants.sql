@@ -0,0 +1,41 @@
+-- name: CreatePermissionGrant :one
+INSERT INTO permission_grants (
+    id,
+    scope,
+    session_id,
+    project,
+    tool_name,
+    action,
+    path,
+    command,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: GetPermissionGrant :one
+SELECT *
+FROM permission_grants
+WHERE id = ? LIMIT 1;
+
+-- name: ListPermissionGrants :many
+SELECT *
+FROM permission_grants
+WHERE scope = 'global' OR project = ?
+ORDER BY created_at DESC;
+
+-- name: ListMatchingPermissionGrants :many
+SELECT *
+FROM permission_grants
+WHERE tool_name = sqlc.arg(tool_name)
+  AND action = sqlc.arg(action)
+  AND (
+    scope = 'global'
+    OR (scope = 'project' AND project = sqlc.arg(project))
+    OR (scope = 'session' AND session_id = sqlc.arg(session_id))
+  );
+
+-- name: DeletePermissionGrant :exec
+DELETE FROM permission_grants
+WHERE id = ?;
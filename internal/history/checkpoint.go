@@ -0,0 +1,178 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/google/uuid"
+)
+
+// Checkpoint records the latest known version of every file touched in a
+// session at the moment a user message was sent. Reverting to a checkpoint
+// restores the files to those versions.
+type Checkpoint struct {
+	ID        string
+	SessionID string
+	MessageID string
+	Files     map[string]int64
+	CreatedAt int64
+}
+
+func (s *service) CreateCheckpoint(ctx context.Context, sessionID, messageID string) (Checkpoint, error) {
+	files, err := s.ListBySession(ctx, sessionID)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	snapshot := make(map[string]int64)
+	for _, file := range files {
+		if v, ok := snapshot[file.Path]; !ok || file.Version > v {
+			snapshot[file.Path] = file.Version
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to marshal checkpoint files: %w", err)
+	}
+	dbCheckpoint, err := s.q.CreateCheckpoint(ctx, db.CreateCheckpointParams{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		MessageID: messageID,
+		Files:     string(data),
+	})
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return s.checkpointFromDBItem(dbCheckpoint)
+}
+
+func (s *service) GetCheckpoint(ctx context.Context, id string) (Checkpoint, error) {
+	dbCheckpoint, err := s.q.GetCheckpoint(ctx, id)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return s.checkpointFromDBItem(dbCheckpoint)
+}
+
+func (s *service) GetCheckpointByMessage(ctx context.Context, messageID string) (Checkpoint, error) {
+	dbCheckpoint, err := s.q.GetCheckpointByMessage(ctx, messageID)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return s.checkpointFromDBItem(dbCheckpoint)
+}
+
+func (s *service) ListCheckpoints(ctx context.Context, sessionID string) ([]Checkpoint, error) {
+	dbCheckpoints, err := s.q.ListCheckpointsBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := make([]Checkpoint, len(dbCheckpoints))
+	for i, dbCheckpoint := range dbCheckpoints {
+		checkpoints[i], err = s.checkpointFromDBItem(dbCheckpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return checkpoints, nil
+}
+
+// RevertToCheckpoint restores every file changed in the checkpoint's session
+// to the state it had when the checkpoint was taken, and drops the file
+// versions created after it. Files that did not exist before the session
+// created them are removed. It returns the paths that were restored.
+func (s *service) RevertToCheckpoint(ctx context.Context, checkpointID string) ([]string, error) {
+	checkpoint, err := s.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.ListBySession(ctx, checkpoint.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are ordered by version, so the first entry for a path is the
+	// content it had before the session touched it.
+	byPath := make(map[string][]File)
+	for _, file := range files {
+		byPath[file.Path] = append(byPath[file.Path], file)
+	}
+
+	var restored []string
+	for path, versions := range byPath {
+		target, inSnapshot := checkpoint.Files[path]
+		var restore *File
+		var stale []File
+		for i, file := range versions {
+			switch {
+			case inSnapshot && file.Version == target:
+				restore = &versions[i]
+			case inSnapshot && file.Version < target:
+			default:
+				stale = append(stale, file)
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+
+		if restore == nil {
+			restore = &versions[0]
+		}
+		if err := restoreFile(path, restore.Content, restore.IsNew); err != nil {
+			return restored, err
+		}
+		for _, file := range stale {
+			if err := s.Delete(ctx, file.ID); err != nil {
+				return restored, err
+			}
+		}
+		restored = append(restored, path)
+	}
+	sort.Strings(restored)
+	return restored, nil
+}
+
+// restoreFile writes back the content of a file, keeping its mode, or
+// removes it when the content is the initial version of a new file.
+func restoreFile(path, content string, isNew bool) error {
+	if isNew {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *service) checkpointFromDBItem(item db.Checkpoint) (Checkpoint, error) {
+	files := make(map[string]int64)
+	if item.Files != "" {
+		if err := json.Unmarshal([]byte(item.Files), &files); err != nil {
+			return Checkpoint{}, fmt.Errorf("failed to unmarshal checkpoint files: %w", err)
+		}
+	}
+	return Checkpoint{
+		ID:        item.ID,
+		SessionID: item.SessionID,
+		MessageID: item.MessageID,
+		Files:     files,
+		CreatedAt: item.CreatedAt,
+	}, nil
+}
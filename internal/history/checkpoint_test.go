@@ -0,0 +1,104 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertToCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	sessions := session.NewService(q, conn)
+	messages := message.NewService(q)
+	files := NewService(q, conn)
+
+	sess, err := sessions.Create(ctx, "test")
+	require.NoError(t, err)
+	prompt := func() message.Message {
+		msg, err := messages.Create(ctx, sess.ID, message.CreateMessageParams{
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: "do it"}},
+		})
+		require.NoError(t, err)
+		return msg
+	}
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	created := filepath.Join(dir, "created.txt")
+	empty := filepath.Join(dir, "__init__.py")
+	require.NoError(t, os.WriteFile(existing, []byte("v0"), 0o755))
+	require.NoError(t, os.WriteFile(empty, nil, 0o644))
+
+	// First prompt edits an existing file.
+	first, err := files.CreateCheckpoint(ctx, sess.ID, prompt().ID)
+	require.NoError(t, err)
+	require.Empty(t, first.Files)
+	_, err = files.Create(ctx, sess.ID, existing, "v0")
+	require.NoError(t, err)
+	_, err = files.CreateVersion(ctx, sess.ID, existing, "v1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(existing, []byte("v1"), 0o644))
+
+	// Second prompt edits it again and creates a new file.
+	second, err := files.CreateCheckpoint(ctx, sess.ID, prompt().ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{existing: 1}, second.Files)
+	// Checkpoints created in the same second keep their order.
+	checkpoints, err := files.ListCheckpoints(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	require.Equal(t, first.ID, checkpoints[0].ID)
+	require.Equal(t, second.ID, checkpoints[1].ID)
+	_, err = files.CreateVersion(ctx, sess.ID, existing, "v2")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(existing, []byte("v2"), 0o644))
+	_, err = files.CreateNew(ctx, sess.ID, created)
+	require.NoError(t, err)
+	_, err = files.CreateVersion(ctx, sess.ID, created, "new")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(created, []byte("new"), 0o644))
+	// An existing empty file is not mistaken for a new one.
+	_, err = files.Create(ctx, sess.ID, empty, "")
+	require.NoError(t, err)
+	_, err = files.CreateVersion(ctx, sess.ID, empty, "x = 1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(empty, []byte("x = 1"), 0o644))
+
+	restored, err := files.RevertToCheckpoint(ctx, second.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{existing, created, empty}, restored)
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+	info, err := os.Stat(existing)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	require.NoFileExists(t, created)
+	content, err = os.ReadFile(empty)
+	require.NoError(t, err)
+	require.Empty(t, content)
+
+	restored, err = files.RevertToCheckpoint(ctx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{existing}, restored)
+	content, err = os.ReadFile(existing)
+	require.NoError(t, err)
+	require.Equal(t, "v0", string(content))
+
+	remaining, err := files.ListBySession(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+}
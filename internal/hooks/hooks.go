@@ -0,0 +1,183 @@
+// Package hooks runs the shell commands configured for agent events.
+//
+// Hooks receive a JSON description of the event on stdin. A hook exiting with
+// code 2 blocks the event: before a tool call, the call does not run and the
+// hook's stderr is returned to the model instead. Other failures are logged
+// and ignored.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+// defaultTimeout is how long a hook runs when its timeout is not configured.
+const defaultTimeout = 60 * time.Second
+
+// blockExitCode is the exit code of a hook blocking its event.
+const blockExitCode = 2
+
+type Event string
+
+const (
+	PreToolUse        Event = "pre_tool_use"
+	PostToolUse       Event = "post_tool_use"
+	TurnEnd           Event = "turn_end"
+	SessionStart      Event = "session_start"
+	PermissionRequest Event = "permission_request"
+)
+
+// Input is the description of an event sent to its hooks.
+type Input struct {
+	Event        Event                         `json:"event"`
+	SessionID    string                        `json:"session_id,omitempty"`
+	WorkingDir   string                        `json:"working_dir"`
+	ToolCall     *tools.ToolCall               `json:"tool_call,omitempty"`
+	ToolResponse *tools.ToolResponse           `json:"tool_response,omitempty"`
+	Files        []string                      `json:"files,omitempty"`
+	Permission   *permission.PermissionRequest `json:"permission,omitempty"`
+	FinishReason string                        `json:"finish_reason,omitempty"`
+}
+
+// toolName returns the name of the tool the event is about, if any.
+func (i Input) toolName() string {
+	switch {
+	case i.ToolCall != nil:
+		return i.ToolCall.Name
+	case i.Permission != nil:
+		return i.Permission.ToolName
+	}
+	return ""
+}
+
+// BlockedError is returned when a hook blocks its event.
+type BlockedError struct {
+	Command string
+	Reason  string
+}
+
+func (e *BlockedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("blocked by hook %q", e.Command)
+	}
+	return fmt.Sprintf("blocked by hook %q: %s", e.Command, e.Reason)
+}
+
+// Runner runs the configured hooks. A nil runner runs nothing.
+type Runner struct {
+	hooks      config.Hooks
+	workingDir string
+}
+
+// New returns a runner for the configured hooks, or nil if there are none.
+func New(cfg *config.Hooks, workingDir string) *Runner {
+	if cfg == nil {
+		return nil
+	}
+	return &Runner{hooks: *cfg, workingDir: workingDir}
+}
+
+func (r *Runner) forEvent(event Event) []config.Hook {
+	if r == nil {
+		return nil
+	}
+	switch event {
+	case PreToolUse:
+		return r.hooks.PreToolUse
+	case PostToolUse:
+		return r.hooks.PostToolUse
+	case TurnEnd:
+		return r.hooks.TurnEnd
+	case SessionStart:
+		return r.hooks.SessionStart
+	case PermissionRequest:
+		return r.hooks.PermissionRequest
+	}
+	return nil
+}
+
+// Run runs the hooks of the event in order. It stops at the first hook
+// blocking the event and returns a *BlockedError.
+func (r *Runner) Run(ctx context.Context, input Input) error {
+	hooks := r.forEvent(input.Event)
+	if len(hooks) == 0 {
+		return nil
+	}
+	input.WorkingDir = r.workingDir
+	if input.ToolResponse != nil {
+		// Binary data is of no use to the hooks.
+		response := *input.ToolResponse
+		response.Data = nil
+		input.ToolResponse = &response
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook input: %w", err)
+	}
+
+	for _, hook := range hooks {
+		if !matchesTool(hook.Tools, input.toolName()) {
+			continue
+		}
+		if err := r.run(ctx, hook, input, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, hook config.Hook, input Input, data []byte) error {
+	timeout := defaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sh := shell.NewShell(&shell.Options{
+		WorkingDir: r.workingDir,
+		Env: append(
+			os.Environ(),
+			"CRUSH_HOOK_EVENT="+string(input.Event),
+			"CRUSH_SESSION_ID="+input.SessionID,
+		),
+	})
+	_, stderr, err := sh.ExecInput(ctx, hook.Command, bytes.NewReader(data))
+	switch {
+	case err == nil:
+		return nil
+	case shell.IsInterrupt(err):
+		slog.Warn("Hook interrupted", "event", input.Event, "command", hook.Command, "error", err)
+	case shell.ExitCode(err) == blockExitCode:
+		return &BlockedError{Command: hook.Command, Reason: strings.TrimSpace(stderr)}
+	default:
+		slog.Warn("Hook failed", "event", input.Event, "command", hook.Command, "error", err, "stderr", stderr)
+	}
+	return nil
+}
+
+// matchesTool reports whether a tool is one of the patterns. Every tool
+// matches an empty list of patterns, including the lack of a tool.
+func matchesTool(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runner := New(&config.Hooks{
+		PreToolUse: []config.Hook{
+			{Command: "cat > input.json"},
+			{Command: "echo 'edits are frozen' >&2; exit 2", Tools: []string{"edit", "mcp_*"}},
+		},
+		PostToolUse: []config.Hook{{Command: "exit 1"}},
+	}, dir)
+
+	call := &tools.ToolCall{ID: "c1", Name: "edit", Input: `{"file_path":"main.go"}`}
+	err := runner.Run(t.Context(), Input{
+		Event:     PreToolUse,
+		SessionID: "s1",
+		ToolCall:  call,
+		Files:     []string{"main.go"},
+	})
+	var blocked *BlockedError
+	require.True(t, errors.As(err, &blocked))
+	require.Equal(t, "edits are frozen", blocked.Reason)
+
+	data, err := os.ReadFile(filepath.Join(dir, "input.json"))
+	require.NoError(t, err)
+	var input Input
+	require.NoError(t, json.Unmarshal(data, &input))
+	require.Equal(t, PreToolUse, input.Event)
+	require.Equal(t, "s1", input.SessionID)
+	require.Equal(t, dir, input.WorkingDir)
+	require.Equal(t, call, input.ToolCall)
+	require.Equal(t, []string{"main.go"}, input.Files)
+
+	// The hooks of other tools are skipped.
+	call = &tools.ToolCall{ID: "c2", Name: "view", Input: `{}`}
+	require.NoError(t, runner.Run(t.Context(), Input{Event: PreToolUse, ToolCall: call}))
+	call.Name = "mcp_github_create_issue"
+	require.Error(t, runner.Run(t.Context(), Input{Event: PreToolUse, ToolCall: call}))
+
+	// Failing hooks do not block their event.
+	response := tools.NewTextResponse("done")
+	require.NoError(t, runner.Run(t.Context(), Input{Event: PostToolUse, ToolCall: call, ToolResponse: &response}))
+
+	var nilRunner *Runner
+	require.NoError(t, nilRunner.Run(t.Context(), Input{Event: PreToolUse, ToolCall: call}))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/crush/internal/llm/tools"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/shell"
 )
 
 type agentTool struct {
@@ -28,6 +29,10 @@ func (b *agentTool) Name() string {
 	return AgentToolName
 }
 
+func (b *agentTool) ReadOnly() bool {
+	return true
+}
+
 func (b *agentTool) Info() tools.ToolInfo {
 	return tools.ToolInfo{
 		Name:        AgentToolName,
@@ -60,6 +65,9 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	if err != nil {
 		return tools.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
 	}
+	// The task starts where the parent session is, without changing its shell.
+	shell.InheritSessionShell(sessionID, session.ID)
+	defer shell.RemoveSessionShell(session.ID)
 
 	done, err := b.agent.Run(ctx, session.ID, params.Prompt)
 	if err != nil {
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/hooks"
 	"github.com/charmbracelet/crush/internal/llm/prompt"
 	"github.com/charmbracelet/crush/internal/llm/provider"
 	"github.com/charmbracelet/crush/internal/llm/tools"
@@ -44,10 +46,12 @@ type AgentEvent struct {
 	Message message.Message
 	Error   error
 
-	// When summarizing
+	// SessionID is the session of the run or of the summary.
 	SessionID string
-	Progress  string
-	Done      bool
+
+	// When summarizing
+	Progress string
+	Done     bool
 }
 
 type Service interface {
@@ -69,6 +73,7 @@ type agent struct {
 	agentCfg config.Agent
 	sessions session.Service
 	messages message.Service
+	history  history.Service
 	mcpTools []McpTool
 
 	tools *csync.LazySlice[tools.BaseTool]
@@ -83,6 +88,8 @@ type agent struct {
 	activeRequests *csync.Map[string, context.CancelFunc]
 
 	promptQueue *csync.Map[string, []string]
+
+	hooks *hooks.Runner
 }
 
 var agentPromptMap = map[string]prompt.PromptID{
@@ -180,8 +187,14 @@ func NewAgent(
 		}()
 
 		cwd := cfg.WorkingDir()
+		supportsImages := func() bool {
+			model := config.Get().GetModelByType(agentCfg.Model)
+			return model != nil && model.SupportsImages
+		}
 		allTools := []tools.BaseTool{
-			tools.NewBashTool(permissions, cwd),
+			tools.NewBashTool(permissions, cwd, cfg.ShellSandbox()),
+			tools.NewBashOutputTool(),
+			tools.NewBashKillTool(),
 			tools.NewDownloadTool(permissions, cwd),
 			tools.NewEditTool(lspClients, permissions, history, cwd),
 			tools.NewMultiEditTool(lspClients, permissions, history, cwd),
@@ -190,17 +203,28 @@ func NewAgent(
 			tools.NewGrepTool(cwd),
 			tools.NewLsTool(permissions, cwd),
 			tools.NewSourcegraphTool(),
-			tools.NewViewTool(lspClients, permissions, cwd),
+			tools.NewViewTool(lspClients, permissions, cwd, supportsImages),
 			tools.NewWriteTool(lspClients, permissions, history, cwd),
 		}
 
 		mcpToolsOnce.Do(func() {
-			mcpTools = doGetMCPTools(ctx, permissions, cfg)
+			doGetMCPTools(ctx, permissions, cfg)
 		})
-		allTools = append(allTools, mcpTools...)
+		if len(GetMCPResources()) > 0 {
+			allTools = append(allTools, NewReadMCPResourceTool(supportsImages))
+		}
 
 		if len(lspClients) > 0 {
-			allTools = append(allTools, tools.NewDiagnosticsTool(lspClients))
+			allTools = append(allTools,
+				tools.NewDiagnosticsTool(lspClients),
+				tools.NewDefinitionTool(lspClients, cwd),
+				tools.NewReferencesTool(lspClients, cwd),
+				tools.NewHoverTool(lspClients, cwd),
+				tools.NewSymbolsTool(lspClients, cwd),
+				tools.NewCallGraphTool(lspClients, cwd),
+				tools.NewRenameTool(lspClients, permissions, history, cwd),
+				tools.NewCodeActionTool(lspClients, permissions, history, cwd),
+			)
 		}
 
 		if agentTool != nil {
@@ -227,12 +251,14 @@ func NewAgent(
 		providerID:          string(providerCfg.ID),
 		messages:            messages,
 		sessions:            sessions,
+		history:             history,
 		titleProvider:       titleProvider,
 		summarizeProvider:   summarizeProvider,
 		summarizeProviderID: string(providerCfg.ID),
 		activeRequests:      csync.NewMap[string, context.CancelFunc](),
 		tools:               csync.NewLazySlice(toolFn),
 		promptQueue:         csync.NewMap[string, []string](),
+		hooks:               hooks.New(cfg.Hooks, cfg.WorkingDir()),
 	}, nil
 }
 
@@ -341,7 +367,9 @@ func (a *agent) err(err error) AgentEvent {
 
 func (a *agent) Run(ctx context.Context, sessionID string, content string, attachments ...message.Attachment) (<-chan AgentEvent, error) {
 	if !a.Model().SupportsImages && attachments != nil {
-		attachments = nil
+		attachments = slices.DeleteFunc(attachments, func(attachment message.Attachment) bool {
+			return strings.HasPrefix(attachment.MimeType, "image/")
+		})
 	}
 	events := make(chan AgentEvent)
 	if a.IsSessionBusy(sessionID) {
@@ -373,6 +401,7 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string, attac
 		slog.Debug("Request completed", "sessionID", sessionID)
 		a.activeRequests.Del(sessionID)
 		cancel()
+		result.SessionID = sessionID
 		a.Publish(pubsub.CreatedEvent, result)
 		select {
 		case events <- result:
@@ -391,6 +420,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		return a.err(fmt.Errorf("failed to list messages: %w", err))
 	}
 	if len(msgs) == 0 {
+		a.runHooks(ctx, hooks.Input{Event: hooks.SessionStart, SessionID: sessionID})
 		go func() {
 			defer log.RecoverPanic("agent.Run", func() {
 				slog.Error("panic while generating title")
@@ -497,10 +527,18 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 func (a *agent) createUserMessage(ctx context.Context, sessionID, content string, attachmentParts []message.ContentPart) (message.Message, error) {
 	parts := []message.ContentPart{message.TextContent{Text: content}}
 	parts = append(parts, attachmentParts...)
-	return a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+	msg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role:  message.User,
 		Parts: parts,
 	})
+	if err != nil {
+		return msg, err
+	}
+	// Every prompt is a point the session can be reverted to.
+	if _, err := a.history.CreateCheckpoint(ctx, sessionID, msg.ID); err != nil {
+		slog.Error("Failed to create checkpoint", "session_id", sessionID, "error", err)
+	}
+	return msg, nil
 }
 
 func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
@@ -518,7 +556,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 	}
 
 	// Now collect tools (which may block on MCP initialization)
-	eventChan := a.provider.StreamResponse(ctx, msgHistory, slices.Collect(a.tools.Seq()))
+	eventChan := a.provider.StreamResponse(ctx, msgHistory, a.availableTools())
 
 	// Add the session and message ID into the context if needed by tools.
 	ctx = context.WithValue(ctx, tools.MessageIDContextKey, assistantMsg.ID)
@@ -539,105 +577,22 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 		}
 	}
 
-	toolResults := make([]message.ToolResult, len(assistantMsg.ToolCalls()))
 	toolCalls := assistantMsg.ToolCalls()
-	for i, toolCall := range toolCalls {
-		select {
-		case <-ctx.Done():
-			a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-			// Make all future tool calls cancelled
-			for j := i; j < len(toolCalls); j++ {
-				toolResults[j] = message.ToolResult{
-					ToolCallID: toolCalls[j].ID,
-					Content:    "Tool execution canceled by user",
-					IsError:    true,
-				}
-			}
-			goto out
-		default:
-			// Continue processing
-			var tool tools.BaseTool
-			for availableTool := range a.tools.Seq() {
-				if availableTool.Info().Name == toolCall.Name {
-					tool = availableTool
-					break
-				}
-			}
-
-			// Tool not found
-			if tool == nil {
-				toolResults[i] = message.ToolResult{
-					ToolCallID: toolCall.ID,
-					Content:    fmt.Sprintf("Tool not found: %s", toolCall.Name),
-					IsError:    true,
-				}
-				continue
-			}
-
-			// Run tool in goroutine to allow cancellation
-			type toolExecResult struct {
-				response tools.ToolResponse
-				err      error
-			}
-			resultChan := make(chan toolExecResult, 1)
-
-			go func() {
-				response, err := tool.Run(ctx, tools.ToolCall{
-					ID:    toolCall.ID,
-					Name:  toolCall.Name,
-					Input: toolCall.Input,
-				})
-				resultChan <- toolExecResult{response: response, err: err}
-			}()
-
-			var toolResponse tools.ToolResponse
-			var toolErr error
-
-			select {
-			case <-ctx.Done():
-				a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-				// Mark remaining tool calls as cancelled
-				for j := i; j < len(toolCalls); j++ {
-					toolResults[j] = message.ToolResult{
-						ToolCallID: toolCalls[j].ID,
-						Content:    "Tool execution canceled by user",
-						IsError:    true,
-					}
-				}
-				goto out
-			case result := <-resultChan:
-				toolResponse = result.response
-				toolErr = result.err
-			}
-
-			if toolErr != nil {
-				slog.Error("Tool execution error", "toolCall", toolCall.ID, "error", toolErr)
-				if errors.Is(toolErr, permission.ErrorPermissionDenied) {
-					toolResults[i] = message.ToolResult{
-						ToolCallID: toolCall.ID,
-						Content:    "Permission denied",
-						IsError:    true,
-					}
-					for j := i + 1; j < len(toolCalls); j++ {
-						toolResults[j] = message.ToolResult{
-							ToolCallID: toolCalls[j].ID,
-							Content:    "Tool execution canceled by user",
-							IsError:    true,
-						}
-					}
-					a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied, "Permission denied", "")
-					break
-				}
-			}
-			toolResults[i] = message.ToolResult{
-				ToolCallID: toolCall.ID,
-				Content:    toolResponse.Content,
-				Metadata:   toolResponse.Metadata,
-				IsError:    toolResponse.IsError,
-			}
-		}
+	if len(toolCalls) == 0 {
+		a.runHooks(ctx, hooks.Input{
+			Event:        hooks.TurnEnd,
+			SessionID:    sessionID,
+			FinishReason: string(assistantMsg.FinishReason()),
+		})
+	}
+	toolResults, denied := runToolCalls(ctx, toolCalls, a.findHookedTool, a.maxParallelTools())
+	switch {
+	case ctx.Err() != nil:
+		a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
+	case denied:
+		a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied, "Permission denied", "")
 	}
-out:
+
 	if len(toolResults) == 0 {
 		return assistantMsg, nil, nil
 	}
@@ -657,6 +612,42 @@ out:
 	return assistantMsg, &msg, err
 }
 
+// availableTools returns the tools of the agent, including the tools of the
+// MCP servers that connected after the agent tools were initialized.
+func (a *agent) availableTools() []tools.BaseTool {
+	available := slices.Collect(a.tools.Seq())
+	for _, tool := range getMCPTools() {
+		if a.agentCfg.AllowedTools == nil || slices.Contains(a.agentCfg.AllowedTools, tool.Name()) {
+			available = append(available, tool)
+		}
+	}
+	return available
+}
+
+// findTool returns the available tool with the given name, or nil.
+func (a *agent) findTool(name string) tools.BaseTool {
+	for _, tool := range a.availableTools() {
+		if tool.Info().Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+// findHookedTool returns the available tool with the given name, wrapped to
+// run the tool use hooks, or nil.
+func (a *agent) findHookedTool(name string) tools.BaseTool {
+	tool := a.findTool(name)
+	if tool == nil || a.hooks == nil {
+		return tool
+	}
+	return hookedTool{BaseTool: tool, hooks: a.hooks}
+}
+
+func (a *agent) maxParallelTools() int {
+	return cmp.Or(config.Get().Options.MaxParallelTools, defaultMaxParallelTools)
+}
+
 func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishReason message.FinishReason, message, details string) {
 	msg.AddFinish(finishReason, message, details)
 	_ = a.messages.Update(ctx, *msg)
@@ -672,23 +663,18 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 
 	switch event.Type {
 	case provider.EventThinkingDelta:
-		assistantMsg.AppendReasoningContent(event.Thinking)
-		return a.messages.Update(ctx, *assistantMsg)
+		return a.streamDelta(ctx, assistantMsg, message.ReasoningDelta, "", event.Thinking)
 	case provider.EventSignatureDelta:
-		assistantMsg.AppendReasoningSignature(event.Signature)
-		return a.messages.Update(ctx, *assistantMsg)
+		return a.streamDelta(ctx, assistantMsg, message.SignatureDelta, "", event.Signature)
 	case provider.EventContentDelta:
-		assistantMsg.FinishThinking()
-		assistantMsg.AppendContent(event.Content)
-		return a.messages.Update(ctx, *assistantMsg)
+		return a.streamDelta(ctx, assistantMsg, message.ContentDelta, "", event.Content)
 	case provider.EventToolUseStart:
 		assistantMsg.FinishThinking()
 		slog.Info("Tool call started", "toolCall", event.ToolCall)
 		assistantMsg.AddToolCall(*event.ToolCall)
 		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventToolUseDelta:
-		assistantMsg.AppendToolCallInput(event.ToolCall.ID, event.ToolCall.Input)
-		return a.messages.Update(ctx, *assistantMsg)
+		return a.streamDelta(ctx, assistantMsg, message.ToolCallInputDelta, event.ToolCall.ID, event.ToolCall.Input)
 	case provider.EventToolUseStop:
 		slog.Info("Finished tool call", "toolCall", event.ToolCall)
 		assistantMsg.FinishToolCall(event.ToolCall.ID)
@@ -708,6 +694,14 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 	return nil
 }
 
+// streamDelta appends streamed text to the assistant message. The message is
+// persisted in batches, the deltas are published as they come.
+func (a *agent) streamDelta(ctx context.Context, assistantMsg *message.Message, typ message.DeltaType, toolCallID, content string) error {
+	delta := assistantMsg.NewDelta(typ, toolCallID, content)
+	assistantMsg.ApplyDelta(delta)
+	return a.messages.Stream(ctx, *assistantMsg, delta)
+}
+
 func (a *agent) TrackUsage(ctx context.Context, sessionID string, model catwalk.Model, usage provider.TokenUsage) error {
 	sess, err := a.sessions.Get(ctx, sessionID)
 	if err != nil {
@@ -833,7 +827,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			a.Publish(pubsub.CreatedEvent, event)
 			return
 		}
-		shell := shell.GetPersistentShell(config.Get().WorkingDir())
+		shell := shell.GetSessionShell(sessionID)
 		summary += "\n\n**Current working directory of the persistent shell**\n\n" + shell.GetWorkingDir()
 		event = AgentEvent{
 			Type:     AgentEventTypeSummarize,
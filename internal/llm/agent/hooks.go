@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/hooks"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+)
+
+// hookedTool runs the tool use hooks around the calls to a tool.
+type hookedTool struct {
+	tools.BaseTool
+	hooks *hooks.Runner
+}
+
+func (t hookedTool) ReadOnly() bool {
+	return tools.IsReadOnly(t.BaseTool)
+}
+
+func (t hookedTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	sessionID, _ := tools.GetContextValues(ctx)
+	input := hooks.Input{
+		Event:     hooks.PreToolUse,
+		SessionID: sessionID,
+		ToolCall:  &call,
+		Files:     toolCallFiles(call),
+	}
+	var blocked *hooks.BlockedError
+	if err := t.hooks.Run(ctx, input); errors.As(err, &blocked) {
+		return tools.NewTextErrorResponse(blocked.Error()), nil
+	} else if err != nil {
+		slog.Error("Failed to run hooks", "event", input.Event, "error", err)
+	}
+
+	response, err := t.BaseTool.Run(ctx, call)
+	if err != nil {
+		return response, err
+	}
+
+	input.Event = hooks.PostToolUse
+	input.ToolResponse = &response
+	// Workspace edits only tell the files they changed in their response.
+	for _, file := range jsonFiles(response.Metadata) {
+		if !slices.Contains(input.Files, file) {
+			input.Files = append(input.Files, file)
+		}
+	}
+	if err := t.hooks.Run(ctx, input); errors.As(err, &blocked) {
+		response.Content += "\n\n" + blocked.Error()
+	} else if err != nil {
+		slog.Error("Failed to run hooks", "event", input.Event, "error", err)
+	}
+	return response, nil
+}
+
+// toolCallFiles returns the files a tool call touches, as far as its input
+// tells.
+func toolCallFiles(call tools.ToolCall) []string {
+	return jsonFiles(call.Input)
+}
+
+// jsonFiles returns the files of the input or response metadata of a tool,
+// in its file_path field or the file_path fields of its files, as in
+// workspace edits.
+func jsonFiles(data string) []string {
+	var params struct {
+		FilePath string `json:"file_path"`
+		Files    []struct {
+			FilePath string `json:"file_path"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		return nil
+	}
+	var files []string
+	if params.FilePath != "" {
+		files = append(files, params.FilePath)
+	}
+	for _, f := range params.Files {
+		if f.FilePath != "" && !slices.Contains(files, f.FilePath) {
+			files = append(files, f.FilePath)
+		}
+	}
+	return files
+}
+
+// runHooks runs the hooks of an event that cannot be blocked.
+func (a *agent) runHooks(ctx context.Context, input hooks.Input) {
+	if err := a.hooks.Run(ctx, input); err != nil {
+		slog.Warn("Hook failed", "event", input.Event, "error", err)
+	}
+}
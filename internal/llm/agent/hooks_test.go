@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/hooks"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunToolCalls_Hooks(t *testing.T) {
+	t.Parallel()
+
+	runner := hooks.New(&config.Hooks{
+		PreToolUse:  []config.Hook{{Command: "echo 'use view instead' >&2; exit 2", Tools: []string{"cat"}}},
+		PostToolUse: []config.Hook{{Command: "echo 'run the tests' >&2; exit 2", Tools: []string{"edit"}}},
+	}, t.TempDir())
+	ran := false
+	cat := &fakeTool{name: "cat", readOnly: true, run: func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		ran = true
+		return echo(ctx, call)
+	}}
+	edit := &fakeTool{name: "edit", run: echo}
+	lookup := func(name string) tools.BaseTool {
+		if tool := lookupIn(cat, edit)(name); tool != nil {
+			return hookedTool{BaseTool: tool, hooks: runner}
+		}
+		return nil
+	}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "cat", Input: `{"file_path":"main.go"}`},
+		{ID: "2", Name: "edit", Input: "a"},
+	}
+	results, denied := runToolCalls(t.Context(), calls, lookup, 4)
+	require.False(t, denied)
+	require.False(t, ran)
+	require.True(t, results[0].IsError)
+	require.Contains(t, results[0].Content, "use view instead")
+	require.False(t, results[1].IsError)
+	require.True(t, strings.HasPrefix(results[1].Content, "a\n\n"))
+	require.Contains(t, results[1].Content, "run the tests")
+}
+
+func TestHookedTool_WorkspaceEditFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runner := hooks.New(&config.Hooks{
+		PostToolUse: []config.Hook{{Command: "cat > post.json"}},
+	}, dir)
+	rename := hookedTool{hooks: runner, BaseTool: &fakeTool{name: "rename", run: func(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+		return tools.WithResponseMetadata(tools.NewTextResponse("renamed"), tools.WorkspaceEditResponseMetadata{
+			Files: []tools.WorkspaceEditFile{{FilePath: "a.go"}, {FilePath: "b.go"}},
+		}), nil
+	}}}
+
+	_, err := rename.Run(t.Context(), tools.ToolCall{ID: "1", Name: "rename", Input: `{"file_path":"a.go"}`})
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "post.json"))
+	require.NoError(t, err)
+	var input hooks.Input
+	require.NoError(t, json.Unmarshal(data, &input))
+	require.Equal(t, []string{"a.go", "b.go"}, input.Files)
+}
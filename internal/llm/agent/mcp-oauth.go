@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// mcpAuthorizationTimeout is how long the user has to authorize an MCP
+// server in the browser.
+const mcpAuthorizationTimeout = 5 * time.Minute
+
+// mcpCredentials are the OAuth credentials of an MCP server stored on disk.
+type mcpCredentials struct {
+	// ClientID and ClientSecret are set when the client was registered
+	// dynamically.
+	ClientID     string           `json:"client_id,omitempty"`
+	ClientSecret string           `json:"client_secret,omitempty"`
+	Token        *transport.Token `json:"token,omitempty"`
+}
+
+// mcpTokenStore stores the OAuth credentials of an MCP server in a file only
+// readable by the user.
+type mcpTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+var _ transport.TokenStore = (*mcpTokenStore)(nil)
+
+func newMCPTokenStore(dataDir, name string) *mcpTokenStore {
+	return &mcpTokenStore{path: filepath.Join(dataDir, "mcp-oauth", name+".json")}
+}
+
+func (s *mcpTokenStore) load() (mcpCredentials, error) {
+	var creds mcpCredentials
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, nil
+	}
+	if err != nil {
+		return creds, err
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return creds, fmt.Errorf("invalid credentials in %s: %w", s.path, err)
+	}
+	return creds, nil
+}
+
+func (s *mcpTokenStore) save(creds mcpCredentials) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Client returns the dynamically registered client, if any.
+func (s *mcpTokenStore) Client() (id, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, err := s.load()
+	if err != nil {
+		slog.Warn("Failed to load MCP credentials", "path", s.path, "error", err)
+	}
+	return creds.ClientID, creds.ClientSecret
+}
+
+// SaveClient stores a dynamically registered client.
+func (s *mcpTokenStore) SaveClient(id, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, _ := s.load()
+	creds.ClientID, creds.ClientSecret = id, secret
+	return s.save(creds)
+}
+
+// GetToken implements transport.TokenStore.
+func (s *mcpTokenStore) GetToken() (*transport.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if creds.Token == nil {
+		return nil, errors.New("no token available")
+	}
+	return creds.Token, nil
+}
+
+// SaveToken implements transport.TokenStore.
+func (s *mcpTokenStore) SaveToken(token *transport.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, _ := s.load()
+	creds.Token = token
+	return s.save(creds)
+}
+
+// mcpOAuthConfig returns the OAuth configuration of an MCP server, using the
+// client registered dynamically on a previous run when none is configured.
+func mcpOAuthConfig(o config.MCPOAuthConfig, store *mcpTokenStore) transport.OAuthConfig {
+	clientID, clientSecret := o.ClientID, o.ResolvedClientSecret()
+	if clientID == "" {
+		clientID, clientSecret = store.Client()
+	}
+	return transport.OAuthConfig{
+		ClientID:              clientID,
+		ClientSecret:          clientSecret,
+		RedirectURI:           o.RedirectURI(),
+		Scopes:                o.Scopes,
+		TokenStore:            store,
+		AuthServerMetadataURL: o.MetadataURL,
+		PKCEEnabled:           true,
+	}
+}
+
+// authorizeMCP runs the OAuth authorization code flow with PKCE for an MCP
+// server. openURL shows the authorization URL to the user, and the flow
+// completes when the browser is redirected to the local redirect URI.
+func authorizeMCP(ctx context.Context, handler *transport.OAuthHandler, store *mcpTokenStore, redirectURI string, openURL func(string) error) error {
+	ctx, cancel := context.WithTimeout(ctx, mcpAuthorizationTimeout)
+	defer cancel()
+
+	if handler.GetClientID() == "" {
+		if err := handler.RegisterClient(ctx, "Crush"); err != nil {
+			return fmt.Errorf("failed to register client: %w", err)
+		}
+		if err := store.SaveClient(handler.GetClientID(), handler.GetClientSecret()); err != nil {
+			return fmt.Errorf("failed to save client: %w", err)
+		}
+	}
+
+	verifier, err := transport.GenerateCodeVerifier()
+	if err != nil {
+		return err
+	}
+	state, err := transport.GenerateState()
+	if err != nil {
+		return err
+	}
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return fmt.Errorf("invalid redirect URI: %w", err)
+	}
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return fmt.Errorf("failed to listen for the authorization callback: %w", err)
+	}
+	type callback struct {
+		code, state string
+		err         error
+	}
+	callbacks := make(chan callback, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		result := callback{code: query.Get("code"), state: query.Get("state")}
+		if e := query.Get("error"); e != "" {
+			result.err = fmt.Errorf("authorization denied: %s %s", e, query.Get("error_description"))
+			http.Error(w, "Authorization failed, you can close this window.", http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete, you can close this window and return to Crush.")
+		}
+		select {
+		case callbacks <- result:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Close()
+
+	authURL, err := handler.GetAuthorizationURL(ctx, state, transport.GenerateCodeChallenge(verifier))
+	if err != nil {
+		return fmt.Errorf("failed to build authorization URL: %w", err)
+	}
+	if err := openURL(authURL); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("authorization not completed: %w", ctx.Err())
+	case result := <-callbacks:
+		if result.err != nil {
+			return result.err
+		}
+		if err := handler.ProcessAuthorizationResponse(ctx, result.code, result.state, verifier); err != nil {
+			return fmt.Errorf("failed to exchange authorization code: %w", err)
+		}
+		return nil
+	}
+}
+
+// refreshMCPToken exchanges the stored refresh token of an MCP server for a
+// new access token, for when the server rejects a token that has not
+// expired yet.
+func refreshMCPToken(ctx context.Context, handler *transport.OAuthHandler, store *mcpTokenStore) error {
+	token, err := store.GetToken()
+	if err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		return errors.New("no refresh token available")
+	}
+	_, err = handler.RefreshToken(ctx, token.RefreshToken)
+	return err
+}
+
+// openMCPAuthorizationURL shows the authorization URL of an MCP server in
+// the MCP state and opens it in the browser.
+func openMCPAuthorizationURL(name string) func(string) error {
+	return func(authURL string) error {
+		slog.Info("Authorize MCP server", "name", name, "url", authURL)
+		updateMCPAuthorizingState(name, authURL)
+		if err := openBrowser(authURL); err != nil {
+			slog.Warn("Failed to open browser", "error", err)
+		}
+		return nil
+	}
+}
+
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() //nolint:errcheck
+	return nil
+}
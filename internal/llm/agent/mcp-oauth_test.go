@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthServer is an OAuth authorization server supporting dynamic client
+// registration, the authorization code flow with PKCE and refresh tokens.
+func fakeAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var challenge string
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("GET /.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(transport.AuthServerMetadata{
+			Issuer:                srv.URL,
+			AuthorizationEndpoint: srv.URL + "/authorize",
+			TokenEndpoint:         srv.URL + "/token",
+			RegistrationEndpoint:  srv.URL + "/register",
+		})
+	})
+	mux.HandleFunc("POST /register", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"client_id": "registered-client"}`)
+	})
+	mux.HandleFunc("GET /authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "registered-client" || query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		challenge = query.Get("code_challenge")
+		redirect, _ := url.Parse(query.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {"the-code"}, "state": {query.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "the-code" || transport.GenerateCodeChallenge(r.Form.Get("code_verifier")) != challenge {
+				http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "access-1", "token_type": "bearer", "refresh_token": "refresh-1", "expires_in": 3600}`)
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "refresh-1" {
+				http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600}`)
+		default:
+			http.Error(w, `{"error": "unsupported_grant_type"}`, http.StatusBadRequest)
+		}
+	})
+	return srv
+}
+
+func freeRedirectURI(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return fmt.Sprintf("http://%s/callback", l.Addr())
+}
+
+func TestAuthorizeMCP(t *testing.T) {
+	t.Parallel()
+
+	srv := fakeAuthServer(t)
+	dataDir := t.TempDir()
+	redirectURI := freeRedirectURI(t)
+	store := newMCPTokenStore(dataDir, "remote")
+	handler := transport.NewOAuthHandler(transport.OAuthConfig{
+		RedirectURI:           redirectURI,
+		TokenStore:            store,
+		AuthServerMetadataURL: srv.URL + "/.well-known/oauth-authorization-server",
+		PKCEEnabled:           true,
+	})
+
+	// The "browser" follows the authorization URL to the local callback.
+	openURL := func(authURL string) error {
+		resp, err := http.Get(authURL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	require.NoError(t, authorizeMCP(context.Background(), handler, store, redirectURI, openURL))
+
+	// The credentials survive a restart.
+	store = newMCPTokenStore(dataDir, "remote")
+	token, err := store.GetToken()
+	require.NoError(t, err)
+	require.Equal(t, "access-1", token.AccessToken)
+	require.Equal(t, "refresh-1", token.RefreshToken)
+	id, _ := store.Client()
+	require.Equal(t, "registered-client", id)
+
+	info, err := os.Stat(store.path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, refreshMCPToken(context.Background(), handler, store))
+	token, err = store.GetToken()
+	require.NoError(t, err)
+	require.Equal(t, "access-2", token.AccessToken)
+	require.Equal(t, "refresh-1", token.RefreshToken)
+}
+
+func TestAuthorizeMCPDenied(t *testing.T) {
+	t.Parallel()
+
+	srv := fakeAuthServer(t)
+	redirectURI := freeRedirectURI(t)
+	store := newMCPTokenStore(t.TempDir(), "remote")
+	handler := transport.NewOAuthHandler(transport.OAuthConfig{
+		ClientID:              "registered-client",
+		RedirectURI:           redirectURI,
+		TokenStore:            store,
+		AuthServerMetadataURL: srv.URL + "/.well-known/oauth-authorization-server",
+		PKCEEnabled:           true,
+	})
+
+	openURL := func(string) error {
+		resp, err := http.Get(redirectURI + "?error=access_denied")
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	err := authorizeMCP(context.Background(), handler, store, redirectURI, openURL)
+	require.ErrorContains(t, err, "access_denied")
+
+	_, err = store.GetToken()
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MCPPrompt is a prompt offered by an MCP server.
+type MCPPrompt struct {
+	MCPName string
+	Prompt  mcp.Prompt
+}
+
+var mcpPrompts = csync.NewMap[string, []mcp.Prompt]()
+
+func getPrompts(ctx context.Context, name string, c *client.Client) {
+	if c.GetServerCapabilities().Prompts == nil {
+		return
+	}
+	result, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		slog.Error("error listing prompts", "error", err, "name", name)
+		return
+	}
+	mcpPrompts.Set(name, result.Prompts)
+}
+
+// GetMCPPrompts returns the prompts of the connected MCP servers, sorted by
+// server and name.
+func GetMCPPrompts() []MCPPrompt {
+	var prompts []MCPPrompt
+	for name, serverPrompts := range mcpPrompts.Seq2() {
+		for _, prompt := range serverPrompts {
+			prompts = append(prompts, MCPPrompt{MCPName: name, Prompt: prompt})
+		}
+	}
+	slices.SortFunc(prompts, func(a, b MCPPrompt) int {
+		return cmp.Or(
+			strings.Compare(a.MCPName, b.MCPName),
+			strings.Compare(a.Prompt.Name, b.Prompt.Name),
+		)
+	})
+	return prompts
+}
+
+// GetMCPPrompt renders a prompt of an MCP server with the given arguments as
+// the text of a message.
+func GetMCPPrompt(ctx context.Context, mcpName, promptName string, args map[string]string) (string, error) {
+	c, err := getOrRenewClient(ctx, mcpName)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.GetPrompt(ctx, mcp.GetPromptRequest{
+		Params: mcp.GetPromptParams{
+			Name:      promptName,
+			Arguments: args,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get prompt %s from %s: %w", promptName, mcpName, err)
+	}
+
+	parts := make([]string, 0, len(result.Messages))
+	for _, msg := range result.Messages {
+		switch content := msg.Content.(type) {
+		case mcp.TextContent:
+			parts = append(parts, content.Text)
+		case mcp.EmbeddedResource:
+			if text, ok := content.Resource.(mcp.TextResourceContents); ok {
+				parts = append(parts, fmt.Sprintf("<resource uri=%q>\n%s\n</resource>", text.URI, text.Text))
+			}
+		default:
+			slog.Warn("Skipping unsupported MCP prompt content", "name", mcpName, "prompt", promptName, "type", fmt.Sprintf("%T", content))
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("prompt %s from %s has no text content", promptName, mcpName)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MCPResource is a resource offered by an MCP server.
+type MCPResource struct {
+	MCPName  string
+	Resource mcp.Resource
+}
+
+var mcpResources = csync.NewMap[string, []mcp.Resource]()
+
+func getResources(ctx context.Context, name string, c *client.Client) {
+	if c.GetServerCapabilities().Resources == nil {
+		return
+	}
+	result, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		slog.Error("error listing resources", "error", err, "name", name)
+		return
+	}
+	mcpResources.Set(name, result.Resources)
+}
+
+// GetMCPResources returns the resources of the connected MCP servers, sorted
+// by server and name.
+func GetMCPResources() []MCPResource {
+	var resources []MCPResource
+	for name, serverResources := range mcpResources.Seq2() {
+		for _, resource := range serverResources {
+			resources = append(resources, MCPResource{MCPName: name, Resource: resource})
+		}
+	}
+	slices.SortFunc(resources, func(a, b MCPResource) int {
+		return cmp.Or(
+			strings.Compare(a.MCPName, b.MCPName),
+			strings.Compare(a.Resource.Name, b.Resource.Name),
+		)
+	})
+	return resources
+}
+
+// ReadMCPResource reads a resource of an MCP server.
+func ReadMCPResource(ctx context.Context, mcpName, uri string) ([]mcp.ResourceContents, error) {
+	c, err := getOrRenewClient(ctx, mcpName)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.ReadResource(ctx, mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", uri, mcpName, err)
+	}
+	return result.Contents, nil
+}
+
+// MCPResourceAttachment reads a resource of an MCP server to attach it to a
+// message.
+func MCPResourceAttachment(ctx context.Context, resource MCPResource) (message.Attachment, error) {
+	contents, err := ReadMCPResource(ctx, resource.MCPName, resource.Resource.URI)
+	if err != nil {
+		return message.Attachment{}, err
+	}
+	return resourceAttachment(resource, contents)
+}
+
+// resourceAttachment turns the contents of a resource into an attachment.
+// Binary contents are only attached when they are images or text, as other
+// attachments are sent to the model as text.
+func resourceAttachment(resource MCPResource, contents []mcp.ResourceContents) (message.Attachment, error) {
+	attachment := message.Attachment{
+		FilePath: resource.Resource.URI,
+		FileName: resource.Resource.Name,
+		MimeType: resource.Resource.MIMEType,
+	}
+	var texts []string
+	for _, content := range contents {
+		switch content := content.(type) {
+		case mcp.TextResourceContents:
+			texts = append(texts, content.Text)
+			attachment.MimeType = cmp.Or(attachment.MimeType, content.MIMEType)
+		case mcp.BlobResourceContents:
+			data, err := base64.StdEncoding.DecodeString(content.Blob)
+			if err != nil {
+				return message.Attachment{}, fmt.Errorf("invalid resource content: %w", err)
+			}
+			mimeType := cmp.Or(content.MIMEType, attachment.MimeType)
+			if !strings.HasPrefix(mimeType, "image/") && (!isTextMIMEType(mimeType) || !utf8.Valid(data)) {
+				return message.Attachment{}, fmt.Errorf("resource %s from %s is binary content of type %s, which cannot be attached", resource.Resource.URI, resource.MCPName, cmp.Or(mimeType, "unknown"))
+			}
+			attachment.Content = data
+			attachment.MimeType = mimeType
+			return attachment, nil
+		}
+	}
+	if len(texts) == 0 {
+		return message.Attachment{}, fmt.Errorf("resource %s from %s is empty", resource.Resource.URI, resource.MCPName)
+	}
+	attachment.Content = []byte(strings.Join(texts, "\n"))
+	attachment.MimeType = cmp.Or(attachment.MimeType, "text/plain")
+	return attachment, nil
+}
+
+// isTextMIMEType reports whether a MIME type is text, such as text/plain,
+// application/json or application/ld+json.
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml", "application/javascript", "application/toml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+type ReadMCPResourceParams struct {
+	MCPName string `json:"mcp_name"`
+	URI     string `json:"uri"`
+}
+
+type readMCPResourceTool struct {
+	supportsImages func() bool
+}
+
+const (
+	ReadMCPResourceToolName    = "read_mcp_resource"
+	readMCPResourceDescription = `Read the resources offered by the connected MCP servers, such as documents, schemas or records.
+
+WHEN TO USE THIS TOOL:
+- Use when you need information the MCP servers expose as resources
+- Use without a URI to list the available resources
+
+HOW TO USE:
+- Provide the MCP server name and the URI of the resource to read it
+- Leave the URI empty to list the resources, optionally only those of one server
+
+FEATURES:
+- Returns the text of the resource
+- Returns images to models that support them
+
+LIMITATIONS:
+- Other binary resources are described but not returned`
+)
+
+// NewReadMCPResourceTool returns the tool reading MCP resources.
+// supportsImages reports whether the current model accepts images.
+func NewReadMCPResourceTool(supportsImages func() bool) tools.BaseTool {
+	return &readMCPResourceTool{supportsImages: supportsImages}
+}
+
+func (r *readMCPResourceTool) Name() string {
+	return ReadMCPResourceToolName
+}
+
+func (r *readMCPResourceTool) ReadOnly() bool {
+	return true
+}
+
+func (r *readMCPResourceTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{
+		Name:        ReadMCPResourceToolName,
+		Description: readMCPResourceDescription,
+		Parameters: map[string]any{
+			"mcp_name": map[string]any{
+				"type":        "string",
+				"description": "The name of the MCP server offering the resource",
+			},
+			"uri": map[string]any{
+				"type":        "string",
+				"description": "The URI of the resource, leave empty to list the resources",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (r *readMCPResourceTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	var params ReadMCPResourceParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return tools.NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.URI == "" {
+		return tools.NewTextResponse(formatMCPResources(params.MCPName)), nil
+	}
+	if params.MCPName == "" {
+		return tools.NewTextErrorResponse("mcp_name is required to read a resource"), nil
+	}
+
+	contents, err := ReadMCPResource(ctx, params.MCPName, params.URI)
+	if err != nil {
+		return tools.NewTextErrorResponse(err.Error()), nil
+	}
+
+	var output []string
+	for _, content := range contents {
+		switch content := content.(type) {
+		case mcp.TextResourceContents:
+			output = append(output, content.Text)
+		case mcp.BlobResourceContents:
+			if strings.HasPrefix(content.MIMEType, "image/") && r.supportsImages != nil && r.supportsImages() {
+				data, err := base64.StdEncoding.DecodeString(content.Blob)
+				if err != nil {
+					return tools.NewTextErrorResponse(fmt.Sprintf("invalid resource content: %s", err)), nil
+				}
+				return tools.NewImageResponse(fmt.Sprintf("Image resource: %s", content.URI), data, content.MIMEType), nil
+			}
+			output = append(output, fmt.Sprintf("(binary content of type %s, %d bytes base64 encoded, not shown)", cmp.Or(content.MIMEType, "unknown"), len(content.Blob)))
+		}
+	}
+	if len(output) == 0 {
+		return tools.NewTextResponse("The resource is empty"), nil
+	}
+	return tools.NewTextResponse(strings.Join(output, "\n")), nil
+}
+
+// formatMCPResources lists the resources of an MCP server, or of all of them
+// when mcpName is empty.
+func formatMCPResources(mcpName string) string {
+	var sb strings.Builder
+	for _, r := range GetMCPResources() {
+		if mcpName != "" && r.MCPName != mcpName {
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s: %s (%s)", r.MCPName, r.Resource.Name, r.Resource.URI)
+		if r.Resource.MIMEType != "" {
+			fmt.Fprintf(&sb, " [%s]", r.Resource.MIMEType)
+		}
+		if r.Resource.Description != "" {
+			fmt.Fprintf(&sb, " - %s", r.Resource.Description)
+		}
+		sb.WriteString("\n")
+	}
+	if sb.Len() == 0 {
+		return "No MCP resources available"
+	}
+	return sb.String()
+}
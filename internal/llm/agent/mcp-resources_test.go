@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMCPResourceToolLists(t *testing.T) {
+	t.Parallel()
+
+	mcpResources.Set("notes", []mcp.Resource{
+		{URI: "notes://todo", Name: "todo", MIMEType: "text/markdown"},
+		{URI: "notes://ideas", Name: "ideas", Description: "Loose ideas"},
+	})
+	mcpResources.Set("db", []mcp.Resource{
+		{URI: "db://schema", Name: "schema"},
+	})
+
+	resources := GetMCPResources()
+	require.Len(t, resources, 3)
+	require.Equal(t, "db", resources[0].MCPName)
+	require.Equal(t, "ideas", resources[1].Resource.Name)
+	require.Equal(t, "todo", resources[2].Resource.Name)
+
+	tool := NewReadMCPResourceTool(nil)
+
+	resp, err := tool.Run(context.Background(), tools.ToolCall{Input: `{}`})
+	require.NoError(t, err)
+	require.Equal(t, "- db: schema (db://schema)\n"+
+		"- notes: ideas (notes://ideas) - Loose ideas\n"+
+		"- notes: todo (notes://todo) [text/markdown]\n", resp.Content)
+
+	resp, err = tool.Run(context.Background(), tools.ToolCall{Input: `{"mcp_name": "db"}`})
+	require.NoError(t, err)
+	require.Equal(t, "- db: schema (db://schema)\n", resp.Content)
+
+	resp, err = tool.Run(context.Background(), tools.ToolCall{Input: `{"uri": "db://schema"}`})
+	require.NoError(t, err)
+	require.True(t, resp.IsError)
+}
+
+func TestResourceAttachment(t *testing.T) {
+	t.Parallel()
+
+	resource := MCPResource{MCPName: "docs", Resource: mcp.Resource{URI: "docs://spec", Name: "spec"}}
+	blob := func(mimeType string, data []byte) []mcp.ResourceContents {
+		return []mcp.ResourceContents{mcp.BlobResourceContents{
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		}}
+	}
+
+	attachment, err := resourceAttachment(resource, []mcp.ResourceContents{
+		mcp.TextResourceContents{Text: "line 1"},
+		mcp.TextResourceContents{Text: "line 2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "line 1\nline 2", string(attachment.Content))
+	require.Equal(t, "text/plain", attachment.MimeType)
+
+	attachment, err = resourceAttachment(resource, blob("image/png", []byte{0x89, 'P', 'N', 'G'}))
+	require.NoError(t, err)
+	require.Equal(t, "image/png", attachment.MimeType)
+
+	attachment, err = resourceAttachment(resource, blob("application/json; charset=utf-8", []byte(`{"a":1}`)))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(attachment.Content))
+
+	// Binary contents would be sent to the model as text.
+	_, err = resourceAttachment(resource, blob("application/pdf", []byte("%PDF-1.7\x00\xff")))
+	require.ErrorContains(t, err, "cannot be attached")
+	_, err = resourceAttachment(resource, blob("", []byte{0x00, 0xff}))
+	require.Error(t, err)
+	_, err = resourceAttachment(resource, blob("text/plain", []byte{0xff, 0xfe}))
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -31,6 +32,7 @@ const (
 	MCPStateStarting
 	MCPStateConnected
 	MCPStateError
+	MCPStateAuthorizing
 )
 
 func (s MCPState) String() string {
@@ -43,6 +45,8 @@ func (s MCPState) String() string {
 		return "connected"
 	case MCPStateError:
 		return "error"
+	case MCPStateAuthorizing:
+		return "authorizing"
 	default:
 		return "unknown"
 	}
@@ -62,6 +66,9 @@ type MCPEvent struct {
 	State     MCPState
 	Error     error
 	ToolCount int
+	// AuthURL is the URL the user authorizes the server at, while it is
+	// authorizing.
+	AuthURL string
 }
 
 // MCPClientInfo holds information about an MCP client's state
@@ -72,16 +79,21 @@ type MCPClientInfo struct {
 	Client      *client.Client
 	ToolCount   int
 	ConnectedAt time.Time
+	AuthURL     string
 }
 
 var (
 	mcpToolsOnce sync.Once
-	mcpTools     []tools.BaseTool
+	mcpTools     = csync.NewMap[string, []tools.BaseTool]()
 	mcpClients   = csync.NewMap[string, *client.Client]()
 	mcpStates    = csync.NewMap[string, MCPClientInfo]()
 	mcpBroker    = pubsub.NewBroker[MCPEvent]()
 )
 
+// errMCPAuthorizing is returned while an MCP server waits for the user to
+// authorize it.
+var errMCPAuthorizing = errors.New("waiting for the user to authorize the mcp server")
+
 type McpTool struct {
 	mcpName     string
 	tool        mcp.Tool
@@ -93,6 +105,13 @@ func (b *McpTool) Name() string {
 	return fmt.Sprintf("mcp_%s_%s", b.mcpName, b.tool.Name)
 }
 
+// ReadOnly reports whether the server flagged the tool with the read-only
+// hint annotation.
+func (b *McpTool) ReadOnly() bool {
+	hint := b.tool.Annotations.ReadOnlyHint
+	return hint != nil && *hint
+}
+
 func (b *McpTool) Info() tools.ToolInfo {
 	required := b.tool.InputSchema.Required
 	if required == nil {
@@ -142,28 +161,44 @@ func runTool(ctx context.Context, name, toolName string, input string) (tools.To
 }
 
 func getOrRenewClient(ctx context.Context, name string) (*client.Client, error) {
+	state, _ := mcpStates.Get(name)
+	if state.State == MCPStateAuthorizing {
+		return nil, errMCPAuthorizing
+	}
 	c, ok := mcpClients.Get(name)
 	if !ok {
 		return nil, fmt.Errorf("mcp '%s' not available", name)
 	}
 
 	m := config.Get().MCP[name]
-	state, _ := mcpStates.Get(name)
 
 	pingCtx, cancel := context.WithTimeout(ctx, mcpTimeout(m))
 	defer cancel()
 	err := c.Ping(pingCtx)
+	if client.IsOAuthAuthorizationRequiredError(err) && m.OAuth != nil {
+		if refreshMCPToken(pingCtx, client.GetOAuthHandler(err), mcpTokenStoreFor(name)) == nil {
+			err = c.Ping(pingCtx)
+		}
+	}
 	if err == nil {
 		return c, nil
 	}
 	updateMCPState(name, MCPStateError, err, nil, state.ToolCount)
 
-	c, err = createAndInitializeClient(ctx, name, m)
+	return reconnectMCP(ctx, name, m, state.ToolCount)
+}
+
+// reconnectMCP replaces the client of an MCP server whose tools are already
+// loaded.
+func reconnectMCP(ctx context.Context, name string, m config.MCPConfig, toolCount int) (*client.Client, error) {
+	c, err := createAndInitializeClient(ctx, name, m, func(ctx context.Context) {
+		_, _ = reconnectMCP(ctx, name, m, toolCount)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	updateMCPState(name, MCPStateConnected, nil, c, state.ToolCount)
+	updateMCPState(name, MCPStateConnected, nil, c, toolCount)
 	mcpClients.Set(name, c)
 	return c, nil
 }
@@ -174,7 +209,7 @@ func (b *McpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 		return tools.ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 	permissionDescription := fmt.Sprintf("execute %s with the following parameters: %s", b.Info().Name, params.Input)
-	p := b.permissions.Request(
+	permErr := b.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			ToolCallID:  params.ID,
@@ -185,8 +220,8 @@ func (b *McpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 			Params:      params.Input,
 		},
 	)
-	if !p {
-		return tools.ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return tools.ToolResponse{}, permErr
 	}
 
 	return runTool(ctx, b.mcpName, b.tool.Name, params.Input)
@@ -199,6 +234,7 @@ func getTools(ctx context.Context, name string, permissions permission.Service,
 		updateMCPState(name, MCPStateError, err, nil, 0)
 		c.Close()
 		mcpClients.Del(name)
+		mcpTools.Del(name)
 		return nil
 	}
 	mcpTools := make([]tools.BaseTool, 0, len(result.Tools))
@@ -213,6 +249,17 @@ func getTools(ctx context.Context, name string, permissions permission.Service,
 	return mcpTools
 }
 
+// getMCPTools returns the tools of the connected MCP servers, ordered by
+// server.
+func getMCPTools() []tools.BaseTool {
+	var result []tools.BaseTool
+	for _, name := range slices.Sorted(maps.Keys(maps.Collect(mcpTools.Seq2()))) {
+		serverTools, _ := mcpTools.Get(name)
+		result = append(result, serverTools...)
+	}
+	return result
+}
+
 // SubscribeMCPEvents returns a channel for MCP events
 func SubscribeMCPEvents(ctx context.Context) <-chan pubsub.Event[MCPEvent] {
 	return mcpBroker.Subscribe(ctx)
@@ -252,6 +299,22 @@ func updateMCPState(name string, state MCPState, err error, client *client.Clien
 	})
 }
 
+// updateMCPAuthorizingState publishes that an MCP client waits for the user
+// to authorize it at authURL.
+func updateMCPAuthorizingState(name, authURL string) {
+	mcpStates.Set(name, MCPClientInfo{
+		Name:    name,
+		State:   MCPStateAuthorizing,
+		AuthURL: authURL,
+	})
+	mcpBroker.Publish(pubsub.UpdatedEvent, MCPEvent{
+		Type:    MCPEventStateChanged,
+		Name:    name,
+		State:   MCPStateAuthorizing,
+		AuthURL: authURL,
+	})
+}
+
 // CloseMCPClients closes all MCP clients. This should be called during application shutdown.
 func CloseMCPClients() {
 	for c := range mcpClients.Seq() {
@@ -270,9 +333,11 @@ var mcpInitRequest = mcp.InitializeRequest{
 	},
 }
 
-func doGetMCPTools(ctx context.Context, permissions permission.Service, cfg *config.Config) []tools.BaseTool {
+// doGetMCPTools connects to the configured MCP servers and loads their tools.
+// Servers that need the user to authorize them are authorized in the
+// background, and their tools are loaded once they connect.
+func doGetMCPTools(ctx context.Context, permissions permission.Service, cfg *config.Config) {
 	var wg sync.WaitGroup
-	result := csync.NewSlice[tools.BaseTool]()
 
 	// Initialize states for all configured MCPs
 	for name, m := range cfg.MCP {
@@ -304,51 +369,121 @@ func doGetMCPTools(ctx context.Context, permissions permission.Service, cfg *con
 				}
 			}()
 
-			ctx, cancel := context.WithTimeout(ctx, mcpTimeout(m))
-			defer cancel()
-			c, err := createAndInitializeClient(ctx, name, m)
-			if err != nil {
-				return
-			}
-			mcpClients.Set(name, c)
-
-			tools := getTools(ctx, name, permissions, c, cfg.WorkingDir())
-			updateMCPState(name, MCPStateConnected, nil, c, len(tools))
-			result.Append(tools...)
+			connectMCP(ctx, name, m, permissions, cfg.WorkingDir())
 		}(name, m)
 	}
 	wg.Wait()
-	return slices.Collect(result.Seq())
 }
 
-func createAndInitializeClient(ctx context.Context, name string, m config.MCPConfig) (*client.Client, error) {
-	c, err := createMcpClient(m)
+// connectMCP connects to an MCP server and loads its tools, prompts and
+// resources.
+func connectMCP(ctx context.Context, name string, m config.MCPConfig, permissions permission.Service, workingDir string) {
+	c, err := createAndInitializeClient(ctx, name, m, func(ctx context.Context) {
+		connectMCP(ctx, name, m, permissions, workingDir)
+	})
+	if err != nil {
+		return
+	}
+	mcpClients.Set(name, c)
+
+	ctx, cancel := context.WithTimeout(ctx, mcpTimeout(m))
+	defer cancel()
+
+	tools := getTools(ctx, name, permissions, c, workingDir)
+	getPrompts(ctx, name, c)
+	getResources(ctx, name, c)
+	updateMCPState(name, MCPStateConnected, nil, c, len(tools))
+	mcpTools.Set(name, tools)
+}
+
+// createAndInitializeClient starts the client of an MCP server. When the
+// server needs the user to authorize it, it returns errMCPAuthorizing and
+// calls connect once the server is authorized.
+func createAndInitializeClient(ctx context.Context, name string, m config.MCPConfig, connect func(context.Context)) (*client.Client, error) {
+	c, err := startMcpClient(ctx, name, m)
+	if client.IsOAuthAuthorizationRequiredError(err) && m.OAuth != nil {
+		// The server rejected the token, or there is none yet.
+		handler, store := client.GetOAuthHandler(err), mcpTokenStoreFor(name)
+		if err = refreshMCPToken(ctx, handler, store); err != nil {
+			authorizeMCPInBackground(ctx, name, m, handler, store, connect)
+			return nil, errMCPAuthorizing
+		}
+		c, err = startMcpClient(ctx, name, m)
+	}
 	if err != nil {
 		updateMCPState(name, MCPStateError, err, nil, 0)
+		return nil, err
+	}
+
+	slog.Info("Initialized mcp client", "name", name)
+	return c, nil
+}
+
+// authorizeMCPInBackground asks the user to authorize an MCP server without
+// blocking the caller, and calls connect once the server is authorized.
+func authorizeMCPInBackground(ctx context.Context, name string, m config.MCPConfig, handler *transport.OAuthHandler, store *mcpTokenStore, connect func(context.Context)) {
+	if state, _ := mcpStates.Get(name); state.State == MCPStateAuthorizing {
+		return
+	}
+	updateMCPAuthorizingState(name, "")
+	// The authorization outlives the tool call or startup that needed it.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		err := authorizeMCP(ctx, handler, store, m.OAuth.RedirectURI(), openMCPAuthorizationURL(name))
+		if err != nil {
+			updateMCPState(name, MCPStateError, err, nil, 0)
+			slog.Error("error authorizing mcp client", "error", err, "name", name)
+			return
+		}
+		connect(ctx)
+	}()
+}
+
+func startMcpClient(ctx context.Context, name string, m config.MCPConfig) (*client.Client, error) {
+	c, err := createMcpClient(name, m)
+	if err != nil {
 		slog.Error("error creating mcp client", "error", err, "name", name)
 		return nil, err
 	}
-	// Only call Start() for non-stdio clients, as stdio clients auto-start
+	// Only call Start() for non-stdio clients, as stdio clients auto-start.
 	if m.Type != config.MCPStdio {
-		if err := c.Start(ctx); err != nil {
-			updateMCPState(name, MCPStateError, err, nil, 0)
+		if err := startWithTimeout(ctx, c, mcpTimeout(m)); err != nil {
 			slog.Error("error starting mcp client", "error", err, "name", name)
 			_ = c.Close()
 			return nil, err
 		}
 	}
-	if _, err := c.Initialize(ctx, mcpInitRequest); err != nil {
-		updateMCPState(name, MCPStateError, err, nil, 0)
+	initCtx, cancel := context.WithTimeout(ctx, mcpTimeout(m))
+	defer cancel()
+	if _, err := c.Initialize(initCtx, mcpInitRequest); err != nil {
 		slog.Error("error initializing mcp client", "error", err, "name", name)
 		_ = c.Close()
 		return nil, err
 	}
-
-	slog.Info("Initialized mcp client", "name", name)
 	return c, nil
 }
 
-func createMcpClient(m config.MCPConfig) (*client.Client, error) {
+// startWithTimeout starts a client, giving up when it does not start within
+// timeout. SSE clients keep their stream open for as long as the context
+// given to Start lives, so the context is only canceled on timeout.
+func startWithTimeout(ctx context.Context, c *client.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+	err := c.Start(ctx)
+	if !timer.Stop() {
+		return fmt.Errorf("timed out starting mcp client after %s", timeout)
+	}
+	if err != nil {
+		cancel()
+	}
+	return err
+}
+
+func mcpTokenStoreFor(name string) *mcpTokenStore {
+	return newMCPTokenStore(config.Get().Options.DataDirectory, name)
+}
+
+func createMcpClient(name string, m config.MCPConfig) (*client.Client, error) {
 	switch m.Type {
 	case config.MCPStdio:
 		if strings.TrimSpace(m.Command) == "" {
@@ -364,20 +499,26 @@ func createMcpClient(m config.MCPConfig) (*client.Client, error) {
 		if strings.TrimSpace(m.URL) == "" {
 			return nil, fmt.Errorf("mcp http config requires a non-empty 'url' field")
 		}
-		return client.NewStreamableHttpClient(
-			m.URL,
+		opts := []transport.StreamableHTTPCOption{
 			transport.WithHTTPHeaders(m.ResolvedHeaders()),
 			transport.WithHTTPLogger(mcpLogger{}),
-		)
+		}
+		if m.OAuth != nil {
+			return client.NewOAuthStreamableHttpClient(m.URL, mcpOAuthConfig(*m.OAuth, mcpTokenStoreFor(name)), opts...)
+		}
+		return client.NewStreamableHttpClient(m.URL, opts...)
 	case config.MCPSse:
 		if strings.TrimSpace(m.URL) == "" {
 			return nil, fmt.Errorf("mcp sse config requires a non-empty 'url' field")
 		}
-		return client.NewSSEMCPClient(
-			m.URL,
+		opts := []transport.ClientOption{
 			client.WithHeaders(m.ResolvedHeaders()),
 			transport.WithSSELogger(mcpLogger{}),
-		)
+		}
+		if m.OAuth != nil {
+			return client.NewOAuthSSEClient(m.URL, mcpOAuthConfig(*m.OAuth, mcpTokenStoreFor(name)), opts...)
+		}
+		return client.NewSSEMCPClient(m.URL, opts...)
 	default:
 		return nil, fmt.Errorf("unsupported mcp type: %s", m.Type)
 	}
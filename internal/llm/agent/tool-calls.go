@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+// defaultMaxParallelTools is the number of read-only tool calls executed at
+// the same time when the limit is not configured.
+const defaultMaxParallelTools = 4
+
+type toolExecResult struct {
+	response tools.ToolResponse
+	err      error
+}
+
+// runToolCalls executes the tool calls of an assistant message and returns
+// their results in the original order.
+//
+// Consecutive calls to read-only tools run concurrently, bounded by limit.
+// Any other tool only starts once every previous call has finished and
+// blocks the following calls until it is done. If a permission request is
+// denied, or the context is cancelled, calls that did not run yet are marked
+// as cancelled. The returned bool reports whether a permission was denied.
+func runToolCalls(ctx context.Context, calls []message.ToolCall, lookup func(name string) tools.BaseTool, limit int) ([]message.ToolResult, bool) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]message.ToolResult, len(calls))
+	finished := make([]bool, len(calls))
+	sem := make(chan struct{}, limit)
+	var denied atomic.Bool
+
+	resolved := make([]tools.BaseTool, len(calls))
+	for i, call := range calls {
+		resolved[i] = lookup(call.Name)
+	}
+	readOnly := func(i int) bool {
+		return resolved[i] != nil && tools.IsReadOnly(resolved[i])
+	}
+
+	for start := 0; start < len(calls); {
+		if ctx.Err() != nil || denied.Load() {
+			break
+		}
+
+		// A batch is either a single mutating call or a run of read-only ones.
+		end := start + 1
+		if readOnly(start) {
+			for end < len(calls) && readOnly(end) {
+				end++
+			}
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				defer func() { <-sem }()
+				if ctx.Err() != nil || denied.Load() {
+					return
+				}
+
+				call := calls[i]
+				tool := resolved[i]
+				if tool == nil {
+					results[i] = message.ToolResult{
+						ToolCallID: call.ID,
+						Content:    fmt.Sprintf("Tool not found: %s", call.Name),
+						IsError:    true,
+					}
+					finished[i] = true
+					return
+				}
+
+				response, err := executeToolCall(ctx, tool, call)
+				if err != nil {
+					if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+						return
+					}
+					slog.Error("Tool execution error", "toolCall", call.ID, "error", err)
+					// Policy denials are reported to the model, which can try
+					// something else, instead of ending the turn.
+					var policyErr *permission.PolicyDeniedError
+					if errors.As(err, &policyErr) {
+						results[i] = message.ToolResult{
+							ToolCallID: call.ID,
+							Content:    fmt.Sprintf("Error: %s. Do not retry this call; try a different approach or ask the user.", policyErr),
+							IsError:    true,
+						}
+						finished[i] = true
+						return
+					}
+					if errors.Is(err, permission.ErrorPermissionDenied) {
+						denied.Store(true)
+						results[i] = message.ToolResult{
+							ToolCallID: call.ID,
+							Content:    "Permission denied",
+							IsError:    true,
+						}
+						finished[i] = true
+						return
+					}
+				}
+				results[i] = message.ToolResult{
+					ToolCallID: call.ID,
+					Content:    response.Content,
+					Metadata:   response.Metadata,
+					IsError:    response.IsError,
+				}
+				if response.Type == tools.ToolResponseTypeImage {
+					results[i].Data = response.Data
+					results[i].MIMEType = response.MIMEType
+				}
+				finished[i] = true
+			}()
+		}
+		wg.Wait()
+		start = end
+	}
+
+	for i, call := range calls {
+		if finished[i] {
+			continue
+		}
+		results[i] = message.ToolResult{
+			ToolCallID: call.ID,
+			Content:    "Tool execution canceled by user",
+			IsError:    true,
+		}
+	}
+	return results, denied.Load()
+}
+
+// executeToolCall runs a single tool, returning early if the context is
+// cancelled before the tool finishes.
+func executeToolCall(ctx context.Context, tool tools.BaseTool, call message.ToolCall) (tools.ToolResponse, error) {
+	resultChan := make(chan toolExecResult, 1)
+	go func() {
+		response, err := tool.Run(ctx, tools.ToolCall{
+			ID:    call.ID,
+			Name:  call.Name,
+			Input: call.Input,
+		})
+		resultChan <- toolExecResult{response: response, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return tools.ToolResponse{}, ctx.Err()
+	case result := <-resultChan:
+		return result.response, result.err
+	}
+}
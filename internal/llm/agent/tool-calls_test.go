@@ -0,0 +1,210 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTool struct {
+	name     string
+	readOnly bool
+	run      func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error)
+}
+
+func (f *fakeTool) Name() string   { return f.name }
+func (f *fakeTool) ReadOnly() bool { return f.readOnly }
+
+func (f *fakeTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{Name: f.name}
+}
+
+func (f *fakeTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	return f.run(ctx, call)
+}
+
+func lookupIn(available ...tools.BaseTool) func(string) tools.BaseTool {
+	return func(name string) tools.BaseTool {
+		for _, t := range available {
+			if t.Name() == name {
+				return t
+			}
+		}
+		return nil
+	}
+}
+
+func echo(_ context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	return tools.NewTextResponse(call.Input), nil
+}
+
+func TestRunToolCalls_PreservesOrder(t *testing.T) {
+	t.Parallel()
+
+	slow := &fakeTool{name: "slow", readOnly: true, run: func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		time.Sleep(50 * time.Millisecond)
+		return echo(ctx, call)
+	}}
+	fast := &fakeTool{name: "fast", readOnly: true, run: echo}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "slow", Input: "a"},
+		{ID: "2", Name: "fast", Input: "b"},
+		{ID: "3", Name: "missing", Input: "c"},
+		{ID: "4", Name: "fast", Input: "d"},
+	}
+	results, denied := runToolCalls(t.Context(), calls, lookupIn(slow, fast), 4)
+	require.False(t, denied)
+	require.Len(t, results, 4)
+	for i, r := range results {
+		require.Equal(t, calls[i].ID, r.ToolCallID)
+	}
+	require.Equal(t, "a", results[0].Content)
+	require.Equal(t, "b", results[1].Content)
+	require.True(t, results[2].IsError)
+	require.Equal(t, "d", results[3].Content)
+}
+
+func TestRunToolCalls_ReadOnlyRunConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	barrier := &fakeTool{name: "view", readOnly: true, run: func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		// Every call waits for the others, so this only completes when all
+		// of them are in flight at the same time.
+		wg.Done()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return echo(ctx, call)
+		case <-time.After(2 * time.Second):
+			return tools.NewTextErrorResponse("not concurrent"), nil
+		}
+	}}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "view", Input: "a"},
+		{ID: "2", Name: "view", Input: "b"},
+		{ID: "3", Name: "view", Input: "c"},
+	}
+	results, _ := runToolCalls(t.Context(), calls, lookupIn(barrier), 3)
+	for _, r := range results {
+		require.False(t, r.IsError, r.Content)
+	}
+}
+
+func TestRunToolCalls_RespectsLimitAndSerializesWrites(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	track := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return echo(ctx, call)
+	}
+	read := &fakeTool{name: "grep", readOnly: true, run: track}
+	write := &fakeTool{name: "edit", run: track}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "grep"},
+		{ID: "2", Name: "grep"},
+		{ID: "3", Name: "grep"},
+		{ID: "4", Name: "grep"},
+	}
+	_, _ = runToolCalls(t.Context(), calls, lookupIn(read), 2)
+	require.Equal(t, int32(2), peak.Load())
+
+	peak.Store(0)
+	calls = []message.ToolCall{
+		{ID: "1", Name: "edit"},
+		{ID: "2", Name: "edit"},
+		{ID: "3", Name: "edit"},
+	}
+	_, _ = runToolCalls(t.Context(), calls, lookupIn(write), 4)
+	require.Equal(t, int32(1), peak.Load())
+}
+
+func TestRunToolCalls_PermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	var ran atomic.Int32
+	deny := &fakeTool{name: "bash", run: func(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+		return tools.ToolResponse{}, permission.ErrorPermissionDenied
+	}}
+	view := &fakeTool{name: "view", readOnly: true, run: func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		ran.Add(1)
+		return echo(ctx, call)
+	}}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "view", Input: "a"},
+		{ID: "2", Name: "bash"},
+		{ID: "3", Name: "view", Input: "b"},
+	}
+	results, denied := runToolCalls(t.Context(), calls, lookupIn(deny, view), 4)
+	require.True(t, denied)
+	require.Equal(t, int32(1), ran.Load())
+	require.Equal(t, "a", results[0].Content)
+	require.Equal(t, "Permission denied", results[1].Content)
+	require.Equal(t, "Tool execution canceled by user", results[2].Content)
+}
+
+func TestRunToolCalls_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	block := &fakeTool{name: "bash", run: func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		cancel()
+		<-ctx.Done()
+		return tools.ToolResponse{}, ctx.Err()
+	}}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "bash"},
+		{ID: "2", Name: "bash"},
+	}
+	results, denied := runToolCalls(ctx, calls, lookupIn(block), 4)
+	require.False(t, denied)
+	for _, r := range results {
+		require.True(t, r.IsError)
+		require.Equal(t, "Tool execution canceled by user", r.Content)
+	}
+}
+
+func TestRunToolCalls_PolicyDenied(t *testing.T) {
+	t.Parallel()
+
+	deny := &fakeTool{name: "edit", run: func(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+		return tools.ToolResponse{}, &permission.PolicyDeniedError{Rule: `"no-vendor"`}
+	}}
+	view := &fakeTool{name: "view", readOnly: true, run: echo}
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: "edit"},
+		{ID: "2", Name: "view", Input: "b"},
+	}
+	results, denied := runToolCalls(t.Context(), calls, lookupIn(deny, view), 4)
+	require.False(t, denied)
+	require.True(t, results[0].IsError)
+	require.Contains(t, results[0].Content, `policy rule "no-vendor"`)
+	require.Equal(t, "b", results[1].Content)
+}
@@ -92,6 +92,9 @@ Tools that support it will also include useful diagnostics such as linting and t
 - These diagnostics will be automatically enabled when you run the tool, and will be displayed in the output at the bottom within the <file_diagnostics></file_diagnostics> and <project_diagnostics></project_diagnostics> tags.
 - Take necessary actions to fix the issues.
 - You should ignore diagnostics of files that you did not change or are not related or caused by your changes unless the user explicitly asks you to fix them.
+- Prefer the definition, references, hover and symbols tools over grep to navigate code in files handled by a language server.
+- Use the call_graph tool to find the callers of a function before changing its behavior or signature.
+- Prefer the rename tool over editing every reference by hand, and the code_action tool to apply fixes the language server offers for diagnostics.
 `
 }
 
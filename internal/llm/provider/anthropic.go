@@ -125,6 +125,10 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			var contentBlocks []anthropic.ContentBlockParamUnion
 			contentBlocks = append(contentBlocks, content)
 			for _, binaryContent := range msg.BinaryContent() {
+				if !binaryContent.IsImage() {
+					contentBlocks = append(contentBlocks, anthropic.NewTextBlock(binaryContent.Text()))
+					continue
+				}
 				base64Image := binaryContent.String(catwalk.InferenceProviderAnthropic)
 				imageBlock := anthropic.NewImageBlockBase64(binaryContent.MIMEType, base64Image)
 				contentBlocks = append(contentBlocks, imageBlock)
@@ -171,6 +175,12 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			results := make([]anthropic.ContentBlockParamUnion, len(msg.ToolResults()))
 			for i, toolResult := range msg.ToolResults() {
 				results[i] = anthropic.NewToolResultBlock(toolResult.ToolCallID, toolResult.Content, toolResult.IsError)
+				if image, ok := toolResult.Image(); ok {
+					imageBlock := anthropic.NewImageBlockBase64(image.MIMEType, image.String(catwalk.InferenceProviderAnthropic))
+					results[i].OfToolResult.Content = append(results[i].OfToolResult.Content, anthropic.ToolResultBlockParamContentUnion{
+						OfImage: imageBlock.OfImage,
+					})
+				}
 			}
 			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(results...))
 		}
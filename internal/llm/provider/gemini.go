@@ -62,6 +62,10 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 			var parts []*genai.Part
 			parts = append(parts, &genai.Part{Text: msg.Content().String()})
 			for _, binaryContent := range msg.BinaryContent() {
+				if !binaryContent.IsImage() {
+					parts = append(parts, &genai.Part{Text: binaryContent.Text()})
+					continue
+				}
 				imageFormat := strings.Split(binaryContent.MIMEType, "/")
 				parts = append(parts, &genai.Part{InlineData: &genai.Blob{
 					MIMEType: imageFormat[1],
@@ -132,6 +136,18 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 					},
 					Role: genai.RoleModel,
 				})
+
+				// Function responses only hold JSON, so images returned by
+				// tools are sent in a user message after them.
+				if image, ok := result.Image(); ok {
+					history = append(history, &genai.Content{
+						Parts: []*genai.Part{
+							{Text: fmt.Sprintf("Image returned by the %s tool:", toolCall.Name)},
+							{InlineData: &genai.Blob{MIMEType: image.MIMEType, Data: image.Data}},
+						},
+						Role: genai.RoleUser,
+					})
+				}
 			}
 		}
 	}
@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/google/uuid"
+)
+
+const (
+	promptToolCallOpen  = "<tool_call>"
+	promptToolCallClose = "</tool_call>"
+)
+
+var promptToolCallRe = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*(?:</tool_call>|$)`)
+
+// localClient talks to the OpenAI compatible API of a local Ollama or
+// llama.cpp server. Models without native tool calling are given the tools in
+// the system prompt and call them in <tool_call> blocks of their response.
+type localClient struct {
+	*openaiClient
+}
+
+type LocalClient ProviderClient
+
+func newLocalClient(opts providerClientOptions) LocalClient {
+	if resolved, err := config.Get().Resolve(opts.baseURL); err == nil && resolved != "" {
+		opts.baseURL = config.LocalServerURL(resolved) + "/v1"
+	}
+	return &localClient{
+		openaiClient: &openaiClient{
+			providerOptions: opts,
+			client:          createOpenAIClient(opts),
+		},
+	}
+}
+
+// usesPromptTools returns whether the tools are given to the model in the
+// system prompt.
+func (l *localClient) usesPromptTools(tools []tools.BaseTool) bool {
+	return len(tools) > 0 && slices.Contains(l.providerOptions.config.PromptToolModels, l.Model().ID)
+}
+
+// promptToolClient returns a client whose system prompt describes the tools.
+func (l *localClient) promptToolClient(tools []tools.BaseTool) *openaiClient {
+	opts := l.providerOptions
+	opts.systemMessage += "\n\n" + promptToolInstructions(tools)
+	return &openaiClient{
+		providerOptions: opts,
+		client:          l.client,
+	}
+}
+
+func (l *localClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	if !l.usesPromptTools(tools) {
+		return l.openaiClient.send(ctx, messages, tools)
+	}
+	response, err := l.promptToolClient(tools).send(ctx, promptToolMessages(messages), nil)
+	if err != nil {
+		return nil, err
+	}
+	promptToolResponse(response)
+	return response, nil
+}
+
+func (l *localClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	if !l.usesPromptTools(tools) {
+		return l.openaiClient.stream(ctx, messages, tools)
+	}
+	events := l.promptToolClient(tools).stream(ctx, promptToolMessages(messages), nil)
+
+	eventChan := make(chan ProviderEvent)
+	go func() {
+		defer close(eventChan)
+		// The content is forwarded up to the first tool call, holding back
+		// what could be the start of one and the whitespace before it.
+		var content strings.Builder
+		sent := 0
+		for event := range events {
+			switch event.Type {
+			case EventContentDelta:
+				content.WriteString(event.Content)
+				text := content.String()
+				end := strings.Index(text, promptToolCallOpen)
+				if end < 0 {
+					end = len(text) - partialSuffixLen(text, promptToolCallOpen)
+				}
+				// Trailing whitespace is dropped before tool calls.
+				end = len(strings.TrimRight(text[:end], " \t\n"))
+				if end > sent {
+					eventChan <- ProviderEvent{Type: EventContentDelta, Content: text[sent:end]}
+					sent = end
+				}
+				continue
+			case EventComplete:
+				promptToolResponse(event.Response)
+				if len(event.Response.Content) > sent {
+					eventChan <- ProviderEvent{Type: EventContentDelta, Content: event.Response.Content[sent:]}
+				}
+			}
+			eventChan <- event
+			// The stream is not always closed after an error.
+			if event.Type == EventComplete || event.Type == EventError {
+				return
+			}
+		}
+	}()
+	return eventChan
+}
+
+// promptToolInstructions describes the tools and how to call them.
+func promptToolInstructions(tools []tools.BaseTool) string {
+	var sb strings.Builder
+	sb.WriteString("# Tools\n\nYou can call the following tools, described by their JSON schema:\n\n<tools>\n")
+	for _, tool := range tools {
+		info := tool.Info()
+		schema, _ := json.Marshal(map[string]any{
+			"name":        info.Name,
+			"description": info.Description,
+			"parameters": map[string]any{
+				"type":       "object",
+				"properties": info.Parameters,
+				"required":   info.Required,
+			},
+		})
+		sb.Write(schema)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</tools>\n\n")
+	sb.WriteString("To call a tool, write a JSON object with its name and arguments in a <tool_call> block:\n\n")
+	sb.WriteString(`<tool_call>{"name": "tool_name", "arguments": {"param": "value"}}</tool_call>`)
+	sb.WriteString("\n\nYou can call several tools, with one block per call. Stop writing after your tool calls; ")
+	sb.WriteString("the results are sent back in <tool_result> blocks.")
+	return sb.String()
+}
+
+// promptToolMessages rewrites the tool calls and tool results of the
+// messages as the text blocks of the prompt tool protocol.
+func promptToolMessages(messages []message.Message) []message.Message {
+	rewritten := make([]message.Message, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case message.Assistant:
+			if len(msg.ToolCalls()) == 0 {
+				break
+			}
+			var sb strings.Builder
+			sb.WriteString(msg.Content().Text)
+			for _, call := range msg.ToolCalls() {
+				if !call.Finished {
+					continue
+				}
+				input := call.Input
+				if !json.Valid([]byte(input)) {
+					input = "{}"
+				}
+				if sb.Len() > 0 {
+					sb.WriteString("\n")
+				}
+				fmt.Fprintf(&sb, `%s{"name": %q, "arguments": %s}%s`, promptToolCallOpen, call.Name, input, promptToolCallClose)
+			}
+			msg = message.Message{
+				ID:    msg.ID,
+				Role:  message.Assistant,
+				Parts: []message.ContentPart{message.TextContent{Text: sb.String()}},
+			}
+		case message.Tool:
+			var sb strings.Builder
+			parts := []message.ContentPart{}
+			for _, result := range msg.ToolResults() {
+				if sb.Len() > 0 {
+					sb.WriteString("\n")
+				}
+				status := ""
+				if result.IsError {
+					status = ` error="true"`
+				}
+				fmt.Fprintf(&sb, "<tool_result name=%q id=%q%s>\n%s\n</tool_result>", result.Name, result.ToolCallID, status, result.Content)
+				if image, ok := result.Image(); ok {
+					parts = append(parts, image)
+				}
+			}
+			msg = message.Message{
+				ID:    msg.ID,
+				Role:  message.User,
+				Parts: append([]message.ContentPart{message.TextContent{Text: sb.String()}}, parts...),
+			}
+		}
+		rewritten = append(rewritten, msg)
+	}
+	return rewritten
+}
+
+// promptToolResponse moves the tool calls in the content of a response to its
+// tool calls.
+func promptToolResponse(response *ProviderResponse) {
+	content, toolCalls := parsePromptToolCalls(response.Content)
+	response.Content = content
+	response.ToolCalls = toolCalls
+	if len(toolCalls) > 0 {
+		response.FinishReason = message.FinishReasonToolUse
+	}
+}
+
+// parsePromptToolCalls splits the content of a response into the text before
+// the first tool call and the tool calls. The content is returned unchanged
+// when no tool call parses.
+func parsePromptToolCalls(content string) (string, []message.ToolCall) {
+	start := strings.Index(content, promptToolCallOpen)
+	if start < 0 {
+		return content, nil
+	}
+
+	var toolCalls []message.ToolCall
+	for _, match := range promptToolCallRe.FindAllStringSubmatch(content[start:], -1) {
+		var call struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal([]byte(match[1]), &call); err != nil || call.Name == "" {
+			continue
+		}
+		input := string(call.Arguments)
+		// Some models send the arguments as a JSON string.
+		var encoded string
+		if err := json.Unmarshal(call.Arguments, &encoded); err == nil {
+			input = encoded
+		}
+		if input == "" || input == "null" {
+			input = "{}"
+		}
+		toolCalls = append(toolCalls, message.ToolCall{
+			ID:       uuid.NewString(),
+			Name:     call.Name,
+			Input:    input,
+			Type:     "function",
+			Finished: true,
+		})
+	}
+	if len(toolCalls) == 0 {
+		return content, nil
+	}
+	return strings.TrimRight(content[:start], " \t\n"), toolCalls
+}
+
+// partialSuffixLen returns the length of the longest suffix of s that is a
+// prefix of tag.
+func partialSuffixLen(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
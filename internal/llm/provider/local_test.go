@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTool struct{}
+
+func (stubTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{
+		Name:        "ls",
+		Description: "List files",
+		Parameters:  map[string]any{"path": map[string]any{"type": "string"}},
+		Required:    []string{"path"},
+	}
+}
+
+func (stubTool) Name() string { return "ls" }
+
+func (stubTool) Run(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+	return tools.NewTextResponse(""), nil
+}
+
+func TestParsePromptToolCalls(t *testing.T) {
+	t.Parallel()
+
+	text, calls := parsePromptToolCalls("No tools needed.")
+	require.Equal(t, "No tools needed.", text)
+	require.Empty(t, calls)
+
+	text, calls = parsePromptToolCalls("Wrap calls in <tool_call>name</tool_call> tags.\nLike <tool_call>this")
+	require.Equal(t, "Wrap calls in <tool_call>name</tool_call> tags.\nLike <tool_call>this", text)
+	require.Empty(t, calls)
+
+	text, calls = parsePromptToolCalls("Let me look.\n" +
+		`<tool_call>{"name": "ls", "arguments": {"path": "."}}</tool_call>` + "\n" +
+		`<tool_call>{"name": "view", "arguments": "{\"file_path\": \"go.mod\"}"}</tool_call>` +
+		`<tool_call>not json</tool_call>` +
+		`<tool_call>{"name": "glob"}`)
+	require.Equal(t, "Let me look.", text)
+	require.Len(t, calls, 3)
+	require.Equal(t, "ls", calls[0].Name)
+	require.JSONEq(t, `{"path": "."}`, calls[0].Input)
+	require.Equal(t, "view", calls[1].Name)
+	require.JSONEq(t, `{"file_path": "go.mod"}`, calls[1].Input)
+	require.Equal(t, "glob", calls[2].Name)
+	require.Equal(t, "{}", calls[2].Input)
+	require.NotEqual(t, calls[0].ID, calls[1].ID)
+}
+
+func TestPromptToolMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := promptToolMessages([]message.Message{
+		{
+			Role: message.Assistant,
+			Parts: []message.ContentPart{
+				message.TextContent{Text: "Let me look."},
+				message.ToolCall{ID: "1", Name: "ls", Input: `{"path":"."}`, Finished: true},
+			},
+		},
+		{
+			Role: message.Tool,
+			Parts: []message.ContentPart{
+				message.ToolResult{ToolCallID: "1", Name: "ls", Content: "go.mod"},
+			},
+		},
+	})
+	require.Len(t, messages, 2)
+	require.Equal(t, message.Assistant, messages[0].Role)
+	require.Empty(t, messages[0].ToolCalls())
+	require.Equal(t, "Let me look.\n"+`<tool_call>{"name": "ls", "arguments": {"path":"."}}</tool_call>`, messages[0].Content().Text)
+	require.Equal(t, message.User, messages[1].Role)
+	require.Equal(t, "<tool_result name=\"ls\" id=\"1\">\ngo.mod\n</tool_result>", messages[1].Content().Text)
+}
+
+func TestLocalClientStreamPromptTools(t *testing.T) {
+	t.Parallel()
+
+	var request struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+		Tools []any `json:"tools"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"Let me look.", "\n<tool", `_call>{"name": "ls", `, `"arguments": {"path": "."}}</tool_call>`} {
+			chunk, _ := json.Marshal(map[string]any{
+				"id":      "chat-completion-test",
+				"object":  "chat.completion.chunk",
+				"model":   "gemma3:4b",
+				"choices": []any{map[string]any{"index": 0, "delta": map[string]any{"content": delta}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := &localClient{
+		openaiClient: &openaiClient{
+			providerOptions: providerClientOptions{
+				config:        config.ProviderConfig{PromptToolModels: []string{"gemma3:4b"}},
+				modelType:     config.SelectedModelTypeLarge,
+				systemMessage: "You are a coding agent.",
+				model: func(config.SelectedModelType) catwalk.Model {
+					return catwalk.Model{ID: "gemma3:4b"}
+				},
+			},
+			client: openai.NewClient(option.WithBaseURL(server.URL)),
+		},
+	}
+
+	messages := []message.Message{{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "What is here?"}},
+	}}
+	var content strings.Builder
+	var response *ProviderResponse
+	for event := range client.stream(t.Context(), messages, []tools.BaseTool{stubTool{}}) {
+		switch event.Type {
+		case EventContentDelta:
+			content.WriteString(event.Content)
+		case EventComplete:
+			response = event.Response
+		case EventError:
+			require.NoError(t, event.Error)
+		}
+	}
+
+	require.Empty(t, request.Tools)
+	require.Contains(t, request.Messages[0].Content, "You are a coding agent.")
+	require.Contains(t, request.Messages[0].Content, `"name":"ls"`)
+
+	require.Equal(t, "Let me look.", content.String())
+	require.NotNil(t, response)
+	require.Equal(t, "Let me look.", response.Content)
+	require.Equal(t, message.FinishReasonToolUse, response.FinishReason)
+	require.Len(t, response.ToolCalls, 1)
+	require.Equal(t, "ls", response.ToolCalls[0].Name)
+	require.JSONEq(t, `{"path": "."}`, response.ToolCalls[0].Input)
+}
+
+func TestPartialSuffixLen(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, partialSuffixLen("hello", "<tool_call>"))
+	require.Equal(t, 1, partialSuffixLen("hello <", "<tool_call>"))
+	require.Equal(t, 5, partialSuffixLen("hello <tool", "<tool_call>"))
+}
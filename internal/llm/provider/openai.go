@@ -103,6 +103,11 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 			hasBinaryContent := false
 			for _, binaryContent := range msg.BinaryContent() {
 				hasBinaryContent = true
+				if !binaryContent.IsImage() {
+					attachmentBlock := openai.ChatCompletionContentPartTextParam{Text: binaryContent.Text()}
+					content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &attachmentBlock})
+					continue
+				}
 				imageURL := openai.ChatCompletionContentPartImageImageURLParam{URL: binaryContent.String(catwalk.InferenceProviderOpenAI)}
 				imageBlock := openai.ChatCompletionContentPartImageParam{ImageURL: imageURL}
 
@@ -171,10 +176,24 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 			})
 
 		case message.Tool:
+			var images []openai.ChatCompletionContentPartUnionParam
 			for _, result := range msg.ToolResults() {
 				openaiMessages = append(openaiMessages,
 					openai.ToolMessage(result.Content, result.ToolCallID),
 				)
+				if image, ok := result.Image(); ok {
+					imageURL := openai.ChatCompletionContentPartImageImageURLParam{URL: image.String(catwalk.InferenceProviderOpenAI)}
+					images = append(images, openai.ChatCompletionContentPartUnionParam{
+						OfImageURL: &openai.ChatCompletionContentPartImageParam{ImageURL: imageURL},
+					})
+				}
+			}
+			// Tool messages only hold text, so images returned by tools are
+			// sent in a user message after them.
+			if len(images) > 0 {
+				textBlock := openai.ChatCompletionContentPartTextParam{Text: "Images returned by the tool calls above:"}
+				content := append([]openai.ChatCompletionContentPartUnionParam{{OfText: &textBlock}}, images...)
+				openaiMessages = append(openaiMessages, openai.UserMessage(content))
 			}
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -12,13 +13,15 @@ import (
 )
 
 type BashParams struct {
-	Command string `json:"command"`
-	Timeout int    `json:"timeout"`
+	Command         string `json:"command"`
+	Timeout         int    `json:"timeout"`
+	RunInBackground bool   `json:"run_in_background"`
 }
 
 type BashPermissionsParams struct {
-	Command string `json:"command"`
-	Timeout int    `json:"timeout"`
+	Command         string `json:"command"`
+	Timeout         int    `json:"timeout"`
+	RunInBackground bool   `json:"run_in_background"`
 }
 
 type BashResponseMetadata struct {
@@ -26,6 +29,7 @@ type BashResponseMetadata struct {
 	EndTime          int64  `json:"end_time"`
 	Output           string `json:"output"`
 	WorkingDirectory string `json:"working_directory"`
+	BackgroundID     string `json:"background_id,omitempty"`
 }
 type bashTool struct {
 	permissions permission.Service
@@ -152,6 +156,7 @@ Before executing the command, please follow these steps:
 Usage notes:
 - The command argument is required.
 - You can specify an optional timeout in milliseconds (up to 600000ms / 10 minutes). If not specified, commands will timeout after 30 minutes.
+- To start a dev server, a file watcher or any other command that does not exit on its own, set run_in_background to true. The tool returns the ID of the background process right away; read its output with the %s tool and stop it with the %s tool once you are done. Background commands do not change the working directory or the environment of the shell, and have no timeout.
 - VERY IMPORTANT: You MUST avoid using search commands like 'find' and 'grep'. Instead use Grep, Glob, or Agent tools to search. You MUST avoid read tools like 'cat', 'head', 'tail', and 'ls', and use FileRead and LS tools to read files.
 - When issuing multiple commands, use the ';' or '&&' operator to separate them. DO NOT use newlines (newlines are ok in quoted strings).
 - IMPORTANT: All commands share the same shell session. Shell state (environment variables, virtual environments, current directory, etc.) persist between commands. For example, if you set an environment variable as part of a command, the environment variable will persist for subsequent commands.
@@ -269,7 +274,7 @@ EOF
 
 Important:
 - Return an empty response - the user will see the gh output directly
-- Never update git config`, bannedCommandsStr, MaxOutputLength)
+- Never update git config`, bannedCommandsStr, MaxOutputLength, BashOutputToolName, BashKillToolName)
 }
 
 func blockFuncs() []shell.BlockFunc {
@@ -304,10 +309,12 @@ func blockFuncs() []shell.BlockFunc {
 	}
 }
 
-func NewBashTool(permission permission.Service, workingDir string) BaseTool {
-	// Set up command blocking on the persistent shell
-	persistentShell := shell.GetPersistentShell(workingDir)
-	persistentShell.SetBlockFuncs(blockFuncs())
+func NewBashTool(permission permission.Service, workingDir string, sandbox *shell.Sandbox) BaseTool {
+	// Set up command blocking on the session shells
+	shell.ConfigureSessionShells(workingDir, blockFuncs(), sandbox)
+	if sandbox != nil && !shell.SandboxAvailable() {
+		slog.Warn("The bash sandbox is not supported on this system, commands run without it")
+	}
 
 	return &bashTool{
 		permissions: permission,
@@ -332,6 +339,10 @@ func (b *bashTool) Info() ToolInfo {
 				"type":        "number",
 				"description": "Optional timeout in milliseconds (max 600000)",
 			},
+			"run_in_background": map[string]any{
+				"type":        "boolean",
+				"description": "Run the command in the background and return its process ID right away",
+			},
 		},
 		Required: []string{"command"},
 	}
@@ -369,25 +380,30 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if sessionID == "" || messageID == "" {
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for executing shell command")
 	}
+	persistentShell := shell.GetSessionShell(sessionID)
 	if !isSafeReadOnly {
-		shell := shell.GetPersistentShell(b.workingDir)
-		p := b.permissions.Request(
+		permErr := b.permissions.Authorize(
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
-				Path:        shell.GetWorkingDir(),
+				Path:        persistentShell.GetWorkingDir(),
 				ToolCallID:  call.ID,
 				ToolName:    BashToolName,
 				Action:      "execute",
 				Description: fmt.Sprintf("Execute command: %s", params.Command),
 				Params: BashPermissionsParams{
-					Command: params.Command,
+					Command:         params.Command,
+					RunInBackground: params.RunInBackground,
 				},
 			},
 		)
-		if !p {
-			return ToolResponse{}, permission.ErrorPermissionDenied
+		if permErr != nil {
+			return ToolResponse{}, permErr
 		}
 	}
+	if params.RunInBackground {
+		return b.runInBackground(persistentShell, params.Command)
+	}
+
 	startTime := time.Now()
 	if params.Timeout > 0 {
 		var cancel context.CancelFunc
@@ -395,7 +411,6 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		defer cancel()
 	}
 
-	persistentShell := shell.GetPersistentShell(b.workingDir)
 	stdout, stderr, err := persistentShell.Exec(ctx, params.Command)
 
 	// Get the current working directory after command execution
@@ -424,6 +439,9 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 			errorMessage += "\n"
 		}
 		errorMessage += fmt.Sprintf("Exit code %d", exitCode)
+		if note := persistentShell.ExplainFailure(errorMessage); note != "" {
+			errorMessage += "\n" + note
+		}
 	}
 
 	hasBothOutputs := stdout != "" && stderr != ""
@@ -449,6 +467,25 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	return WithResponseMetadata(NewTextResponse(stdout), metadata), nil
 }
 
+// runInBackground starts a command in the background and returns its ID.
+func (b *bashTool) runInBackground(persistentShell *shell.PersistentShell, command string) (ToolResponse, error) {
+	job, err := persistentShell.ExecBackground(command)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	metadata := BashResponseMetadata{
+		StartTime:        job.StartedAt.UnixMilli(),
+		EndTime:          job.StartedAt.UnixMilli(),
+		WorkingDirectory: job.WorkingDir,
+		BackgroundID:     job.ID,
+	}
+	output := fmt.Sprintf(
+		"Started background process %s.\nRead its output with the %s tool and stop it with the %s tool.",
+		job.ID, BashOutputToolName, BashKillToolName,
+	)
+	return WithResponseMetadata(NewTextResponse(output), metadata), nil
+}
+
 func truncateOutput(content string) string {
 	if len(content) <= MaxOutputLength {
 		return content
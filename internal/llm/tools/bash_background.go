@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+type BashOutputParams struct {
+	ID string `json:"id"`
+}
+
+type BashKillParams struct {
+	ID string `json:"id"`
+}
+
+type BashBackgroundResponseMetadata struct {
+	ID       string `json:"id"`
+	Command  string `json:"command"`
+	State    string `json:"state"`
+	ExitCode int    `json:"exit_code"`
+}
+
+type bashOutputTool struct{}
+
+type bashKillTool struct{}
+
+const (
+	BashOutputToolName    = "bash_output"
+	bashOutputDescription = `Reads the output of a command started in the background with the bash tool.
+WHEN TO USE THIS TOOL:
+- Use to check on a dev server, file watcher or long build started with run_in_background
+- Use to find out whether a background command is still running, and its exit code once it is done
+HOW TO USE:
+- Provide the ID of the background process returned by the bash tool
+- Each call returns the output written since the previous call, so call it again to follow the output
+LIMITATIONS:
+- Output not read for a long time may be truncated, keeping the most recent part
+- Only the 50 most recently ended background commands are kept, read the output of a finished command before starting many more
+`
+
+	BashKillToolName    = "bash_kill"
+	bashKillDescription = `Stops a command started in the background with the bash tool.
+WHEN TO USE THIS TOOL:
+- Use once you are done with a dev server, file watcher or other background command
+- Use to stop a background command that hangs or is no longer needed
+HOW TO USE:
+- Provide the ID of the background process returned by the bash tool
+- The command is interrupted, and killed if it does not stop in time
+`
+)
+
+func NewBashOutputTool() BaseTool {
+	return &bashOutputTool{}
+}
+
+func (b *bashOutputTool) Name() string {
+	return BashOutputToolName
+}
+
+// ReadOnly is false as reading the output of a job consumes it.
+func (b *bashOutputTool) ReadOnly() bool {
+	return false
+}
+
+func (b *bashOutputTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        BashOutputToolName,
+		Description: bashOutputDescription,
+		Parameters: map[string]any{
+			"id": map[string]any{
+				"type":        "string",
+				"description": "The ID of the background process",
+			},
+		},
+		Required: []string{"id"},
+	}
+}
+
+func (b *bashOutputTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params BashOutputParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	job, stdout, stderr, err := shell.BackgroundOutput(params.ID)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return WithResponseMetadata(
+		NewTextResponse(formatBackgroundJob(job, truncateOutput(stdout), truncateOutput(stderr))),
+		backgroundJobMetadata(job),
+	), nil
+}
+
+func NewBashKillTool() BaseTool {
+	return &bashKillTool{}
+}
+
+func (b *bashKillTool) Name() string {
+	return BashKillToolName
+}
+
+func (b *bashKillTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        BashKillToolName,
+		Description: bashKillDescription,
+		Parameters: map[string]any{
+			"id": map[string]any{
+				"type":        "string",
+				"description": "The ID of the background process to stop",
+			},
+		},
+		Required: []string{"id"},
+	}
+}
+
+func (b *bashKillTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params BashKillParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	job, err := shell.KillBackground(params.ID)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return WithResponseMetadata(
+		NewTextResponse(formatBackgroundJob(job, "", "")),
+		backgroundJobMetadata(job),
+	), nil
+}
+
+// formatBackgroundJob describes the state of a background job and its new
+// output.
+func formatBackgroundJob(job shell.BackgroundJob, stdout, stderr string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<status>%s</status>\n", job.State)
+	if job.State != shell.BackgroundRunning {
+		fmt.Fprintf(&sb, "<exit_code>%d</exit_code>\n", job.ExitCode)
+	}
+	if stdout != "" {
+		fmt.Fprintf(&sb, "<stdout>\n%s\n</stdout>\n", strings.TrimRight(stdout, "\n"))
+	}
+	if stderr != "" {
+		fmt.Fprintf(&sb, "<stderr>\n%s\n</stderr>\n", strings.TrimRight(stderr, "\n"))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func backgroundJobMetadata(job shell.BackgroundJob) BashBackgroundResponseMetadata {
+	return BashBackgroundResponseMetadata{
+		ID:       job.ID,
+		Command:  job.Command,
+		State:    string(job.State),
+		ExitCode: job.ExitCode,
+	}
+}
@@ -0,0 +1,380 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type CallGraphParams struct {
+	NavigationParams
+	Direction string `json:"direction"`
+	Depth     int    `json:"depth"`
+}
+
+// Call graph directions.
+const (
+	CallGraphIncoming   = "incoming"
+	CallGraphOutgoing   = "outgoing"
+	CallGraphSupertypes = "supertypes"
+	CallGraphSubtypes   = "subtypes"
+)
+
+// CallGraphNode is a function or type in a call or type hierarchy.
+type CallGraphNode struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail,omitempty"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	// CallLines are the lines of the calls between the node and its
+	// parent, in the file of the caller.
+	CallLines []int `json:"call_lines,omitempty"`
+	// Recursive is set when the node already appears above it in the tree,
+	// so its children are left out.
+	Recursive bool            `json:"recursive,omitempty"`
+	Children  []CallGraphNode `json:"children,omitempty"`
+}
+
+type CallGraphResponseMetadata struct {
+	Direction string          `json:"direction"`
+	Roots     []CallGraphNode `json:"roots"`
+}
+
+type callGraphTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	CallGraphToolName = "call_graph"
+	defaultGraphDepth = 2
+	maxGraphDepth     = 5
+	// maxGraphNodes bounds the number of requests made for a single graph.
+	maxGraphNodes        = 200
+	callGraphDescription = `Explore the call hierarchy or the type hierarchy of a symbol using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use before changing a function to find everything that calls it, directly or indirectly
+- Use to understand what a function depends on by following the functions it calls
+- Use to find the interfaces a type implements or the types implementing an interface
+
+HOW TO USE:
+- Provide the file path and the line of the symbol, optionally with its column or name
+- Or provide the file path and the symbol name to use its first occurrence in the file
+- Set direction to incoming (callers, the default), outgoing (callees), supertypes or subtypes
+- Set depth to the number of levels to follow (defaults to 2, at most 5)
+
+FEATURES:
+- Returns a tree with the location of every function or type
+- Call trees include the lines of the calls
+- Recursive calls are marked instead of being followed again
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server that supports call or type hierarchies
+- Large graphs are cut off after 200 entries
+
+TIPS:
+- Start with depth 1 and increase it for the branches you need`
+)
+
+func NewCallGraphTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &callGraphTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (c *callGraphTool) Name() string {
+	return CallGraphToolName
+}
+
+func (c *callGraphTool) ReadOnly() bool {
+	return true
+}
+
+func (c *callGraphTool) Info() ToolInfo {
+	parameters := navigationParameters()
+	parameters["direction"] = map[string]any{
+		"type":        "string",
+		"description": "What to follow from the symbol",
+		"enum":        []string{CallGraphIncoming, CallGraphOutgoing, CallGraphSupertypes, CallGraphSubtypes},
+	}
+	parameters["depth"] = map[string]any{
+		"type":        "integer",
+		"description": "The number of levels to follow (defaults to 2, at most 5)",
+	}
+	return ToolInfo{
+		Name:        CallGraphToolName,
+		Description: callGraphDescription,
+		Parameters:  parameters,
+		Required:    []string{},
+	}
+}
+
+func (c *callGraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params CallGraphParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Direction == "" {
+		params.Direction = CallGraphIncoming
+	}
+	switch params.Direction {
+	case CallGraphIncoming, CallGraphOutgoing, CallGraphSupertypes, CallGraphSubtypes:
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("invalid direction %q", params.Direction)), nil
+	}
+	if params.Depth <= 0 {
+		params.Depth = defaultGraphDepth
+	}
+	params.Depth = min(params.Depth, maxGraphDepth)
+
+	target, err := resolveNavigationTarget(ctx, c.lspClients, c.workingDir, params.NavigationParams)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var (
+		roots   []CallGraphNode
+		lastErr error
+	)
+	for _, client := range target.clients {
+		b := &graphBuilder{client: client, snippets: newNavigationSnippets(c.workingDir)}
+		switch params.Direction {
+		case CallGraphIncoming, CallGraphOutgoing:
+			roots, err = b.callGraph(ctx, target, params.Direction, params.Depth)
+		default:
+			roots, err = b.typeGraph(ctx, target, params.Direction, params.Depth)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(roots) > 0 {
+			break
+		}
+	}
+
+	snippets := newNavigationSnippets(c.workingDir)
+	if len(roots) == 0 {
+		if lastErr != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to get the %s hierarchy: %s", params.Direction, lastErr)), nil
+		}
+		return NewTextResponse(fmt.Sprintf("No symbol with a hierarchy found at %s", snippets.location(target.path, target.position))), nil
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(FormatCallGraph(params.Direction, roots)),
+		CallGraphResponseMetadata{Direction: params.Direction, Roots: roots},
+	), nil
+}
+
+// graphBuilder follows the hierarchy of a symbol with one LSP client.
+type graphBuilder struct {
+	client   *lsp.Client
+	snippets *navigationSnippets
+	nodes    int
+}
+
+func (b *graphBuilder) node(name string, kind protocol.SymbolKind, detail string, uri protocol.DocumentURI, rng protocol.Range) CallGraphNode {
+	b.nodes++
+	path, err := uri.Path()
+	if err != nil {
+		path = string(uri)
+	}
+	return CallGraphNode{
+		Name:     name,
+		Kind:     symbolKind(kind),
+		Detail:   detail,
+		FilePath: b.snippets.relative(path),
+		Line:     int(rng.Start.Line) + 1,
+	}
+}
+
+func hierarchyKey(uri protocol.DocumentURI, rng protocol.Range) string {
+	return fmt.Sprintf("%s:%d:%d", uri, rng.Start.Line, rng.Start.Character)
+}
+
+func (b *graphBuilder) callGraph(ctx context.Context, target navigationTarget, direction string, depth int) ([]CallGraphNode, error) {
+	items, err := b.client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: target.positionParams(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]CallGraphNode, 0, len(items))
+	for _, item := range items {
+		root := b.node(item.Name, item.Kind, item.Detail, item.URI, item.SelectionRange)
+		seen := map[string]bool{hierarchyKey(item.URI, item.SelectionRange): true}
+		root.Children = b.calls(ctx, item, direction, depth, seen)
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// calls returns the callers or callees of item, down to the given depth.
+// seen holds the items on the path from the root, to stop at recursion.
+func (b *graphBuilder) calls(ctx context.Context, item protocol.CallHierarchyItem, direction string, depth int, seen map[string]bool) []CallGraphNode {
+	if depth == 0 || b.nodes >= maxGraphNodes {
+		return nil
+	}
+
+	type related struct {
+		item   protocol.CallHierarchyItem
+		ranges []protocol.Range
+	}
+	var relatedItems []related
+	if direction == CallGraphIncoming {
+		calls, err := b.client.IncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+		if err != nil {
+			return nil
+		}
+		for _, call := range calls {
+			relatedItems = append(relatedItems, related{item: call.From, ranges: call.FromRanges})
+		}
+	} else {
+		calls, err := b.client.OutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+		if err != nil {
+			return nil
+		}
+		for _, call := range calls {
+			relatedItems = append(relatedItems, related{item: call.To, ranges: call.FromRanges})
+		}
+	}
+
+	nodes := make([]CallGraphNode, 0, len(relatedItems))
+	for _, r := range relatedItems {
+		if b.nodes >= maxGraphNodes {
+			break
+		}
+		node := b.node(r.item.Name, r.item.Kind, r.item.Detail, r.item.URI, r.item.SelectionRange)
+		for _, rng := range r.ranges {
+			node.CallLines = append(node.CallLines, int(rng.Start.Line)+1)
+		}
+		key := hierarchyKey(r.item.URI, r.item.SelectionRange)
+		if seen[key] {
+			node.Recursive = true
+		} else {
+			seen[key] = true
+			node.Children = b.calls(ctx, r.item, direction, depth-1, seen)
+			delete(seen, key)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (b *graphBuilder) typeGraph(ctx context.Context, target navigationTarget, direction string, depth int) ([]CallGraphNode, error) {
+	items, err := b.client.PrepareTypeHierarchy(ctx, protocol.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: target.positionParams(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]CallGraphNode, 0, len(items))
+	for _, item := range items {
+		root := b.node(item.Name, item.Kind, item.Detail, item.URI, item.SelectionRange)
+		seen := map[string]bool{hierarchyKey(item.URI, item.SelectionRange): true}
+		root.Children = b.types(ctx, item, direction, depth, seen)
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// types returns the supertypes or subtypes of item, down to the given depth.
+func (b *graphBuilder) types(ctx context.Context, item protocol.TypeHierarchyItem, direction string, depth int, seen map[string]bool) []CallGraphNode {
+	if depth == 0 || b.nodes >= maxGraphNodes {
+		return nil
+	}
+
+	var (
+		items []protocol.TypeHierarchyItem
+		err   error
+	)
+	if direction == CallGraphSupertypes {
+		items, err = b.client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+	} else {
+		items, err = b.client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+	}
+	if err != nil {
+		return nil
+	}
+
+	nodes := make([]CallGraphNode, 0, len(items))
+	for _, related := range items {
+		if b.nodes >= maxGraphNodes {
+			break
+		}
+		node := b.node(related.Name, related.Kind, related.Detail, related.URI, related.SelectionRange)
+		key := hierarchyKey(related.URI, related.SelectionRange)
+		if seen[key] {
+			node.Recursive = true
+		} else {
+			seen[key] = true
+			node.Children = b.types(ctx, related, direction, depth-1, seen)
+			delete(seen, key)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// FormatCallGraph renders a call or type hierarchy as an indented tree, one
+// symbol per line.
+func FormatCallGraph(direction string, roots []CallGraphNode) string {
+	var label string
+	switch direction {
+	case CallGraphIncoming:
+		label = "Callers of"
+	case CallGraphOutgoing:
+		label = "Calls made by"
+	case CallGraphSupertypes:
+		label = "Supertypes of"
+	case CallGraphSubtypes:
+		label = "Subtypes of"
+	}
+
+	var output strings.Builder
+	var write func(nodes []CallGraphNode, depth int)
+	write = func(nodes []CallGraphNode, depth int) {
+		for _, node := range nodes {
+			output.WriteString(strings.Repeat("  ", depth))
+			output.WriteString(FormatCallGraphNode(node))
+			output.WriteString("\n")
+			write(node.Children, depth+1)
+		}
+	}
+	for i, root := range roots {
+		if i > 0 {
+			output.WriteString("\n")
+		}
+		fmt.Fprintf(&output, "%s %s\n", label, FormatCallGraphNode(root))
+		if len(root.Children) == 0 {
+			output.WriteString("  (none)\n")
+		}
+		write(root.Children, 1)
+	}
+	return output.String()
+}
+
+// FormatCallGraphNode renders a single symbol of a hierarchy.
+func FormatCallGraphNode(node CallGraphNode) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s %s:%d", node.Kind, node.Name, node.FilePath, node.Line)
+	if len(node.CallLines) > 0 {
+		lines := make([]string, len(node.CallLines))
+		for i, line := range node.CallLines {
+			lines[i] = fmt.Sprintf("%d", line)
+		}
+		fmt.Fprintf(&sb, " (calls at %s)", strings.Join(lines, ", "))
+	}
+	if node.Recursive {
+		sb.WriteString(" (recursive)")
+	}
+	return sb.String()
+}
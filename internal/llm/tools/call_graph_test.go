@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCallGraph(t *testing.T) {
+	t.Parallel()
+
+	roots := []CallGraphNode{{
+		Name:     "save",
+		Kind:     "Function",
+		FilePath: "store.go",
+		Line:     10,
+		Children: []CallGraphNode{
+			{
+				Name:      "handle",
+				Kind:      "Method",
+				FilePath:  "server.go",
+				Line:      20,
+				CallLines: []int{24, 31},
+				Children: []CallGraphNode{
+					{Name: "save", Kind: "Function", FilePath: "store.go", Line: 10, CallLines: []int{12}, Recursive: true},
+				},
+			},
+		},
+	}}
+
+	require.Equal(t, `Callers of Function save store.go:10
+  Method handle server.go:20 (calls at 24, 31)
+    Function save store.go:10 (calls at 12) (recursive)
+`, FormatCallGraph(CallGraphIncoming, roots))
+
+	require.Equal(t, `Subtypes of Interface Store store.go:3
+  (none)
+`, FormatCallGraph(CallGraphSubtypes, []CallGraphNode{{Name: "Store", Kind: "Interface", FilePath: "store.go", Line: 3}}))
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type CodeActionParams struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	EndLine  int    `json:"end_line"`
+	Apply    int    `json:"apply"`
+}
+
+type codeActionTool struct {
+	editor workspaceEditor
+}
+
+const (
+	CodeActionToolName    = "code_action"
+	codeActionDescription = `List and apply the quick-fixes and refactorings the language server offers for a range of lines.
+
+WHEN TO USE THIS TOOL:
+- Use to fix diagnostics the language server knows how to fix, such as missing imports or unused variables
+- Use to run refactorings like extracting a function or organizing imports
+
+HOW TO USE:
+- Provide the file path and the line (and optionally the end line) of the code, usually the lines of a diagnostic
+- Without apply, the available actions are listed with a number
+- Call the tool again with apply set to the number of an action to apply it
+
+FEATURES:
+- Includes the diagnostics on those lines so the server can offer fixes for them
+- Shows the changes of every file for approval before writing them
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- Actions that only run a server command or that create, move or delete files cannot be applied
+- Every file it changes must have been read with the View tool since it last changed
+
+TIPS:
+- Use the diagnostics tool to find the lines that have problems`
+)
+
+func NewCodeActionTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service, workingDir string) BaseTool {
+	return &codeActionTool{
+		editor: workspaceEditor{
+			lspClients:  lspClients,
+			permissions: permissions,
+			files:       files,
+			workingDir:  workingDir,
+		},
+	}
+}
+
+func (c *codeActionTool) Name() string {
+	return CodeActionToolName
+}
+
+func (c *codeActionTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        CodeActionToolName,
+		Description: codeActionDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "The first line of the range (1-based)",
+			},
+			"end_line": map[string]any{
+				"type":        "integer",
+				"description": "The last line of the range (1-based, defaults to line)",
+			},
+			"apply": map[string]any{
+				"type":        "integer",
+				"description": "The number of the action to apply, as listed by a previous call",
+			},
+		},
+		Required: []string{"file_path", "line"},
+	}
+}
+
+func (c *codeActionTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params CodeActionParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+	if params.Line <= 0 {
+		return NewTextErrorResponse("line is required"), nil
+	}
+	params.EndLine = max(params.EndLine, params.Line)
+
+	path := params.FilePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.editor.workingDir, path)
+	}
+	lines, err := readLines(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("file not found: %s", params.FilePath)), nil
+		}
+		return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if params.Line > len(lines) {
+		return NewTextErrorResponse(fmt.Sprintf("line %d is past the end of the file", params.Line)), nil
+	}
+	clients, err := clientsForFile(ctx, c.editor.lspClients, path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	endLine := min(params.EndLine, len(lines))
+	rng := protocol.Range{
+		Start: protocol.Position{Line: uint32(params.Line - 1)},
+		End:   protocol.Position{Line: uint32(endLine - 1), Character: utf16Column(lines[endLine-1], len(lines[endLine-1]))},
+	}
+
+	for _, client := range clients {
+		actions, err := codeActions(ctx, client, path, rng)
+		if err != nil || len(actions) == 0 {
+			continue
+		}
+		if params.Apply == 0 {
+			return NewTextResponse(formatCodeActions(actions)), nil
+		}
+		if params.Apply > len(actions) {
+			return NewTextErrorResponse(fmt.Sprintf("there is no action %d, only %d actions are available", params.Apply, len(actions))), nil
+		}
+		return c.applyCodeAction(ctx, call, client, actions[params.Apply-1])
+	}
+	return NewTextResponse(fmt.Sprintf("No code actions available for lines %d-%d of %s", params.Line, endLine, params.FilePath)), nil
+}
+
+// codeActions requests the actions for a range, with the diagnostics
+// overlapping it as context.
+func codeActions(ctx context.Context, client *lsp.Client, path string, rng protocol.Range) ([]protocol.Or_Result_textDocument_codeAction_Item0_Elem, error) {
+	uri := protocol.URIFromPath(path)
+	var diagnostics []protocol.Diagnostic
+	for _, diag := range client.GetFileDiagnostics(uri) {
+		if diag.Range.End.Line >= rng.Start.Line && diag.Range.Start.Line <= rng.End.Line {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	return client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        rng,
+		Context:      protocol.CodeActionContext{Diagnostics: diagnostics},
+	})
+}
+
+func formatCodeActions(actions []protocol.Or_Result_textDocument_codeAction_Item0_Elem) string {
+	var output strings.Builder
+	for i, item := range actions {
+		switch action := item.Value.(type) {
+		case protocol.CodeAction:
+			fmt.Fprintf(&output, "%d. %s", i+1, action.Title)
+			if action.Kind != "" {
+				fmt.Fprintf(&output, " [%s]", action.Kind)
+			}
+			if action.IsPreferred {
+				output.WriteString(" (preferred)")
+			}
+			if action.Disabled != nil {
+				fmt.Fprintf(&output, " (disabled: %s)", action.Disabled.Reason)
+			}
+			output.WriteString("\n")
+			for _, diag := range action.Diagnostics {
+				fmt.Fprintf(&output, "   fixes line %d: %s\n", diag.Range.Start.Line+1, diag.Message)
+			}
+		case protocol.Command:
+			fmt.Fprintf(&output, "%d. %s [command]\n", i+1, action.Title)
+		}
+	}
+	output.WriteString("\nCall this tool again with apply set to the number of an action to apply it.")
+	return output.String()
+}
+
+func (c *codeActionTool) applyCodeAction(ctx context.Context, call ToolCall, client *lsp.Client, item protocol.Or_Result_textDocument_codeAction_Item0_Elem) (ToolResponse, error) {
+	action, ok := item.Value.(protocol.CodeAction)
+	if !ok {
+		return NewTextErrorResponse("this action only runs a server command and cannot be applied"), nil
+	}
+	if action.Disabled != nil {
+		return NewTextErrorResponse(fmt.Sprintf("this action is disabled: %s", action.Disabled.Reason)), nil
+	}
+	// Servers may leave out the edit until the action is resolved.
+	if action.Edit == nil && action.Data != nil {
+		resolved, err := client.ResolveCodeAction(ctx, action)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to resolve code action: %s", err)), nil
+		}
+		action = resolved
+	}
+	if action.Edit == nil {
+		return NewTextErrorResponse("this action only runs a server command and cannot be applied"), nil
+	}
+	return c.editor.apply(ctx, call, CodeActionToolName, fmt.Sprintf("Apply code action %q", action.Title), *action.Edit)
+}
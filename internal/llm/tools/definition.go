@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type DefinitionParams = NavigationParams
+
+type definitionTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	DefinitionToolName    = "definition"
+	definitionDescription = `Find where a symbol is defined using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use when you need to jump to the definition of a function, type, variable or method
+- More accurate than grep for code navigation since it understands the language
+
+HOW TO USE:
+- Provide the file path and the line of the symbol, optionally with its column or name
+- Or provide the file path and the symbol name to use its first occurrence in the file
+- Or provide only the symbol name to look it up in the whole workspace
+
+FEATURES:
+- Returns the location of each definition with the surrounding lines
+- Follows imports into other files and packages
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- Results depend on the language server and may be empty while it is still indexing
+
+TIPS:
+- Use the symbols tool to get an outline of a file before navigating
+- Use the references tool to find where a symbol is used`
+)
+
+func NewDefinitionTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &definitionTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (d *definitionTool) Name() string {
+	return DefinitionToolName
+}
+
+func (d *definitionTool) ReadOnly() bool {
+	return true
+}
+
+func (d *definitionTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DefinitionToolName,
+		Description: definitionDescription,
+		Parameters:  navigationParameters(),
+		Required:    []string{},
+	}
+}
+
+func (d *definitionTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DefinitionParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	target, err := resolveNavigationTarget(ctx, d.lspClients, d.workingDir, params)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var locations []protocol.Location
+	for _, client := range target.clients {
+		result, err := client.Definition(ctx, protocol.DefinitionParams{
+			TextDocumentPositionParams: target.positionParams(),
+		})
+		if err != nil {
+			continue
+		}
+		locations = definitionLocations(result)
+		if len(locations) > 0 {
+			break
+		}
+	}
+
+	snippets := newNavigationSnippets(d.workingDir)
+	if len(locations) == 0 {
+		return NewTextResponse(fmt.Sprintf("No definition found at %s", snippets.location(target.path, target.position))), nil
+	}
+
+	var output strings.Builder
+	for i, loc := range locations {
+		if i == maxNavigationResults {
+			fmt.Fprintf(&output, "\n... and %d more definitions\n", len(locations)-i)
+			break
+		}
+		path, err := loc.URI.Path()
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			output.WriteString("\n")
+		}
+		fmt.Fprintf(&output, "%s\n", snippets.location(path, loc.Range.Start))
+		output.WriteString(snippets.snippet(path, loc.Range.Start.Line, 2, 5))
+	}
+	return NewTextResponse(output.String()), nil
+}
+
+// definitionLocations flattens the possible shapes of a definition result.
+func definitionLocations(result protocol.Or_Result_textDocument_definition) []protocol.Location {
+	switch v := result.Value.(type) {
+	case protocol.Definition:
+		switch loc := v.Value.(type) {
+		case protocol.Location:
+			return []protocol.Location{loc}
+		case []protocol.Location:
+			return loc
+		}
+	case []protocol.DefinitionLink:
+		locations := make([]protocol.Location, 0, len(v))
+		for _, link := range v {
+			locations = append(locations, protocol.Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+		}
+		return locations
+	}
+	return nil
+}
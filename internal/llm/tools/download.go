@@ -137,7 +137,7 @@ func (t *downloadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for downloading files")
 	}
 
-	p := t.permissions.Request(
+	permErr := t.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        filePath,
@@ -148,8 +148,8 @@ func (t *downloadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 		},
 	)
 
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	// Handle timeout with context
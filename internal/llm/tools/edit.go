@@ -212,12 +212,13 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
+	content, formatted := formatOnWrite(ctx, filePath, content, e.lspClients)
 	_, additions, removals := diff.GenerateDiff(
 		"",
 		content,
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
-	p := e.permissions.Request(
+	permErr := e.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
@@ -232,8 +233,8 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 			},
 		},
 	)
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	err = os.WriteFile(filePath, []byte(content), 0o644)
@@ -242,7 +243,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 	}
 
 	// File can't be in the history so we create a new file history
-	_, err = e.files.Create(ctx, sessionID, filePath, "")
+	_, err = e.files.CreateNew(ctx, sessionID, filePath)
 	if err != nil {
 		// Log error but don't fail the operation
 		return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
@@ -259,7 +260,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("File created: "+filePath),
+		NewTextResponse(withFormattedNote("File created: "+filePath, formatted)),
 		EditResponseMetadata{
 			OldContent: "",
 			NewContent: content,
@@ -332,13 +333,14 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
+	newContent, formatted := formatOnWrite(ctx, filePath, newContent, e.lspClients)
 	_, additions, removals := diff.GenerateDiff(
 		oldContent,
 		newContent,
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
 
-	p := e.permissions.Request(
+	permErr := e.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
@@ -353,8 +355,8 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 			},
 		},
 	)
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	if isCrlf {
@@ -383,7 +385,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		}
 	}
 	// Store the new version
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, "")
+	_, err = e.files.CreateVersion(ctx, sessionID, filePath, newContent)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -392,7 +394,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("Content deleted from file: "+filePath),
+		NewTextResponse(withFormattedNote("Content deleted from file: "+filePath, formatted)),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -467,13 +469,15 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	if sessionID == "" || messageID == "" {
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
+
+	newContent, formatted := formatOnWrite(ctx, filePath, newContent, e.lspClients)
 	_, additions, removals := diff.GenerateDiff(
 		oldContent,
 		newContent,
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
 
-	p := e.permissions.Request(
+	permErr := e.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
@@ -488,8 +492,8 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 			},
 		},
 	)
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	if isCrlf {
@@ -527,7 +531,7 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("Content replaced in file: "+filePath),
+		NewTextResponse(withFormattedNote("Content replaced in file: "+filePath, formatted)),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -132,7 +132,7 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
-	p := t.permissions.Request(
+	permErr := t.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        t.workingDir,
@@ -144,8 +144,8 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		},
 	)
 
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	// Handle timeout with context
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+)
+
+// formattedOnWriteNote is added to the result of the edit tools when the
+// content they wrote was changed by a language server.
+const formattedOnWriteNote = "The file was formatted by the language server, view it again before editing it."
+
+// formatOnWrite formats content through the LSP clients configured with
+// format_on_write that handle the file, before it is written to path. It
+// reports whether the content was changed.
+func formatOnWrite(ctx context.Context, path, content string, lspClients map[string]*lsp.Client) (string, bool) {
+	formatted := content
+	for _, client := range sortedClients(lspClients) {
+		if !client.FormatOnWrite() || !client.HandlesFile(path) {
+			continue
+		}
+		result, err := client.FormatContent(ctx, path, formatted)
+		if err != nil {
+			slog.Warn("Failed to format file", "file", path, "error", err)
+			continue
+		}
+		formatted = result
+	}
+	return formatted, formatted != content
+}
+
+// withFormattedNote adds formattedOnWriteNote to message when the content was
+// formatted.
+func withFormattedNote(message string, formatted bool) string {
+	if formatted {
+		return message + "\n" + formattedOnWriteNote
+	}
+	return message
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type HoverParams = NavigationParams
+
+type hoverTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	HoverToolName    = "hover"
+	hoverDescription = `Get the type information and documentation of a symbol using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use when you need the signature or type of a function, variable or expression
+- Use to read the documentation of a symbol without opening its definition
+
+HOW TO USE:
+- Provide the file path and the line of the symbol, optionally with its column or name
+- Or provide the file path and the symbol name to use its first occurrence in the file
+- Or provide only the symbol name to look it up in the whole workspace
+
+FEATURES:
+- Returns what an editor shows when hovering the symbol, usually its signature and doc comment
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- The content depends on the language server`
+)
+
+func NewHoverTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &hoverTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (h *hoverTool) Name() string {
+	return HoverToolName
+}
+
+func (h *hoverTool) ReadOnly() bool {
+	return true
+}
+
+func (h *hoverTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        HoverToolName,
+		Description: hoverDescription,
+		Parameters:  navigationParameters(),
+		Required:    []string{},
+	}
+}
+
+func (h *hoverTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params HoverParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	target, err := resolveNavigationTarget(ctx, h.lspClients, h.workingDir, params)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	for _, client := range target.clients {
+		result, err := client.Hover(ctx, protocol.HoverParams{
+			TextDocumentPositionParams: target.positionParams(),
+		})
+		if err != nil {
+			continue
+		}
+		if content := strings.TrimSpace(result.Contents.Value); content != "" {
+			return NewTextResponse(content), nil
+		}
+	}
+	snippets := newNavigationSnippets(h.workingDir)
+	return NewTextResponse(fmt.Sprintf("No hover information at %s", snippets.location(target.path, target.position))), nil
+}
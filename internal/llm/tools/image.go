@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"os"
+
+	"github.com/disintegration/imageorient"
+	"github.com/nfnt/resize"
+	_ "golang.org/x/image/bmp"  // register the BMP decoder
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+const (
+	// MaxImageReadSize is the largest image file the view tool loads.
+	MaxImageReadSize = 20 * 1024 * 1024
+	// maxImageDimension is the longest side images are downscaled to, the
+	// largest size providers use without resizing them again.
+	maxImageDimension = 1568
+	// maxImageBytes keeps images under the 5MB providers accept once they
+	// are base64 encoded.
+	maxImageBytes = 3 * 1024 * 1024
+	// maxImagePixels bounds the memory decoding an image takes, as small
+	// files can claim huge dimensions.
+	maxImagePixels = 50_000_000
+)
+
+// loadedImage is an image ready to be sent to a model.
+type loadedImage struct {
+	data     []byte
+	mimeType string
+	width    int
+	height   int
+	// originalWidth and originalHeight are the size of the image file, when
+	// it was downscaled.
+	originalWidth  int
+	originalHeight int
+}
+
+// loadImage reads the image at path, downscaling it and converting it to PNG
+// or JPEG when it is too large or in a format providers do not accept.
+func loadImage(path string) (loadedImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return loadedImage{}, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return loadedImage{}, fmt.Errorf("unsupported image: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return loadedImage{}, fmt.Errorf("image is too large: %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	supported := format == "png" || format == "jpeg" || format == "gif" || format == "webp"
+	if supported && max(cfg.Width, cfg.Height) <= maxImageDimension && len(data) <= maxImageBytes {
+		return loadedImage{
+			data:     data,
+			mimeType: "image/" + format,
+			width:    cfg.Width,
+			height:   cfg.Height,
+		}, nil
+	}
+
+	img, _, err := imageorient.Decode(bytes.NewReader(data))
+	if err != nil {
+		return loadedImage{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+	img = resize.Thumbnail(maxImageDimension, maxImageDimension, img, resize.Lanczos3)
+
+	var buf bytes.Buffer
+	mimeType := "image/png"
+	if format == "jpeg" {
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err == nil && buf.Len() > maxImageBytes {
+		// Photos and screenshots with noise compress poorly as PNG.
+		buf.Reset()
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return loadedImage{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	return loadedImage{
+		data:           buf.Bytes(),
+		mimeType:       mimeType,
+		width:          bounds.Dx(),
+		height:         bounds.Dy(),
+		originalWidth:  cfg.Width,
+		originalHeight: cfg.Height,
+	}, nil
+}
+
+// description returns the text sent to the model along with the image.
+func (i loadedImage) description(path string) string {
+	if i.originalWidth == 0 {
+		return fmt.Sprintf("Image file: %s (%dx%d)", path, i.width, i.height)
+	}
+	return fmt.Sprintf("Image file: %s (%dx%d, downscaled from %dx%d)", path, i.width, i.height, i.originalWidth, i.originalHeight)
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+)
+
+func writeTestImage(t *testing.T, name string, width, height int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	if filepath.Ext(name) == ".bmp" {
+		require.NoError(t, bmp.Encode(f, img))
+	} else {
+		require.NoError(t, png.Encode(f, img))
+	}
+	return path
+}
+
+func TestLoadImage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("small images are sent as is", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestImage(t, "small.png", 40, 20)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		img, err := loadImage(path)
+		require.NoError(t, err)
+		require.Equal(t, data, img.data)
+		require.Equal(t, "image/png", img.mimeType)
+		require.Equal(t, "Image file: a.png (40x20)", img.description("a.png"))
+	})
+
+	t.Run("large images are downscaled", func(t *testing.T) {
+		t.Parallel()
+		img, err := loadImage(writeTestImage(t, "large.png", 3136, 1000))
+		require.NoError(t, err)
+		require.Equal(t, "image/png", img.mimeType)
+		require.Equal(t, "Image file: a.png (1568x500, downscaled from 3136x1000)", img.description("a.png"))
+
+		cfg, err := png.DecodeConfig(bytes.NewReader(img.data))
+		require.NoError(t, err)
+		require.Equal(t, 1568, cfg.Width)
+	})
+
+	t.Run("unsupported formats are converted", func(t *testing.T) {
+		t.Parallel()
+		img, err := loadImage(writeTestImage(t, "small.bmp", 40, 20))
+		require.NoError(t, err)
+		require.Equal(t, "image/png", img.mimeType)
+		require.Equal(t, 40, img.width)
+	})
+
+	t.Run("images with too many pixels fail before decoding", func(t *testing.T) {
+		t.Parallel()
+		// A tiny PNG whose header claims 100000x100000 pixels.
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+		data := buf.Bytes()
+		binary.BigEndian.PutUint32(data[16:], 100000)
+		binary.BigEndian.PutUint32(data[20:], 100000)
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+		path := filepath.Join(t.TempDir(), "huge.png")
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+
+		_, err := loadImage(path)
+		require.ErrorContains(t, err, "too large")
+	})
+
+	t.Run("invalid images fail", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "broken.png")
+		require.NoError(t, os.WriteFile(path, []byte("not an image"), 0o644))
+		_, err := loadImage(path)
+		require.Error(t, err)
+	})
+}
@@ -89,6 +89,10 @@ func (l *lsTool) Name() string {
 	return LSToolName
 }
 
+func (l *lsTool) ReadOnly() bool {
+	return true
+}
+
 func (l *lsTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        LSToolName,
@@ -150,7 +154,7 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 			return ToolResponse{}, fmt.Errorf("session ID and message ID are required for accessing directories outside working directory")
 		}
 
-		granted := l.permissions.Request(
+		permErr := l.permissions.Authorize(
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        absSearchPath,
@@ -162,8 +166,8 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 			},
 		)
 
-		if !granted {
-			return ToolResponse{}, permission.ErrorPermissionDenied
+		if permErr != nil {
+			return ToolResponse{}, permErr
 		}
 	}
 
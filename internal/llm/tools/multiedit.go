@@ -248,10 +248,12 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
+	currentContent, formatted := formatOnWrite(ctx, params.FilePath, currentContent, m.lspClients)
+
 	// Check permissions
 	_, additions, removals := diff.GenerateDiff("", currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
 
-	p := m.permissions.Request(permission.CreatePermissionRequest{
+	permErr := m.permissions.Authorize(permission.CreatePermissionRequest{
 		SessionID:   sessionID,
 		Path:        fsext.PathOrPrefix(params.FilePath, m.workingDir),
 		ToolCallID:  call.ID,
@@ -264,8 +266,8 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 			NewContent: currentContent,
 		},
 	})
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	// Write the file
@@ -275,7 +277,7 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 	}
 
 	// Update file history
-	_, err = m.files.Create(ctx, sessionID, params.FilePath, "")
+	_, err = m.files.CreateNew(ctx, sessionID, params.FilePath)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
 	}
@@ -289,7 +291,7 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 	recordFileRead(params.FilePath)
 
 	return WithResponseMetadata(
-		NewTextResponse(fmt.Sprintf("File created with %d edits: %s", len(params.Edits), params.FilePath)),
+		NewTextResponse(withFormattedNote(fmt.Sprintf("File created with %d edits: %s", len(params.Edits), params.FilePath), formatted)),
 		MultiEditResponseMetadata{
 			OldContent:   "",
 			NewContent:   currentContent,
@@ -358,9 +360,11 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for editing file")
 	}
 
+	currentContent, formatted := formatOnWrite(ctx, params.FilePath, currentContent, m.lspClients)
+
 	// Generate diff and check permissions
 	_, additions, removals := diff.GenerateDiff(oldContent, currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
-	p := m.permissions.Request(permission.CreatePermissionRequest{
+	permErr := m.permissions.Authorize(permission.CreatePermissionRequest{
 		SessionID:   sessionID,
 		Path:        fsext.PathOrPrefix(params.FilePath, m.workingDir),
 		ToolCallID:  call.ID,
@@ -373,8 +377,8 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 			NewContent: currentContent,
 		},
 	})
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
 	if isCrlf {
@@ -413,7 +417,7 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 	recordFileRead(params.FilePath)
 
 	return WithResponseMetadata(
-		NewTextResponse(fmt.Sprintf("Applied %d edits to file: %s", len(params.Edits), params.FilePath)),
+		NewTextResponse(withFormattedNote(fmt.Sprintf("Applied %d edits to file: %s", len(params.Edits), params.FilePath), formatted)),
 		MultiEditResponseMetadata{
 			OldContent:   oldContent,
 			NewContent:   currentContent,
@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+// NavigationParams locates the code that a navigation tool operates on,
+// either by position or by symbol name.
+type NavigationParams struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Symbol   string `json:"symbol"`
+}
+
+// maxNavigationResults limits the number of locations a navigation tool
+// reports.
+const maxNavigationResults = 100
+
+func navigationParameters() map[string]any {
+	return map[string]any{
+		"file_path": map[string]any{
+			"type":        "string",
+			"description": "The path to the file containing the symbol (optional when symbol is given)",
+		},
+		"line": map[string]any{
+			"type":        "integer",
+			"description": "The line number of the symbol (1-based)",
+		},
+		"column": map[string]any{
+			"type":        "integer",
+			"description": "The column of the symbol (1-based, defaults to the symbol or the first non-blank character of the line)",
+		},
+		"symbol": map[string]any{
+			"type":        "string",
+			"description": "The name of the symbol, used when no line is given or to find the column on the line",
+		},
+	}
+}
+
+// navigationTarget is a position in a file together with the LSP clients
+// that handle that file.
+type navigationTarget struct {
+	clients  []*lsp.Client
+	path     string
+	position protocol.Position
+}
+
+func (t navigationTarget) positionParams() protocol.TextDocumentPositionParams {
+	return protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(t.path)},
+		Position:     t.position,
+	}
+}
+
+// sortedClients returns the LSP clients ordered by name, so that results do
+// not depend on map order.
+func sortedClients(lspClients map[string]*lsp.Client) []*lsp.Client {
+	names := make([]string, 0, len(lspClients))
+	for name := range lspClients {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	clients := make([]*lsp.Client, 0, len(names))
+	for _, name := range names {
+		clients = append(clients, lspClients[name])
+	}
+	return clients
+}
+
+// clientsForFile returns the LSP clients handling path, with the file
+// opened in each of them.
+func clientsForFile(ctx context.Context, lspClients map[string]*lsp.Client, path string) ([]*lsp.Client, error) {
+	var clients []*lsp.Client
+	for _, client := range sortedClients(lspClients) {
+		if !client.HandlesFile(path) {
+			continue
+		}
+		if err := client.OpenFileOnDemand(ctx, path); err != nil {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no LSP client handles %s", path)
+	}
+	return clients, nil
+}
+
+// resolveNavigationTarget finds the position described by params. Without a
+// file, the symbol is looked up in the workspace.
+func resolveNavigationTarget(ctx context.Context, lspClients map[string]*lsp.Client, workingDir string, params NavigationParams) (navigationTarget, error) {
+	if params.FilePath == "" {
+		if params.Symbol == "" {
+			return navigationTarget{}, fmt.Errorf("file_path or symbol is required")
+		}
+		return findWorkspaceSymbol(ctx, lspClients, params.Symbol)
+	}
+
+	path := params.FilePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return navigationTarget{}, fmt.Errorf("file not found: %s", params.FilePath)
+	}
+	clients, err := clientsForFile(ctx, lspClients, path)
+	if err != nil {
+		return navigationTarget{}, err
+	}
+	target := navigationTarget{clients: clients, path: path}
+
+	if params.Line > 0 {
+		line := uint32(params.Line - 1)
+		if params.Column > 0 {
+			target.position = protocol.Position{Line: line, Character: uint32(params.Column - 1)}
+			return target, nil
+		}
+		character, ok := findInLine(path, line, params.Symbol)
+		if !ok && params.Symbol != "" {
+			return navigationTarget{}, fmt.Errorf("symbol %q not found on line %d", params.Symbol, params.Line)
+		}
+		target.position = protocol.Position{Line: line, Character: character}
+		return target, nil
+	}
+
+	if params.Symbol == "" {
+		return navigationTarget{}, fmt.Errorf("line or symbol is required")
+	}
+	for _, client := range clients {
+		if pos, ok := findDocumentSymbol(ctx, client, path, params.Symbol); ok {
+			target.position = pos
+			return target, nil
+		}
+	}
+	if pos, ok := findInFile(path, params.Symbol); ok {
+		target.position = pos
+		return target, nil
+	}
+	return navigationTarget{}, fmt.Errorf("symbol %q not found in %s", params.Symbol, params.FilePath)
+}
+
+// findWorkspaceSymbol returns the position of the symbol with the given
+// name, preferring exact matches over partial ones.
+func findWorkspaceSymbol(ctx context.Context, lspClients map[string]*lsp.Client, name string) (navigationTarget, error) {
+	for _, client := range sortedClients(lspClients) {
+		result, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: name})
+		if err != nil {
+			continue
+		}
+		symbols, err := result.Results()
+		if err != nil || len(symbols) == 0 {
+			continue
+		}
+		best := symbols[0]
+		for _, symbol := range symbols {
+			if symbol.GetName() == name {
+				best = symbol
+				break
+			}
+		}
+		loc := best.GetLocation()
+		path, err := loc.URI.Path()
+		if err != nil {
+			continue
+		}
+		if err := client.OpenFileOnDemand(ctx, path); err != nil {
+			continue
+		}
+		pos := loc.Range.Start
+		if character, ok := findInLine(path, pos.Line, name); ok {
+			pos.Character = character
+		}
+		return navigationTarget{clients: []*lsp.Client{client}, path: path, position: pos}, nil
+	}
+	return navigationTarget{}, fmt.Errorf("symbol %q not found in the workspace", name)
+}
+
+// findDocumentSymbol returns the position of the name of the symbol
+// declared in path.
+func findDocumentSymbol(ctx context.Context, client *lsp.Client, path, name string) (protocol.Position, bool) {
+	result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(path)},
+	})
+	if err != nil {
+		return protocol.Position{}, false
+	}
+	switch symbols := result.Value.(type) {
+	case []protocol.DocumentSymbol:
+		var find func(symbols []protocol.DocumentSymbol) (protocol.Position, bool)
+		find = func(symbols []protocol.DocumentSymbol) (protocol.Position, bool) {
+			for _, symbol := range symbols {
+				if symbol.Name == name {
+					return symbol.SelectionRange.Start, true
+				}
+				if pos, ok := find(symbol.Children); ok {
+					return pos, true
+				}
+			}
+			return protocol.Position{}, false
+		}
+		return find(symbols)
+	case []protocol.SymbolInformation:
+		for _, symbol := range symbols {
+			if symbol.Name != name {
+				continue
+			}
+			pos := symbol.Location.Range.Start
+			if character, ok := findInLine(path, pos.Line, name); ok {
+				pos.Character = character
+			}
+			return pos, true
+		}
+	}
+	return protocol.Position{}, false
+}
+
+// findInLine returns the column of the first whole-word occurrence of name on
+// the given line, or of its first non-blank character when name is empty.
+func findInLine(path string, line uint32, name string) (uint32, bool) {
+	lines, err := readLines(path)
+	if err != nil || int(line) >= len(lines) {
+		return 0, false
+	}
+	text := lines[line]
+	if name == "" {
+		idx := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsSpace(r) })
+		return utf16Column(text, max(idx, 0)), true
+	}
+	idx := indexWord(text, name)
+	if idx < 0 {
+		return 0, false
+	}
+	return utf16Column(text, idx), true
+}
+
+// findInFile returns the position of the first whole-word occurrence of name
+// in path.
+func findInFile(path, name string) (protocol.Position, bool) {
+	lines, err := readLines(path)
+	if err != nil {
+		return protocol.Position{}, false
+	}
+	for i, text := range lines {
+		if idx := indexWord(text, name); idx >= 0 {
+			return protocol.Position{Line: uint32(i), Character: utf16Column(text, idx)}, true
+		}
+	}
+	return protocol.Position{}, false
+}
+
+// indexWord returns the byte offset of the first occurrence of word in s that
+// is not part of a longer identifier.
+func indexWord(s, word string) int {
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for offset := 0; ; {
+		idx := strings.Index(s[offset:], word)
+		if idx < 0 {
+			return -1
+		}
+		start, end := offset+idx, offset+idx+len(word)
+		prev, _ := utf8.DecodeLastRuneInString(s[:start])
+		next, _ := utf8.DecodeRuneInString(s[end:])
+		before := start == 0 || !isIdent(prev)
+		after := end == len(s) || !isIdent(next)
+		if before && after {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+// utf16Column converts a byte offset in line to the UTF-16 column used by
+// the LSP protocol.
+func utf16Column(line string, offset int) uint32 {
+	return uint32(len(utf16.Encode([]rune(line[:offset]))))
+}
+
+func readLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), nil
+}
+
+// navigationSnippets formats locations with the lines around them, reading
+// each file once.
+type navigationSnippets struct {
+	workingDir string
+	files      map[string][]string
+}
+
+func newNavigationSnippets(workingDir string) *navigationSnippets {
+	return &navigationSnippets{workingDir: workingDir, files: make(map[string][]string)}
+}
+
+// location returns the path of loc relative to the working directory, with
+// its 1-based line and column.
+func (s *navigationSnippets) location(path string, pos protocol.Position) string {
+	return fmt.Sprintf("%s:%d:%d", s.relative(path), pos.Line+1, pos.Character+1)
+}
+
+func (s *navigationSnippets) relative(path string) string {
+	rel, err := filepath.Rel(s.workingDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// snippet returns the lines from before to after around line, numbered and
+// with the given line marked.
+func (s *navigationSnippets) snippet(path string, line uint32, before, after int) string {
+	lines, ok := s.files[path]
+	if !ok {
+		lines, _ = readLines(path)
+		s.files[path] = lines
+	}
+	if int(line) >= len(lines) {
+		return ""
+	}
+	start := max(int(line)-before, 0)
+	end := min(int(line)+after+1, len(lines))
+	var sb strings.Builder
+	for i := start; i < end; i++ {
+		marker := " "
+		if i == int(line) {
+			marker = ">"
+		}
+		text := lines[i]
+		if len(text) > MaxLineLength {
+			text = text[:MaxLineLength] + "..."
+		}
+		fmt.Fprintf(&sb, "%s%6d|%s\n", marker, i+1, text)
+	}
+	return sb.String()
+}
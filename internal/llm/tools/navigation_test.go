@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexWord(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 5, indexWord("func Run() { runner.Run() }", "Run"))
+	require.Equal(t, 19, indexWord("var RunnerX = 1; x.Runner", "Runner"))
+	require.Equal(t, -1, indexWord("Runner", "Run"))
+	require.Equal(t, 0, indexWord("x", "x"))
+}
+
+func TestFindPositions(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	content := "package main\n\n// ünïcode Config\nfunc NewConfig() *Config {\n\treturn &Config{}\n}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	character, ok := findInLine(path, 3, "Config")
+	require.True(t, ok)
+	require.Equal(t, uint32(18), character)
+
+	character, ok = findInLine(path, 4, "")
+	require.True(t, ok)
+	require.Equal(t, uint32(1), character)
+
+	_, ok = findInLine(path, 4, "Missing")
+	require.False(t, ok)
+
+	// Columns are counted in UTF-16 code units.
+	pos, ok := findInFile(path, "Config")
+	require.True(t, ok)
+	require.Equal(t, protocol.Position{Line: 2, Character: 11}, pos)
+}
+
+func TestDefinitionLocations(t *testing.T) {
+	t.Parallel()
+
+	loc := protocol.Location{URI: "file:///a.go", Range: protocol.Range{Start: protocol.Position{Line: 3}}}
+	require.Equal(t, []protocol.Location{loc}, definitionLocations(protocol.Or_Result_textDocument_definition{
+		Value: protocol.Definition{Value: loc},
+	}))
+
+	links := definitionLocations(protocol.Or_Result_textDocument_definition{
+		Value: []protocol.DefinitionLink{{
+			TargetURI:            "file:///b.go",
+			TargetRange:          protocol.Range{Start: protocol.Position{Line: 1}},
+			TargetSelectionRange: protocol.Range{Start: protocol.Position{Line: 2, Character: 5}},
+		}},
+	})
+	require.Len(t, links, 1)
+	require.Equal(t, protocol.DocumentURI("file:///b.go"), links[0].URI)
+	require.Equal(t, protocol.Position{Line: 2, Character: 5}, links[0].Range.Start)
+
+	require.Empty(t, definitionLocations(protocol.Or_Result_textDocument_definition{}))
+}
+
+func TestNavigationSnippet(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644))
+
+	snippets := newNavigationSnippets(dir)
+	require.Equal(t, "a.txt:2:3", snippets.location(path, protocol.Position{Line: 1, Character: 2}))
+	require.Equal(t, "      1|one\n>     2|two\n      3|three\n", snippets.snippet(path, 1, 1, 1))
+	require.Equal(t, ">     1|one\n      2|two\n", snippets.snippet(path, 0, 1, 1))
+}
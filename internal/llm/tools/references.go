@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type ReferencesParams = NavigationParams
+
+type referencesTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	ReferencesToolName    = "references"
+	referencesDescription = `Find all references to a symbol using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use when you need to know where a function, type, variable or method is used
+- Use before renaming or changing the signature of a symbol
+- More accurate than grep since it ignores unrelated symbols with the same name
+
+HOW TO USE:
+- Provide the file path and the line of the symbol, optionally with its column or name
+- Or provide the file path and the symbol name to use its first occurrence in the file
+- Or provide only the symbol name to look it up in the whole workspace
+
+FEATURES:
+- Returns every reference grouped by file with the surrounding lines
+- Includes the declaration of the symbol
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- Results are limited to 100 references
+- Results depend on the language server and may be incomplete while it is still indexing
+
+TIPS:
+- Use the definition tool to find where a symbol is declared`
+)
+
+func NewReferencesTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &referencesTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (r *referencesTool) Name() string {
+	return ReferencesToolName
+}
+
+func (r *referencesTool) ReadOnly() bool {
+	return true
+}
+
+func (r *referencesTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReferencesToolName,
+		Description: referencesDescription,
+		Parameters:  navigationParameters(),
+		Required:    []string{},
+	}
+}
+
+func (r *referencesTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ReferencesParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	target, err := resolveNavigationTarget(ctx, r.lspClients, r.workingDir, params)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var locations []protocol.Location
+	for _, client := range target.clients {
+		locations, err = client.References(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: target.positionParams(),
+			Context:                    protocol.ReferenceContext{IncludeDeclaration: true},
+		})
+		if err == nil && len(locations) > 0 {
+			break
+		}
+	}
+
+	snippets := newNavigationSnippets(r.workingDir)
+	if len(locations) == 0 {
+		return NewTextResponse(fmt.Sprintf("No references found at %s", snippets.location(target.path, target.position))), nil
+	}
+
+	slices.SortFunc(locations, func(a, b protocol.Location) int {
+		return cmp.Or(
+			strings.Compare(string(a.URI), string(b.URI)),
+			cmp.Compare(a.Range.Start.Line, b.Range.Start.Line),
+			cmp.Compare(a.Range.Start.Character, b.Range.Start.Character),
+		)
+	})
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Found %d references\n", len(locations))
+	var currentURI protocol.DocumentURI
+	for i, loc := range locations {
+		if i == maxNavigationResults {
+			fmt.Fprintf(&output, "\n... and %d more references\n", len(locations)-i)
+			break
+		}
+		path, err := loc.URI.Path()
+		if err != nil {
+			continue
+		}
+		if loc.URI != currentURI {
+			currentURI = loc.URI
+			fmt.Fprintf(&output, "\n%s:\n", snippets.relative(path))
+		}
+		fmt.Fprintf(&output, "  %s\n", snippets.location(path, loc.Range.Start))
+		output.WriteString(snippets.snippet(path, loc.Range.Start.Line, 1, 1))
+	}
+	return NewTextResponse(output.String()), nil
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type RenameParams struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Symbol   string `json:"symbol"`
+	NewName  string `json:"new_name"`
+}
+
+type renameTool struct {
+	editor workspaceEditor
+}
+
+const (
+	RenameToolName    = "rename"
+	renameDescription = `Rename a symbol and all of its references across the workspace using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use when you need to rename a function, type, variable, method or field
+- Prefer it over editing every reference by hand since it only changes references to that symbol
+
+HOW TO USE:
+- Provide the file path and the line of the symbol, optionally with its column or name
+- Or provide the file path and the symbol name to use its first occurrence in the file
+- Provide the new name of the symbol
+
+FEATURES:
+- Changes every file that references the symbol in a single step
+- Shows the changes of every file for approval before writing them
+- Reports the diagnostics of the project after the rename
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- Renames that create, move or delete files are not supported
+- Every file it changes must have been read with the View tool since it last changed
+
+TIPS:
+- Use the references tool first if you want to review where the symbol is used`
+)
+
+func NewRenameTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service, workingDir string) BaseTool {
+	return &renameTool{
+		editor: workspaceEditor{
+			lspClients:  lspClients,
+			permissions: permissions,
+			files:       files,
+			workingDir:  workingDir,
+		},
+	}
+}
+
+func (r *renameTool) Name() string {
+	return RenameToolName
+}
+
+func (r *renameTool) Info() ToolInfo {
+	parameters := navigationParameters()
+	parameters["file_path"] = map[string]any{
+		"type":        "string",
+		"description": "The path to the file containing the symbol",
+	}
+	parameters["new_name"] = map[string]any{
+		"type":        "string",
+		"description": "The new name of the symbol",
+	}
+	return ToolInfo{
+		Name:        RenameToolName,
+		Description: renameDescription,
+		Parameters:  parameters,
+		Required:    []string{"file_path", "new_name"},
+	}
+}
+
+func (r *renameTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RenameParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+	if params.NewName == "" {
+		return NewTextErrorResponse("new_name is required"), nil
+	}
+
+	target, err := resolveNavigationTarget(ctx, r.editor.lspClients, r.editor.workingDir, NavigationParams{
+		FilePath: params.FilePath,
+		Line:     params.Line,
+		Column:   params.Column,
+		Symbol:   params.Symbol,
+	})
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var lastErr error
+	for _, client := range target.clients {
+		// Servers reject positions that cannot be renamed, such as keywords,
+		// with a better message than the rename itself. Servers without
+		// support for it fail here too, so the rename is still attempted.
+		prepared, prepareErr := client.PrepareRename(ctx, protocol.PrepareRenameParams{
+			TextDocumentPositionParams: target.positionParams(),
+		})
+		if prepareErr == nil && prepared.Value == nil {
+			lastErr = fmt.Errorf("the symbol at this position cannot be renamed")
+			continue
+		}
+
+		edit, err := client.Rename(ctx, protocol.RenameParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(target.path)},
+			Position:     target.position,
+			NewName:      params.NewName,
+		})
+		if err != nil {
+			lastErr = cmp.Or(prepareErr, err)
+			continue
+		}
+		description := fmt.Sprintf("Rename symbol at %s to %s", newNavigationSnippets(r.editor.workingDir).location(target.path, target.position), params.NewName)
+		return r.editor.apply(ctx, call, RenameToolName, description, edit)
+	}
+	return NewTextErrorResponse(fmt.Sprintf("rename failed: %s", lastErr)), nil
+}
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type SymbolsParams struct {
+	FilePath string `json:"file_path"`
+	Query    string `json:"query"`
+}
+
+type symbolsTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	SymbolsToolName    = "symbols"
+	symbolsDescription = `List the symbols of a file or search symbols in the workspace using the language server.
+
+WHEN TO USE THIS TOOL:
+- Use to get an outline of the types, functions and methods declared in a file
+- Use to find where a type or function is declared when you only know (part of) its name
+
+HOW TO USE:
+- Provide a file path to get the outline of that file
+- Or provide a query to search symbols across the workspace
+
+FEATURES:
+- File outlines are nested, showing methods and fields under their types
+- Every symbol comes with its kind and line numbers
+
+LIMITATIONS:
+- Only works for files handled by a configured LSP server
+- Workspace results are limited to 100 symbols
+- How queries are matched depends on the language server
+
+TIPS:
+- Use the outline to pick the line to pass to the definition, references and hover tools`
+)
+
+func NewSymbolsTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &symbolsTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (s *symbolsTool) Name() string {
+	return SymbolsToolName
+}
+
+func (s *symbolsTool) ReadOnly() bool {
+	return true
+}
+
+func (s *symbolsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        SymbolsToolName,
+		Description: symbolsDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file to outline",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "The symbol name to search for in the workspace (used when no file path is given)",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (s *symbolsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params SymbolsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	switch {
+	case params.FilePath != "":
+		return s.documentSymbols(ctx, params.FilePath)
+	case params.Query != "":
+		return s.workspaceSymbols(ctx, params.Query)
+	default:
+		return NewTextErrorResponse("file_path or query is required"), nil
+	}
+}
+
+func (s *symbolsTool) documentSymbols(ctx context.Context, filePath string) (ToolResponse, error) {
+	path := filePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.workingDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+	}
+	clients, err := clientsForFile(ctx, s.lspClients, path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var output strings.Builder
+	for _, client := range clients {
+		result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(path)},
+		})
+		if err != nil {
+			continue
+		}
+		switch symbols := result.Value.(type) {
+		case []protocol.DocumentSymbol:
+			writeDocumentSymbols(&output, symbols, 0)
+		case []protocol.SymbolInformation:
+			for _, symbol := range symbols {
+				writeSymbolLine(&output, 0, symbol.Kind, symbol.Name, symbol.ContainerName, symbol.Location.Range)
+			}
+		}
+		if output.Len() > 0 {
+			break
+		}
+	}
+	if output.Len() == 0 {
+		return NewTextResponse(fmt.Sprintf("No symbols found in %s", filePath)), nil
+	}
+	return NewTextResponse(output.String()), nil
+}
+
+func (s *symbolsTool) workspaceSymbols(ctx context.Context, query string) (ToolResponse, error) {
+	snippets := newNavigationSnippets(s.workingDir)
+	var (
+		output strings.Builder
+		count  int
+	)
+	for _, client := range sortedClients(s.lspClients) {
+		result, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: query})
+		if err != nil {
+			continue
+		}
+		symbols, err := result.Results()
+		if err != nil {
+			continue
+		}
+		for _, symbol := range symbols {
+			count++
+			if count > maxNavigationResults {
+				continue
+			}
+			loc := symbol.GetLocation()
+			path, err := loc.URI.Path()
+			if err != nil {
+				continue
+			}
+			kind := symbolKind(symbolInfoKind(symbol))
+			fmt.Fprintf(&output, "%s %s %s\n", kind, symbol.GetName(), snippets.location(path, loc.Range.Start))
+		}
+	}
+	if count == 0 {
+		return NewTextResponse(fmt.Sprintf("No symbols found matching %q", query)), nil
+	}
+	if count > maxNavigationResults {
+		fmt.Fprintf(&output, "\n... and %d more symbols\n", count-maxNavigationResults)
+	}
+	return NewTextResponse(output.String()), nil
+}
+
+func writeDocumentSymbols(output *strings.Builder, symbols []protocol.DocumentSymbol, depth int) {
+	for _, symbol := range symbols {
+		writeSymbolLine(output, depth, symbol.Kind, symbol.Name, symbol.Detail, symbol.Range)
+		writeDocumentSymbols(output, symbol.Children, depth+1)
+	}
+}
+
+func writeSymbolLine(output *strings.Builder, depth int, kind protocol.SymbolKind, name, detail string, rng protocol.Range) {
+	output.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(output, "%s %s", symbolKind(kind), name)
+	if detail != "" {
+		fmt.Fprintf(output, " %s", detail)
+	}
+	if rng.Start.Line == rng.End.Line {
+		fmt.Fprintf(output, " (line %d)\n", rng.Start.Line+1)
+	} else {
+		fmt.Fprintf(output, " (lines %d-%d)\n", rng.Start.Line+1, rng.End.Line+1)
+	}
+}
+
+func symbolInfoKind(symbol protocol.WorkspaceSymbolResult) protocol.SymbolKind {
+	switch v := symbol.(type) {
+	case *protocol.WorkspaceSymbol:
+		return v.Kind
+	case *protocol.SymbolInformation:
+		return v.Kind
+	}
+	return 0
+}
+
+func symbolKind(kind protocol.SymbolKind) string {
+	if name, ok := protocol.TableKindMap[kind]; ok {
+		return name
+	}
+	return "Symbol"
+}
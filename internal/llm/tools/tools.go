@@ -30,6 +30,8 @@ const (
 type ToolResponse struct {
 	Type     toolResponseType `json:"type"`
 	Content  string           `json:"content"`
+	Data     []byte           `json:"data,omitempty"`
+	MIMEType string           `json:"mime_type,omitempty"`
 	Metadata string           `json:"metadata,omitempty"`
 	IsError  bool             `json:"is_error"`
 }
@@ -41,6 +43,17 @@ func NewTextResponse(content string) ToolResponse {
 	}
 }
 
+// NewImageResponse returns an image for the model, with content describing
+// it.
+func NewImageResponse(content string, data []byte, mimeType string) ToolResponse {
+	return ToolResponse{
+		Type:     ToolResponseTypeImage,
+		Content:  content,
+		Data:     data,
+		MIMEType: mimeType,
+	}
+}
+
 func WithResponseMetadata(response ToolResponse, metadata any) ToolResponse {
 	if metadata != nil {
 		metadataBytes, err := json.Marshal(metadata)
@@ -72,6 +85,18 @@ type BaseTool interface {
 	Run(ctx context.Context, params ToolCall) (ToolResponse, error)
 }
 
+// ReadOnlyTool is implemented by tools that never modify the workspace. Calls
+// to read-only tools may be executed concurrently within a single turn.
+type ReadOnlyTool interface {
+	ReadOnly() bool
+}
+
+// IsReadOnly reports whether the given tool is safe to run concurrently.
+func IsReadOnly(tool BaseTool) bool {
+	t, ok := tool.(ReadOnlyTool)
+	return ok && t.ReadOnly()
+}
+
 func GetContextValues(ctx context.Context) (string, string) {
 	sessionID := ctx.Value(SessionIDContextKey)
 	messageID := ctx.Value(MessageIDContextKey)
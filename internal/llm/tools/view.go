@@ -28,9 +28,10 @@ type ViewPermissionsParams struct {
 }
 
 type viewTool struct {
-	lspClients  map[string]*lsp.Client
-	workingDir  string
-	permissions permission.Service
+	lspClients     map[string]*lsp.Client
+	workingDir     string
+	permissions    permission.Service
+	supportsImages func() bool
 }
 
 type ViewResponseMetadata struct {
@@ -62,13 +63,15 @@ FEATURES:
 - Handles large files by limiting the number of lines read
 - Automatically truncates very long lines for better display
 - Suggests similar file names when the requested file isn't found
+- Shows images to models that support them, downscaling large ones
 
 LIMITATIONS:
 - Maximum file size is 250KB
 - Default reading limit is 2000 lines
 - Lines longer than 2000 characters are truncated
-- Cannot display binary files or images
-- Images can be identified but not displayed
+- Cannot display binary files
+- Images (PNG, JPEG, GIF, WebP and BMP) are only shown to models that support images
+- Maximum image size is 20MB
 
 WINDOWS NOTES:
 - Handles both Windows (CRLF) and Unix (LF) line endings automatically
@@ -81,11 +84,14 @@ TIPS:
 - When viewing large files, use the offset parameter to read specific sections`
 )
 
-func NewViewTool(lspClients map[string]*lsp.Client, permissions permission.Service, workingDir string) BaseTool {
+// NewViewTool returns the view tool. supportsImages reports whether the
+// current model accepts images, since the model can change between calls.
+func NewViewTool(lspClients map[string]*lsp.Client, permissions permission.Service, workingDir string, supportsImages func() bool) BaseTool {
 	return &viewTool{
-		lspClients:  lspClients,
-		workingDir:  workingDir,
-		permissions: permissions,
+		lspClients:     lspClients,
+		workingDir:     workingDir,
+		permissions:    permissions,
+		supportsImages: supportsImages,
 	}
 }
 
@@ -93,6 +99,10 @@ func (v *viewTool) Name() string {
 	return ViewToolName
 }
 
+func (v *viewTool) ReadOnly() bool {
+	return true
+}
+
 func (v *viewTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        ViewToolName,
@@ -151,7 +161,7 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 			return ToolResponse{}, fmt.Errorf("session ID and message ID are required for accessing files outside working directory")
 		}
 
-		granted := v.permissions.Request(
+		permErr := v.permissions.Authorize(
 			permission.CreatePermissionRequest{
 				SessionID:   sessionID,
 				Path:        absFilePath,
@@ -163,8 +173,8 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 			},
 		)
 
-		if !granted {
-			return ToolResponse{}, permission.ErrorPermissionDenied
+		if permErr != nil {
+			return ToolResponse{}, permErr
 		}
 	}
 
@@ -205,6 +215,11 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
 	}
 
+	// Check if it's an image file
+	if isImage, imageType := isImageFile(filePath); isImage {
+		return v.viewImage(filePath, imageType, fileInfo.Size())
+	}
+
 	// Check file size
 	if fileInfo.Size() > MaxReadSize {
 		return NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
@@ -216,13 +231,6 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		params.Limit = DefaultReadLimit
 	}
 
-	// Check if it's an image file
-	isImage, imageType := isImageFile(filePath)
-	// TODO: handle images
-	if isImage {
-		return NewTextErrorResponse(fmt.Sprintf("This is an image file of type: %s\n", imageType)), nil
-	}
-
 	// Read the file content
 	content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit)
 	isValidUt8 := utf8.ValidString(content)
@@ -331,6 +339,23 @@ func readTextFile(filePath string, offset, limit int) (string, int, error) {
 	return strings.Join(lines, "\n"), lineCount, nil
 }
 
+// viewImage returns the image at filePath when the model supports images.
+func (v *viewTool) viewImage(filePath, imageType string, size int64) (ToolResponse, error) {
+	if v.supportsImages == nil || !v.supportsImages() {
+		return NewTextErrorResponse(fmt.Sprintf("This is an image file of type: %s\nThe current model does not support images.", imageType)), nil
+	}
+	if size > MaxImageReadSize {
+		return NewTextErrorResponse(fmt.Sprintf("Image is too large (%d bytes). Maximum size is %d bytes",
+			size, MaxImageReadSize)), nil
+	}
+	img, err := loadImage(filePath)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to load image: %s", err)), nil
+	}
+	recordFileRead(filePath)
+	return NewImageResponse(img.description(filePath), img.data, img.mimeType), nil
+}
+
 func isImageFile(filePath string) (bool, string) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
@@ -342,8 +367,6 @@ func isImageFile(filePath string) (bool, string) {
 		return true, "GIF"
 	case ".bmp":
 		return true, "BMP"
-	case ".svg":
-		return true, "SVG"
 	case ".webp":
 		return true, "WebP"
 	default:
@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+// WorkspaceEditFile is the change a workspace edit makes to one file.
+type WorkspaceEditFile struct {
+	FilePath   string `json:"file_path"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+	Additions  int    `json:"additions"`
+	Removals   int    `json:"removals"`
+}
+
+type WorkspaceEditPermissionsParams struct {
+	Files []WorkspaceEditFile `json:"files"`
+}
+
+type WorkspaceEditResponseMetadata struct {
+	Files     []WorkspaceEditFile `json:"files"`
+	Additions int                 `json:"additions"`
+	Removals  int                 `json:"removals"`
+}
+
+// workspaceEditor applies the workspace edits returned by language servers
+// the same way the edit tools change files: after asking for permission, and
+// recording every file in the history.
+type workspaceEditor struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+	workingDir  string
+}
+
+// fileChange is a file changed by a workspace edit, with the content as it
+// is on disk.
+type fileChange struct {
+	path       string
+	oldContent string
+	newContent string
+}
+
+// computeWorkspaceEdit applies edit in memory and returns the files it
+// changes, sorted by path.
+func computeWorkspaceEdit(edit protocol.WorkspaceEdit) ([]fileChange, error) {
+	edits := make(map[string][]protocol.TextEdit)
+	for uri, textEdits := range edit.Changes {
+		path, err := uri.Path()
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI: %w", err)
+		}
+		edits[path] = append(edits[path], textEdits...)
+	}
+	for _, change := range edit.DocumentChanges {
+		if change.TextDocumentEdit == nil {
+			return nil, fmt.Errorf("workspace edits that create, rename or delete files are not supported")
+		}
+		path, err := change.TextDocumentEdit.TextDocument.URI.Path()
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI: %w", err)
+		}
+		for _, e := range change.TextDocumentEdit.Edits {
+			textEdit, err := e.AsTextEdit()
+			if err != nil {
+				return nil, fmt.Errorf("invalid edit type: %w", err)
+			}
+			edits[path] = append(edits[path], textEdit)
+		}
+	}
+
+	changes := make([]fileChange, 0, len(edits))
+	for path, textEdits := range edits {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		newContent, err := util.ApplyTextEdits(string(content), textEdits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply edits to %s: %w", path, err)
+		}
+		if newContent == string(content) {
+			continue
+		}
+		changes = append(changes, fileChange{path: path, oldContent: string(content), newContent: newContent})
+	}
+	slices.SortFunc(changes, func(a, b fileChange) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return changes, nil
+}
+
+// apply writes the changes of edit after asking for permission, and reports
+// a diff of every changed file in the response metadata.
+func (w *workspaceEditor) apply(ctx context.Context, call ToolCall, toolName, description string, edit protocol.WorkspaceEdit) (ToolResponse, error) {
+	changes, err := computeWorkspaceEdit(edit)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(changes) == 0 {
+		return NewTextErrorResponse("no changes made - the language server returned no edits"), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for editing files")
+	}
+
+	// Like the edit tools, only files read since they last changed are
+	// edited.
+	var unread []string
+	for _, change := range changes {
+		lastRead := getLastReadTime(change.path)
+		if lastRead.IsZero() {
+			unread = append(unread, change.path)
+			continue
+		}
+		fileInfo, err := os.Stat(change.path)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+		}
+		if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+			return NewTextErrorResponse(
+				fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+					change.path, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+				)), nil
+		}
+	}
+	if len(unread) > 0 {
+		return NewTextErrorResponse(fmt.Sprintf("you must read the files before editing them. Use the View tool first on: %s", strings.Join(unread, ", "))), nil
+	}
+
+	meta := WorkspaceEditResponseMetadata{Files: make([]WorkspaceEditFile, 0, len(changes))}
+	for _, change := range changes {
+		oldContent, _ := fsext.ToUnixLineEndings(change.oldContent)
+		newContent, _ := fsext.ToUnixLineEndings(change.newContent)
+		_, additions, removals := diff.GenerateDiff(oldContent, newContent, strings.TrimPrefix(change.path, w.workingDir))
+		meta.Files = append(meta.Files, WorkspaceEditFile{
+			FilePath:   change.path,
+			OldContent: oldContent,
+			NewContent: newContent,
+			Additions:  additions,
+			Removals:   removals,
+		})
+		meta.Additions += additions
+		meta.Removals += removals
+	}
+
+	// Edits outside of the working directory are asked for with the first
+	// such file, so that a project grant does not cover them.
+	path := w.workingDir
+	for _, change := range changes {
+		if p := fsext.PathOrPrefix(change.path, w.workingDir); p != w.workingDir {
+			path = p
+			break
+		}
+	}
+	if err := w.permissions.Authorize(permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        path,
+		ToolCallID:  call.ID,
+		ToolName:    toolName,
+		Action:      "write",
+		Description: description,
+		Params:      WorkspaceEditPermissionsParams{Files: meta.Files},
+	}); err != nil {
+		return ToolResponse{}, err
+	}
+
+	for i, change := range changes {
+		if err := os.WriteFile(change.path, []byte(change.newContent), 0o644); err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+		}
+		if err := w.recordHistory(ctx, sessionID, change.path, meta.Files[i].OldContent, meta.Files[i].NewContent); err != nil {
+			return ToolResponse{}, err
+		}
+		recordFileWrite(change.path)
+		recordFileRead(change.path)
+		w.notifyChange(ctx, change.path)
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "<result>\n%s\n", description)
+	for _, file := range meta.Files {
+		fmt.Fprintf(&output, "- %s (+%d -%d)\n", file.FilePath, file.Additions, file.Removals)
+	}
+	output.WriteString("</result>\n")
+
+	waitForLspDiagnostics(ctx, changes[0].path, w.lspClients)
+	output.WriteString(getDiagnostics(changes[0].path, w.lspClients))
+
+	return WithResponseMetadata(NewTextResponse(output.String()), meta), nil
+}
+
+// recordHistory stores the new version of a file, keeping the content it had
+// before when it was changed outside of Crush.
+func (w *workspaceEditor) recordHistory(ctx context.Context, sessionID, path, oldContent, newContent string) error {
+	file, err := w.files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil {
+		_, err = w.files.Create(ctx, sessionID, path, oldContent)
+		if err != nil {
+			return fmt.Errorf("error creating file history: %w", err)
+		}
+	}
+	if file.Content != oldContent {
+		// User manually changed the content, store an intermediate version
+		_, err = w.files.CreateVersion(ctx, sessionID, path, oldContent)
+		if err != nil {
+			slog.Debug("Error creating file history version", "error", err)
+		}
+	}
+	_, err = w.files.CreateVersion(ctx, sessionID, path, newContent)
+	if err != nil {
+		slog.Debug("Error creating file history version", "error", err)
+	}
+	return nil
+}
+
+// notifyChange tells the LSP clients that have the file open about its new
+// content.
+func (w *workspaceEditor) notifyChange(ctx context.Context, path string) {
+	for _, client := range w.lspClients {
+		if !client.IsFileOpen(path) {
+			continue
+		}
+		if err := client.NotifyChange(ctx, path); err != nil {
+			slog.Debug("Failed to notify LSP of file change", "path", path, "error", err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func rangeAt(line, start, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: line, Character: start},
+		End:   protocol.Position{Line: line, Character: end},
+	}
+}
+
+func TestComputeWorkspaceEdit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	require.NoError(t, os.WriteFile(a, []byte("func Old() {}\r\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("x := Old()\ny := Old()\n"), 0o644))
+
+	changes, err := computeWorkspaceEdit(protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(b): {
+				{Range: rangeAt(0, 5, 8), NewText: "New"},
+				{Range: rangeAt(1, 5, 8), NewText: "New"},
+			},
+		},
+		DocumentChanges: []protocol.DocumentChange{{
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(a)},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{
+					{Value: protocol.TextEdit{Range: rangeAt(0, 5, 8), NewText: "New"}},
+				},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, a, changes[0].path)
+	require.Equal(t, "func New() {}\r\n", changes[0].newContent)
+	require.Equal(t, b, changes[1].path)
+	require.Equal(t, "x := New()\ny := New()\n", changes[1].newContent)
+
+	// Nothing is written until the edit is applied.
+	content, err := os.ReadFile(b)
+	require.NoError(t, err)
+	require.Equal(t, "x := Old()\ny := Old()\n", string(content))
+
+	_, err = computeWorkspaceEdit(protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{{
+			DeleteFile: &protocol.DeleteFile{URI: protocol.URIFromPath(a)},
+		}},
+	})
+	require.Error(t, err)
+}
+
+func TestWorkspaceEditorApply(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	sess, err := session.NewService(q, conn).Create(ctx, "Rename")
+	require.NoError(t, err)
+	ctx = context.WithValue(ctx, SessionIDContextKey, sess.ID)
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("var old = 1\n"), 0o644))
+	recordFileRead(path)
+
+	files := history.NewService(q, conn)
+	editor := workspaceEditor{
+		permissions: permission.NewPermissionService(nil, dir, true, nil, nil),
+		files:       files,
+		workingDir:  dir,
+	}
+	resp, err := editor.apply(ctx, ToolCall{ID: "call"}, RenameToolName, "Rename old to renamed", protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(path): {{Range: rangeAt(0, 4, 7), NewText: "renamed"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+	require.Contains(t, resp.Content, "main.go (+1 -1)")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "var renamed = 1\n", string(content))
+
+	latest, err := files.GetByPathAndSession(ctx, path, sess.ID)
+	require.NoError(t, err)
+	require.Equal(t, "var renamed = 1\n", latest.Content)
+}
+
+func TestWorkspaceEditorApplyDeniedPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	sess, err := session.NewService(q, conn).Create(ctx, "Rename")
+	require.NoError(t, err)
+	ctx = context.WithValue(ctx, SessionIDContextKey, sess.ID)
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "main.go")
+	denied := filepath.Join(dir, "vendor", "lib.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(denied), 0o755))
+	require.NoError(t, os.WriteFile(allowed, []byte("var old = 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(denied, []byte("var old = 1\n"), 0o644))
+	recordFileRead(allowed)
+	recordFileRead(denied)
+
+	rules := []config.PermissionRule{
+		{Name: "no-vendor", Decision: config.PermissionDeny, Paths: []string{"vendor/**"}},
+	}
+	editor := workspaceEditor{
+		permissions: permission.NewPermissionService(nil, dir, true, nil, rules),
+		files:       history.NewService(q, conn),
+		workingDir:  dir,
+	}
+	_, err = editor.apply(ctx, ToolCall{ID: "call"}, RenameToolName, "Rename old to renamed", protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(allowed): {{Range: rangeAt(0, 4, 7), NewText: "renamed"}},
+			protocol.URIFromPath(denied):  {{Range: rangeAt(0, 4, 7), NewText: "renamed"}},
+		},
+	})
+	var policyErr *permission.PolicyDeniedError
+	require.ErrorAs(t, err, &policyErr)
+
+	// None of the files are written.
+	for _, path := range []string{allowed, denied} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "var old = 1\n", string(content))
+	}
+}
+
+func TestWorkspaceEditorApplyUnreadFile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+
+	dir := t.TempDir()
+	read := filepath.Join(dir, "main.go")
+	unread := filepath.Join(dir, "lib.go")
+	require.NoError(t, os.WriteFile(read, []byte("var old = 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(unread, []byte("var old = 1\n"), 0o644))
+	recordFileRead(read)
+
+	editor := workspaceEditor{
+		permissions: permission.NewPermissionService(nil, dir, true, nil, nil),
+		workingDir:  dir,
+	}
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			protocol.URIFromPath(read):   {{Range: rangeAt(0, 4, 7), NewText: "renamed"}},
+			protocol.URIFromPath(unread): {{Range: rangeAt(0, 4, 7), NewText: "renamed"}},
+		},
+	}
+	resp, err := editor.apply(ctx, ToolCall{ID: "call"}, RenameToolName, "Rename old to renamed", edit)
+	require.NoError(t, err)
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "you must read the files")
+	require.Contains(t, resp.Content, unread)
+	require.NotContains(t, resp.Content, read)
+
+	// A file changed since it was read is not edited either.
+	recordFileRead(unread)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(read, future, future))
+	resp, err = editor.apply(ctx, ToolCall{ID: "call"}, RenameToolName, "Rename old to renamed", edit)
+	require.NoError(t, err)
+	require.True(t, resp.IsError)
+	require.Contains(t, resp.Content, "has been modified since it was last read")
+
+	for _, path := range []string{read, unread} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "var old = 1\n", string(content))
+	}
+}
@@ -167,13 +167,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, fmt.Errorf("session_id and message_id are required")
 	}
 
+	content, formatted := formatOnWrite(ctx, filePath, params.Content, w.lspClients)
 	diff, additions, removals := diff.GenerateDiff(
 		oldContent,
-		params.Content,
+		content,
 		strings.TrimPrefix(filePath, w.workingDir),
 	)
 
-	p := w.permissions.Request(
+	permErr := w.permissions.Authorize(
 		permission.CreatePermissionRequest{
 			SessionID:   sessionID,
 			Path:        fsext.PathOrPrefix(filePath, w.workingDir),
@@ -184,15 +185,15 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			Params: WritePermissionsParams{
 				FilePath:   filePath,
 				OldContent: oldContent,
-				NewContent: params.Content,
+				NewContent: content,
 			},
 		},
 	)
-	if !p {
-		return ToolResponse{}, permission.ErrorPermissionDenied
+	if permErr != nil {
+		return ToolResponse{}, permErr
 	}
 
-	err = os.WriteFile(filePath, []byte(params.Content), 0o644)
+	err = os.WriteFile(filePath, []byte(content), 0o644)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error writing file: %w", err)
 	}
@@ -200,7 +201,11 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	// Check if file exists in history
 	file, err := w.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		_, err = w.files.Create(ctx, sessionID, filePath, oldContent)
+		if fileInfo == nil {
+			_, err = w.files.CreateNew(ctx, sessionID, filePath)
+		} else {
+			_, err = w.files.Create(ctx, sessionID, filePath, oldContent)
+		}
 		if err != nil {
 			// Log error but don't fail the operation
 			return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
@@ -214,7 +219,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 	}
 	// Store the new version
-	_, err = w.files.CreateVersion(ctx, sessionID, filePath, params.Content)
+	_, err = w.files.CreateVersion(ctx, sessionID, filePath, content)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -223,7 +228,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	recordFileRead(filePath)
 	waitForLspDiagnostics(ctx, filePath, w.lspClients)
 
-	result := fmt.Sprintf("File successfully written: %s", filePath)
+	result := withFormattedNote(fmt.Sprintf("File successfully written: %s", filePath), formatted)
 	result = fmt.Sprintf("<result>\n%s\n</result>", result)
 	result += getDiagnostics(filePath, w.lspClients)
 	return WithResponseMetadata(NewTextResponse(result),
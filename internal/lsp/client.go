@@ -34,6 +34,9 @@ type Client struct {
 	// File types this LSP server handles (e.g., .go, .rs, .py)
 	fileTypes []string
 
+	// Whether files are formatted through this server before they are saved
+	formatOnWrite bool
+
 	// Diagnostic change callback
 	onDiagnosticsChanged func(name string, count int)
 
@@ -95,6 +98,7 @@ func NewClient(ctx context.Context, name string, config config.LSPConfig) (*Clie
 		Cmd:                   cmd,
 		name:                  name,
 		fileTypes:             config.FileTypes,
+		formatOnWrite:         config.FormatOnWrite,
 		stdin:                 stdin,
 		stdout:                bufio.NewReader(stdout),
 		stderr:                stderr,
@@ -195,6 +199,14 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 								ValueSet: []protocol.CodeActionKind{},
 							},
 						},
+						IsPreferredSupport: true,
+						DataSupport:        true,
+						ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+							Properties: []string{"edit"},
+						},
+					},
+					Rename: &protocol.RenameClientCapabilities{
+						PrepareSupport: true,
 					},
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
 						VersionSupport: true,
@@ -0,0 +1,186 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+)
+
+// FormatOnWrite reports whether files should be formatted through this
+// server before they are saved.
+func (c *Client) FormatOnWrite() bool {
+	return c.formatOnWrite
+}
+
+// FormatContent returns content with its imports organized and formatted by
+// the server, as if it was the content of the file at path. The file is not
+// written, and the server is synced back to the file on disk afterwards.
+func (c *Client) FormatContent(ctx context.Context, path, content string) (string, error) {
+	if !c.HandlesFile(path) {
+		return content, nil
+	}
+
+	uri := protocol.URIFromPath(path)
+	if err := c.setContent(ctx, path, content); err != nil {
+		return content, err
+	}
+	defer c.syncFromDisk(ctx, path)
+
+	organized, err := c.organizeImports(ctx, uri, content)
+	if err != nil {
+		slog.Debug("Failed to organize imports", "file", path, "error", err)
+	} else if organized != content {
+		content = organized
+		if err := c.setContent(ctx, path, content); err != nil {
+			return content, err
+		}
+	}
+
+	edits, err := c.Formatting(ctx, protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Options:      formattingOptions(content),
+	})
+	if err != nil {
+		return content, fmt.Errorf("failed to format file: %w", err)
+	}
+	return util.ApplyTextEdits(content, edits)
+}
+
+// organizeImports applies the first organize imports action the server
+// offers for the document.
+func (c *Client) organizeImports(ctx context.Context, uri protocol.DocumentURI, content string) (string, error) {
+	actions, err := c.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        documentRange(content),
+		Context: protocol.CodeActionContext{
+			Diagnostics: []protocol.Diagnostic{},
+			Only:        []protocol.CodeActionKind{protocol.SourceOrganizeImports},
+		},
+	})
+	if err != nil {
+		return content, err
+	}
+
+	for _, item := range actions {
+		action, ok := item.Value.(protocol.CodeAction)
+		if !ok || !strings.HasPrefix(string(action.Kind), string(protocol.SourceOrganizeImports)) {
+			continue
+		}
+		if action.Edit == nil && action.Data != nil {
+			if action, err = c.ResolveCodeAction(ctx, action); err != nil {
+				return content, err
+			}
+		}
+		if action.Edit == nil {
+			continue
+		}
+		return util.ApplyTextEdits(content, documentEdits(*action.Edit, uri))
+	}
+	return content, nil
+}
+
+// documentEdits returns the edits a workspace edit makes to a document.
+func documentEdits(edit protocol.WorkspaceEdit, uri protocol.DocumentURI) []protocol.TextEdit {
+	edits := edit.Changes[uri]
+	for _, change := range edit.DocumentChanges {
+		if change.TextDocumentEdit == nil || change.TextDocumentEdit.TextDocument.URI != uri {
+			continue
+		}
+		for _, e := range change.TextDocumentEdit.Edits {
+			if textEdit, err := e.AsTextEdit(); err == nil {
+				edits = append(edits, textEdit)
+			}
+		}
+	}
+	return edits
+}
+
+// setContent makes the server see content as the content of the file at
+// path.
+func (c *Client) setContent(ctx context.Context, path, content string) error {
+	uri := protocol.URIFromPath(path)
+
+	c.openFilesMu.Lock()
+	fileInfo, isOpen := c.openFiles[string(uri)]
+	if !isOpen {
+		c.openFilesMu.Unlock()
+		if err := c.DidOpen(ctx, protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{
+				URI:        uri,
+				LanguageID: DetectLanguageID(string(uri)),
+				Version:    1,
+				Text:       content,
+			},
+		}); err != nil {
+			return err
+		}
+		c.openFilesMu.Lock()
+		c.openFiles[string(uri)] = &OpenFileInfo{Version: 1, URI: uri}
+		c.openFilesMu.Unlock()
+		return nil
+	}
+	fileInfo.Version++
+	version := fileInfo.Version
+	c.openFilesMu.Unlock()
+
+	return c.DidChange(ctx, protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+			Version:                version,
+		},
+		ContentChanges: []protocol.TextDocumentContentChangeEvent{
+			{Value: protocol.TextDocumentContentChangeWholeDocument{Text: content}},
+		},
+	})
+}
+
+// syncFromDisk makes the server see the file at path as it is on disk,
+// closing it when it does not exist.
+func (c *Client) syncFromDisk(ctx context.Context, path string) {
+	var err error
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		err = c.CloseFile(ctx, path)
+	} else {
+		err = c.NotifyChange(ctx, path)
+	}
+	if err != nil {
+		slog.Debug("Failed to sync file with LSP", "file", path, "error", err)
+	}
+}
+
+// documentRange returns the range covering all of content.
+func documentRange(content string) protocol.Range {
+	lines := strings.Split(content, "\n")
+	last := lines[len(lines)-1]
+	return protocol.Range{
+		End: protocol.Position{
+			Line:      uint32(len(lines) - 1),
+			Character: uint32(len(utf16.Encode([]rune(last)))),
+		},
+	}
+}
+
+// formattingOptions guesses the indentation of content, for servers that do
+// not read it from the project configuration.
+func formattingOptions(content string) protocol.FormattingOptions {
+	insertSpaces := true
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			insertSpaces = false
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			break
+		}
+	}
+	return protocol.FormattingOptions{
+		TabSize:      4,
+		InsertSpaces: insertSpaces,
+	}
+}
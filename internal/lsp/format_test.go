@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentRange(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, protocol.Position{Line: 2, Character: 0}, documentRange("a\nb\n").End)
+	require.Equal(t, protocol.Position{Line: 1, Character: 3}, documentRange("a\nx😀").End)
+}
+
+func TestFormattingOptions(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, formattingOptions("func main() {\n\tprintln()\n}\n").InsertSpaces)
+	require.True(t, formattingOptions("def main():\n    print()\n").InsertSpaces)
+	require.True(t, formattingOptions("").InsertSpaces)
+}
+
+func TestDocumentEdits(t *testing.T) {
+	t.Parallel()
+
+	uri := protocol.URIFromPath("/tmp/main.go")
+	other := protocol.URIFromPath("/tmp/other.go")
+	edit := protocol.TextEdit{NewText: "import \"fmt\"\n"}
+	edits := documentEdits(protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			uri:   {edit},
+			other: {edit},
+		},
+		DocumentChanges: []protocol.DocumentChange{{
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{Value: edit}},
+			},
+		}},
+	}, uri)
+	require.Len(t, edits, 2)
+}
@@ -2,7 +2,9 @@ package message
 
 import (
 	"encoding/base64"
+	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
@@ -82,6 +84,17 @@ func (bc BinaryContent) String(p catwalk.InferenceProvider) string {
 	return base64Encoded
 }
 
+// IsImage reports whether the content is an image, as opposed to a text
+// attachment such as an MCP resource.
+func (bc BinaryContent) IsImage() bool {
+	return strings.HasPrefix(bc.MIMEType, "image/")
+}
+
+// Text returns a text attachment as it is sent to the model.
+func (bc BinaryContent) Text() string {
+	return fmt.Sprintf("<attachment path=%q>\n%s\n</attachment>", bc.Path, bc.Data)
+}
+
 func (BinaryContent) isPart() {}
 
 type ToolCall struct {
@@ -98,12 +111,22 @@ type ToolResult struct {
 	ToolCallID string `json:"tool_call_id"`
 	Name       string `json:"name"`
 	Content    string `json:"content"`
+	Data       []byte `json:"data,omitempty"`
+	MIMEType   string `json:"mime_type,omitempty"`
 	Metadata   string `json:"metadata"`
 	IsError    bool   `json:"is_error"`
 }
 
 func (ToolResult) isPart() {}
 
+// Image returns the image returned by the tool, if any.
+func (tr ToolResult) Image() (BinaryContent, bool) {
+	if len(tr.Data) == 0 {
+		return BinaryContent{}, false
+	}
+	return BinaryContent{MIMEType: tr.MIMEType, Data: tr.Data}, true
+}
+
 type Finish struct {
 	Reason  FinishReason `json:"reason"`
 	Time    int64        `json:"time"`
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/db"
@@ -23,6 +24,10 @@ type Service interface {
 	pubsub.Suscriber[Message]
 	Create(ctx context.Context, sessionID string, params CreateMessageParams) (Message, error)
 	Update(ctx context.Context, message Message) error
+	// Stream publishes a delta of a message being streamed and persists the
+	// message in batches.
+	Stream(ctx context.Context, message Message, delta Delta) error
+	SubscribeDeltas(ctx context.Context) <-chan pubsub.Event[Delta]
 	Get(ctx context.Context, id string) (Message, error)
 	List(ctx context.Context, sessionID string) ([]Message, error)
 	Delete(ctx context.Context, id string) error
@@ -31,13 +36,20 @@ type Service interface {
 
 type service struct {
 	*pubsub.Broker[Message]
-	q db.Querier
+	q      db.Querier
+	deltas *pubsub.Broker[Delta]
+
+	// streamMu guards the streamed messages and orders their writes.
+	streamMu  sync.Mutex
+	streaming map[string]*streamingMessage
 }
 
 func NewService(q db.Querier) Service {
 	return &service{
-		Broker: pubsub.NewBroker[Message](),
-		q:      q,
+		Broker:    pubsub.NewBroker[Message](),
+		q:         q,
+		deltas:    pubsub.NewBroker[Delta](),
+		streaming: make(map[string]*streamingMessage),
 	}
 }
 
@@ -46,6 +58,9 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
+	s.streamMu.Lock()
+	s.discardStreaming(message.ID)
+	s.streamMu.Unlock()
 	err = s.q.DeleteMessage(ctx, message.ID)
 	if err != nil {
 		return err
@@ -100,6 +115,13 @@ func (s *service) DeleteSessionMessages(ctx context.Context, sessionID string) e
 }
 
 func (s *service) Update(ctx context.Context, message Message) error {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	s.discardStreaming(message.ID)
+	return s.update(ctx, message)
+}
+
+func (s *service) update(ctx context.Context, message Message) error {
 	parts, err := marshallParts(message.Parts)
 	if err != nil {
 		return err
@@ -280,3 +302,61 @@ func unmarshallParts(data []byte) ([]ContentPart, error) {
 
 	return parts, nil
 }
+
+// messageJSON is the wire representation of a Message. Parts are encoded the
+// same way they are stored in the database.
+type messageJSON struct {
+	ID        string          `json:"id"`
+	Role      MessageRole     `json:"role"`
+	SessionID string          `json:"session_id"`
+	Parts     json.RawMessage `json:"parts"`
+	Model     string          `json:"model,omitempty"`
+	Provider  string          `json:"provider,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Message) MarshalJSON() ([]byte, error) {
+	parts, err := marshallParts(m.Parts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(messageJSON{
+		ID:        m.ID,
+		Role:      m.Role,
+		SessionID: m.SessionID,
+		Parts:     parts,
+		Model:     m.Model,
+		Provider:  m.Provider,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw messageJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parts := []ContentPart{}
+	if len(raw.Parts) > 0 && string(raw.Parts) != "null" {
+		var err error
+		parts, err = unmarshallParts(raw.Parts)
+		if err != nil {
+			return err
+		}
+	}
+	*m = Message{
+		ID:        raw.ID,
+		Role:      raw.Role,
+		SessionID: raw.SessionID,
+		Parts:     parts,
+		Model:     raw.Model,
+		Provider:  raw.Provider,
+		CreatedAt: raw.CreatedAt,
+		UpdatedAt: raw.UpdatedAt,
+	}
+	return nil
+}
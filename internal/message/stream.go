@@ -0,0 +1,170 @@
+package message
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+const (
+	// streamFlushInterval is the longest a streamed delta stays in memory
+	// before the message is persisted.
+	streamFlushInterval = 250 * time.Millisecond
+	// streamFlushSize is the size of the streamed deltas after which the
+	// message is persisted right away.
+	streamFlushSize = 4096
+)
+
+type DeltaType string
+
+const (
+	ContentDelta       DeltaType = "content"
+	ReasoningDelta     DeltaType = "reasoning"
+	SignatureDelta     DeltaType = "signature"
+	ToolCallInputDelta DeltaType = "tool_call_input"
+)
+
+// Delta is text appended to a field of a message while it streams: its
+// content, its reasoning, its reasoning signature or the input of one of its
+// tool calls.
+type Delta struct {
+	MessageID  string    `json:"message_id"`
+	SessionID  string    `json:"session_id"`
+	Type       DeltaType `json:"type"`
+	ToolCallID string    `json:"tool_call_id,omitempty"`
+	// Offset is the length of the field before the delta.
+	Offset  int    `json:"offset"`
+	Content string `json:"content"`
+}
+
+// Since returns the content of the delta past the first n bytes of its field.
+// It returns false when the delta starts after them, as the content in
+// between is missing.
+func (d Delta) Since(n int) (string, bool) {
+	if d.Offset > n {
+		return "", false
+	}
+	if end := d.Offset + len(d.Content); end > n {
+		return d.Content[n-d.Offset:], true
+	}
+	return "", true
+}
+
+// NewDelta returns a delta appending content to a field of the message.
+func (m *Message) NewDelta(typ DeltaType, toolCallID, content string) Delta {
+	return Delta{
+		MessageID:  m.ID,
+		SessionID:  m.SessionID,
+		Type:       typ,
+		ToolCallID: toolCallID,
+		Offset:     m.deltaFieldLen(typ, toolCallID),
+		Content:    content,
+	}
+}
+
+// ApplyDelta appends a delta to the message. It returns false when the delta
+// does not line up with the message, when deltas before it were missed.
+func (m *Message) ApplyDelta(d Delta) bool {
+	content, ok := d.Since(m.deltaFieldLen(d.Type, d.ToolCallID))
+	if !ok {
+		return false
+	}
+	switch d.Type {
+	case ContentDelta:
+		m.FinishThinking()
+		m.AppendContent(content)
+	case ReasoningDelta:
+		m.AppendReasoningContent(content)
+	case SignatureDelta:
+		m.AppendReasoningSignature(content)
+	case ToolCallInputDelta:
+		m.AppendToolCallInput(d.ToolCallID, content)
+	}
+	return true
+}
+
+func (m *Message) deltaFieldLen(typ DeltaType, toolCallID string) int {
+	switch typ {
+	case ContentDelta:
+		return len(m.Content().Text)
+	case ReasoningDelta:
+		return len(m.ReasoningContent().Thinking)
+	case SignatureDelta:
+		return len(m.ReasoningContent().Signature)
+	case ToolCallInputDelta:
+		for _, call := range m.ToolCalls() {
+			if call.ID == toolCallID {
+				return len(call.Input)
+			}
+		}
+	}
+	return 0
+}
+
+// Clone returns a copy of the message that does not share its parts.
+func (m Message) Clone() Message {
+	m.Parts = slices.Clone(m.Parts)
+	return m
+}
+
+// streamingMessage is a message with streamed deltas not persisted yet.
+type streamingMessage struct {
+	message Message
+	size    int
+	timer   *time.Timer
+}
+
+func (s *service) SubscribeDeltas(ctx context.Context) <-chan pubsub.Event[Delta] {
+	return s.deltas.Subscribe(ctx)
+}
+
+// Stream publishes a delta already applied to the message. The message is
+// persisted, and published whole, once the deltas are older than the flush
+// interval or larger than the flush size, or on the next Update.
+func (s *service) Stream(ctx context.Context, message Message, delta Delta) error {
+	s.deltas.Publish(pubsub.UpdatedEvent, delta)
+
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	pending, ok := s.streaming[message.ID]
+	if !ok {
+		pending = &streamingMessage{}
+		pending.timer = time.AfterFunc(streamFlushInterval, func() {
+			s.flushStreaming(message.ID)
+		})
+		s.streaming[message.ID] = pending
+	}
+	pending.message = message.Clone()
+	pending.size += len(delta.Content)
+	if pending.size < streamFlushSize {
+		return nil
+	}
+	s.discardStreaming(message.ID)
+	return s.update(ctx, pending.message)
+}
+
+// flushStreaming persists the deltas of a message left in memory.
+func (s *service) flushStreaming(id string) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	pending, ok := s.streaming[id]
+	if !ok {
+		return
+	}
+	s.discardStreaming(id)
+	if err := s.update(context.Background(), pending.message); err != nil {
+		slog.Error("Failed to persist streamed message", "message", id, "error", err)
+	}
+}
+
+// discardStreaming forgets the deltas of a message left in memory. The stream
+// lock must be held.
+func (s *service) discardStreaming(id string) {
+	if pending, ok := s.streaming[id]; ok {
+		pending.timer.Stop()
+		delete(s.streaming, id)
+	}
+}
@@ -0,0 +1,109 @@
+package message
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+// countingQuerier counts the message updates.
+type countingQuerier struct {
+	db.Querier
+	updates atomic.Int32
+}
+
+func (q *countingQuerier) UpdateMessage(ctx context.Context, arg db.UpdateMessageParams) error {
+	q.updates.Add(1)
+	return q.Querier.UpdateMessage(ctx, arg)
+}
+
+func newStreamTestService(t *testing.T) (*service, *countingQuerier, string) {
+	t.Helper()
+	conn, err := db.Connect(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := &countingQuerier{Querier: db.New(conn)}
+	sess, err := q.CreateSession(t.Context(), db.CreateSessionParams{ID: "s1", Title: "Streaming"})
+	require.NoError(t, err)
+	return NewService(q).(*service), q, sess.ID
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	s, q, sessionID := newStreamTestService(t)
+	deltas := s.SubscribeDeltas(ctx)
+
+	msg, err := s.Create(ctx, sessionID, CreateMessageParams{Role: Assistant})
+	require.NoError(t, err)
+	for _, text := range []string{"Hel", "lo", " world"} {
+		delta := msg.NewDelta(ContentDelta, "", text)
+		require.True(t, msg.ApplyDelta(delta))
+		require.NoError(t, s.Stream(ctx, msg, delta))
+	}
+
+	// The deltas are published right away, the message is persisted later.
+	for _, offset := range []int{0, 3, 5} {
+		event := <-deltas
+		require.Equal(t, msg.ID, event.Payload.MessageID)
+		require.Equal(t, offset, event.Payload.Offset)
+	}
+	require.Zero(t, q.updates.Load())
+	require.Eventually(t, func() bool {
+		stored, err := s.Get(ctx, msg.ID)
+		return err == nil && stored.Content().Text == "Hello world"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int32(1), q.updates.Load())
+
+	// Large deltas are persisted right away.
+	delta := msg.NewDelta(ContentDelta, "", strings.Repeat("a", streamFlushSize))
+	msg.ApplyDelta(delta)
+	require.NoError(t, s.Stream(ctx, msg, delta))
+	require.Equal(t, int32(2), q.updates.Load())
+
+	// Updates persist the pending deltas with the message.
+	delta = msg.NewDelta(ContentDelta, "", "!")
+	msg.ApplyDelta(delta)
+	require.NoError(t, s.Stream(ctx, msg, delta))
+	msg.AddFinish(FinishReasonEndTurn, "", "")
+	require.NoError(t, s.Update(ctx, msg))
+	require.Equal(t, int32(3), q.updates.Load())
+	time.Sleep(2 * streamFlushInterval)
+	require.Equal(t, int32(3), q.updates.Load())
+
+	stored, err := s.Get(ctx, msg.ID)
+	require.NoError(t, err)
+	require.True(t, stored.IsFinished())
+	require.True(t, strings.HasSuffix(stored.Content().Text, "a!"))
+}
+
+func TestApplyDelta(t *testing.T) {
+	t.Parallel()
+
+	msg := Message{Parts: []ContentPart{
+		TextContent{Text: "Hello"},
+		ToolCall{ID: "c1", Input: `{"path":`},
+	}}
+
+	// Deltas already applied are skipped, overlapping ones are trimmed.
+	require.True(t, msg.ApplyDelta(Delta{Type: ContentDelta, Offset: 0, Content: "Hel"}))
+	require.True(t, msg.ApplyDelta(Delta{Type: ContentDelta, Offset: 3, Content: "lo world"}))
+	require.Equal(t, "Hello world", msg.Content().Text)
+
+	// Deltas after missed ones are dropped.
+	require.False(t, msg.ApplyDelta(Delta{Type: ContentDelta, Offset: 20, Content: "!"}))
+	require.Equal(t, "Hello world", msg.Content().Text)
+
+	require.True(t, msg.ApplyDelta(Delta{Type: ToolCallInputDelta, ToolCallID: "c1", Offset: 8, Content: `"."}`}))
+	require.Equal(t, `{"path":"."}`, msg.ToolCalls()[0].Input)
+
+	require.True(t, msg.ApplyDelta(Delta{Type: ReasoningDelta, Content: "Thinking"}))
+	require.Equal(t, "Thinking", msg.ReasoningContent().Thinking)
+}
@@ -0,0 +1,208 @@
+package permission
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/google/uuid"
+)
+
+// GrantScope is where an "always allow" grant applies.
+type GrantScope string
+
+const (
+	// GrantScopeSession applies to the session the permission was asked in.
+	GrantScopeSession GrantScope = "session"
+	// GrantScopeProject applies to every session of the working directory.
+	GrantScopeProject GrantScope = "project"
+	// GrantScopeGlobal applies to every session. Grants are stored in the
+	// data directory, so projects with their own data directory do not share
+	// them.
+	GrantScopeGlobal GrantScope = "global"
+)
+
+// Grant is a permission the user always allows.
+type Grant struct {
+	ID        string     `json:"id"`
+	Scope     GrantScope `json:"scope"`
+	SessionID string     `json:"session_id,omitempty"`
+	Project   string     `json:"project,omitempty"`
+	ToolName  string     `json:"tool_name"`
+	Action    string     `json:"action"`
+	Path      string     `json:"path,omitempty"`
+	// Command is the shell command the grant is limited to. Grants with a
+	// command apply wherever the command runs.
+	Command   string `json:"command,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// newGrant creates the grant allowing permission in scope. Session grants
+// allow the tool action on the path, as they always did. Wider grants of a
+// shell command only allow that exact command.
+func (s *permissionService) newGrant(permission PermissionRequest, scope GrantScope) Grant {
+	grant := Grant{
+		ID:       uuid.New().String(),
+		Scope:    scope,
+		ToolName: permission.ToolName,
+		Action:   permission.Action,
+		Path:     permission.Path,
+	}
+	switch scope {
+	case GrantScopeSession:
+		grant.SessionID = permission.SessionID
+		grant.Project = s.workingDir
+	case GrantScopeProject:
+		grant.Project = s.workingDir
+	}
+	if scope != GrantScopeSession {
+		target := requestTarget(CreatePermissionRequest{Params: permission.Params})
+		if target.command != "" {
+			grant.Command = normalizeCommand(target.command)
+			grant.Path = ""
+		}
+	}
+	return grant
+}
+
+// allows reports whether the grant allows permission.
+func (s *permissionService) allows(grant Grant, permission PermissionRequest, command string) bool {
+	if grant.ToolName != permission.ToolName || grant.Action != permission.Action {
+		return false
+	}
+	switch grant.Scope {
+	case GrantScopeSession:
+		if grant.SessionID != permission.SessionID {
+			return false
+		}
+	case GrantScopeProject:
+		if grant.Project != s.workingDir {
+			return false
+		}
+	}
+	if grant.Command != "" {
+		return grant.Command == normalizeCommand(command)
+	}
+	return grant.Path == permission.Path
+}
+
+// granted reports whether an earlier grant allows permission. Grants made by
+// this process are checked first, then the ones stored in the database.
+func (s *permissionService) granted(permission PermissionRequest, command string) bool {
+	s.grantsMu.RLock()
+	for _, grant := range s.grants {
+		if s.allows(grant, permission, command) {
+			s.grantsMu.RUnlock()
+			return true
+		}
+	}
+	s.grantsMu.RUnlock()
+
+	if s.q == nil {
+		return false
+	}
+	grants, err := s.q.ListMatchingPermissionGrants(context.Background(), db.ListMatchingPermissionGrantsParams{
+		ToolName:  permission.ToolName,
+		Action:    permission.Action,
+		Project:   s.workingDir,
+		SessionID: sql.NullString{String: permission.SessionID, Valid: permission.SessionID != ""},
+	})
+	if err != nil {
+		slog.Error("Failed to list permission grants", "error", err)
+		return false
+	}
+	for _, dbGrant := range grants {
+		if s.allows(fromDBGrant(dbGrant), permission, command) {
+			return true
+		}
+	}
+	return false
+}
+
+// addGrant remembers grant and stores it in the database.
+func (s *permissionService) addGrant(grant Grant) {
+	if s.q != nil {
+		dbGrant, err := s.q.CreatePermissionGrant(context.Background(), db.CreatePermissionGrantParams{
+			ID:        grant.ID,
+			Scope:     string(grant.Scope),
+			SessionID: sql.NullString{String: grant.SessionID, Valid: grant.SessionID != ""},
+			Project:   grant.Project,
+			ToolName:  grant.ToolName,
+			Action:    grant.Action,
+			Path:      grant.Path,
+			Command:   grant.Command,
+		})
+		if err != nil {
+			// The grant still applies until the app exits.
+			slog.Error("Failed to store permission grant", "error", err)
+		} else {
+			grant = fromDBGrant(dbGrant)
+		}
+	}
+
+	s.grantsMu.Lock()
+	s.grants = append(s.grants, grant)
+	s.grantsMu.Unlock()
+}
+
+// ListGrants returns the grants of the working directory and the global
+// grants, newest first.
+func (s *permissionService) ListGrants(ctx context.Context) ([]Grant, error) {
+	if s.q == nil {
+		s.grantsMu.RLock()
+		defer s.grantsMu.RUnlock()
+		grants := slices.Clone(s.grants)
+		slices.Reverse(grants)
+		return grants, nil
+	}
+	dbGrants, err := s.q.ListPermissionGrants(ctx, s.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]Grant, len(dbGrants))
+	for i, dbGrant := range dbGrants {
+		grants[i] = fromDBGrant(dbGrant)
+	}
+	return grants, nil
+}
+
+// RevokeGrant removes a grant, so that its permission is asked again.
+func (s *permissionService) RevokeGrant(ctx context.Context, id string) error {
+	s.grantsMu.Lock()
+	found := false
+	s.grants = slices.DeleteFunc(s.grants, func(grant Grant) bool {
+		if grant.ID == id {
+			found = true
+			return true
+		}
+		return false
+	})
+	s.grantsMu.Unlock()
+
+	if s.q == nil {
+		if !found {
+			return sql.ErrNoRows
+		}
+		return nil
+	}
+	if _, err := s.q.GetPermissionGrant(ctx, id); err != nil {
+		return err
+	}
+	return s.q.DeletePermissionGrant(ctx, id)
+}
+
+func fromDBGrant(item db.PermissionGrant) Grant {
+	return Grant{
+		ID:        item.ID,
+		Scope:     GrantScope(item.Scope),
+		SessionID: item.SessionID.String,
+		Project:   item.Project,
+		ToolName:  item.ToolName,
+		Action:    item.Action,
+		Path:      item.Path,
+		Command:   item.Command,
+		CreatedAt: item.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package permission
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+// authorize runs a request, answering with answer when the user is asked.
+// It reports whether the user was asked.
+func authorize(t *testing.T, service Service, opts CreatePermissionRequest, answer func(PermissionRequest)) (bool, error) {
+	t.Helper()
+	events := service.Subscribe(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- service.Authorize(opts) }()
+	select {
+	case err := <-done:
+		return false, err
+	case event := <-events:
+		answer(event.Payload)
+		return true, <-done
+	}
+}
+
+func TestPersistentGrants(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	sessions := session.NewService(q, conn)
+	first, err := sessions.Create(ctx, "first")
+	require.NoError(t, err)
+	second, err := sessions.Create(ctx, "second")
+	require.NoError(t, err)
+
+	workingDir := t.TempDir()
+	bash := func(sessionID, command string) CreatePermissionRequest {
+		return CreatePermissionRequest{
+			SessionID: sessionID,
+			ToolName:  "bash",
+			Action:    "execute",
+			Path:      workingDir,
+			Params:    bashParams{command},
+		}
+	}
+
+	service := NewPermissionService(q, workingDir, false, nil, nil)
+	asked, err := authorize(t, service, bash(first.ID, "go  test ./..."), func(p PermissionRequest) {
+		service.GrantScoped(p, GrantScopeProject)
+	})
+	require.True(t, asked)
+	require.NoError(t, err)
+
+	// A new service sees the grant, as after a restart.
+	restarted := NewPermissionService(q, workingDir, false, nil, nil)
+	asked, err = authorize(t, restarted, bash(second.ID, "go test ./..."), restarted.Deny)
+	require.False(t, asked)
+	require.NoError(t, err)
+
+	// Other commands are still asked for.
+	asked, err = authorize(t, restarted, bash(second.ID, "go test ./... && rm -rf /"), restarted.Deny)
+	require.True(t, asked)
+	require.ErrorIs(t, err, ErrorPermissionDenied)
+
+	// Other projects do not share project grants.
+	other := NewPermissionService(q, t.TempDir(), false, nil, nil)
+	asked, _ = authorize(t, other, bash(second.ID, "go test ./..."), other.Deny)
+	require.True(t, asked)
+
+	grants, err := restarted.ListGrants(ctx)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, GrantScopeProject, grants[0].Scope)
+	require.Equal(t, "go test ./...", grants[0].Command)
+
+	require.NoError(t, restarted.RevokeGrant(ctx, grants[0].ID))
+	asked, _ = authorize(t, restarted, bash(second.ID, "go test ./..."), restarted.Deny)
+	require.True(t, asked)
+	require.Error(t, restarted.RevokeGrant(ctx, grants[0].ID))
+
+	// Session grants allow any command of the session, and are removed with
+	// it.
+	asked, _ = authorize(t, service, bash(first.ID, "make"), func(p PermissionRequest) {
+		service.GrantPersistent(p)
+	})
+	require.True(t, asked)
+	asked, _ = authorize(t, restarted, bash(first.ID, "make build"), restarted.Deny)
+	require.False(t, asked)
+	asked, _ = authorize(t, restarted, bash(second.ID, "make build"), restarted.Deny)
+	require.True(t, asked)
+
+	require.NoError(t, sessions.Delete(ctx, first.ID))
+	grants, err = restarted.ListGrants(ctx)
+	require.NoError(t, err)
+	require.Empty(t, grants)
+}
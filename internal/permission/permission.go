@@ -8,7 +8,9 @@ import (
 	"slices"
 	"sync"
 
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/google/uuid"
 )
@@ -45,34 +47,47 @@ type PermissionRequest struct {
 type Service interface {
 	pubsub.Suscriber[PermissionRequest]
 	GrantPersistent(permission PermissionRequest)
+	GrantScoped(permission PermissionRequest, scope GrantScope)
 	Grant(permission PermissionRequest)
 	Deny(permission PermissionRequest)
 	Request(opts CreatePermissionRequest) bool
+	Authorize(opts CreatePermissionRequest) error
 	AutoApproveSession(sessionID string)
 	SetSkipRequests(skip bool)
 	SkipRequests() bool
 	SubscribeNotifications(ctx context.Context) <-chan pubsub.Event[PermissionNotification]
+	ListGrants(ctx context.Context) ([]Grant, error)
+	RevokeGrant(ctx context.Context, id string) error
 }
 
 type permissionService struct {
 	*pubsub.Broker[PermissionRequest]
 
+	q                     db.Querier
 	notificationBroker    *pubsub.Broker[PermissionNotification]
 	workingDir            string
-	sessionPermissions    []PermissionRequest
-	sessionPermissionsMu  sync.RWMutex
+	grants                []Grant
+	grantsMu              sync.RWMutex
 	pendingRequests       *csync.Map[string, chan bool]
 	autoApproveSessions   map[string]bool
 	autoApproveSessionsMu sync.RWMutex
 	skip                  bool
 	allowedTools          []string
+	policy                policy
 
 	// used to make sure we only process one request at a time
 	requestMu     sync.Mutex
 	activeRequest *PermissionRequest
 }
 
+// GrantPersistent grants the permission and allows it for the rest of the
+// session.
 func (s *permissionService) GrantPersistent(permission PermissionRequest) {
+	s.GrantScoped(permission, GrantScopeSession)
+}
+
+// GrantScoped grants the permission and always allows it in scope.
+func (s *permissionService) GrantScoped(permission PermissionRequest, scope GrantScope) {
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: permission.ToolCallID,
 		Granted:    true,
@@ -82,9 +97,7 @@ func (s *permissionService) GrantPersistent(permission PermissionRequest) {
 		respCh <- true
 	}
 
-	s.sessionPermissionsMu.Lock()
-	s.sessionPermissions = append(s.sessionPermissions, permission)
-	s.sessionPermissionsMu.Unlock()
+	s.addGrant(s.newGrant(permission, scope))
 
 	if s.activeRequest != nil && s.activeRequest.ID == permission.ID {
 		s.activeRequest = nil
@@ -122,9 +135,25 @@ func (s *permissionService) Deny(permission PermissionRequest) {
 	}
 }
 
+// Request reports whether the request was granted. Use Authorize to learn
+// why it was not.
 func (s *permissionService) Request(opts CreatePermissionRequest) bool {
+	return s.Authorize(opts) == nil
+}
+
+// Authorize checks the request against the permission rules, and asks the
+// user when no rule decides. It returns ErrorPermissionDenied when the user
+// denies the request and a *PolicyDeniedError when a rule does.
+func (s *permissionService) Authorize(opts CreatePermissionRequest) error {
+	// Deny rules apply even when every request is accepted.
+	rule, ruleName, hasRule := s.policy.match(opts)
+	if hasRule && rule.Decision == config.PermissionDeny {
+		s.notifyDenied(opts.ToolCallID)
+		return &PolicyDeniedError{Rule: ruleName}
+	}
+
 	if s.skip {
-		return true
+		return nil
 	}
 
 	// tell the UI that a permission was requested
@@ -134,18 +163,33 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	s.requestMu.Lock()
 	defer s.requestMu.Unlock()
 
-	// Check if the tool/action combination is in the allowlist
-	commandKey := opts.ToolName + ":" + opts.Action
-	if slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName) {
-		return true
+	if hasRule && rule.Decision == config.PermissionAllow {
+		s.notifyGranted(opts.ToolCallID)
+		return nil
 	}
 
 	s.autoApproveSessionsMu.RLock()
 	autoApprove := s.autoApproveSessions[opts.SessionID]
 	s.autoApproveSessionsMu.RUnlock()
 
-	if autoApprove {
-		return true
+	if hasRule && rule.Decision == config.PermissionAsk {
+		// Nobody can answer in sessions that are approved automatically.
+		if autoApprove {
+			s.notifyDenied(opts.ToolCallID)
+			return &PolicyDeniedError{Rule: ruleName + " requires approval, which is not available in this session"}
+		}
+	} else {
+		// Check if the tool/action combination is in the allowlist
+		commandKey := opts.ToolName + ":" + opts.Action
+		if slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName) {
+			s.notifyGranted(opts.ToolCallID)
+			return nil
+		}
+
+		if autoApprove {
+			s.notifyGranted(opts.ToolCallID)
+			return nil
+		}
 	}
 
 	fileInfo, err := os.Stat(opts.Path)
@@ -172,23 +216,10 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 		Params:      opts.Params,
 	}
 
-	s.sessionPermissionsMu.RLock()
-	for _, p := range s.sessionPermissions {
-		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
-			s.sessionPermissionsMu.RUnlock()
-			return true
-		}
+	if s.granted(permission, requestTarget(opts).command) {
+		s.notifyGranted(permission.ToolCallID)
+		return nil
 	}
-	s.sessionPermissionsMu.RUnlock()
-
-	s.sessionPermissionsMu.RLock()
-	for _, p := range s.sessionPermissions {
-		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
-			s.sessionPermissionsMu.RUnlock()
-			return true
-		}
-	}
-	s.sessionPermissionsMu.RUnlock()
 
 	s.activeRequest = &permission
 
@@ -199,7 +230,27 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	// Publish the request
 	s.Publish(pubsub.CreatedEvent, permission)
 
-	return <-respCh
+	if !<-respCh {
+		return ErrorPermissionDenied
+	}
+	return nil
+}
+
+// notifyGranted tells the UI that a request was granted without asking the
+// user, because of the allowlist or an earlier grant.
+func (s *permissionService) notifyGranted(toolCallID string) {
+	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
+		ToolCallID: toolCallID,
+		Granted:    true,
+	})
+}
+
+// notifyDenied tells the UI that a request was denied by a rule.
+func (s *permissionService) notifyDenied(toolCallID string) {
+	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
+		ToolCallID: toolCallID,
+		Denied:     true,
+	})
 }
 
 func (s *permissionService) AutoApproveSession(sessionID string) {
@@ -220,15 +271,16 @@ func (s *permissionService) SkipRequests() bool {
 	return s.skip
 }
 
-func NewPermissionService(workingDir string, skip bool, allowedTools []string) Service {
+func NewPermissionService(q db.Querier, workingDir string, skip bool, allowedTools []string, rules []config.PermissionRule) Service {
 	return &permissionService{
 		Broker:              pubsub.NewBroker[PermissionRequest](),
+		q:                   q,
 		notificationBroker:  pubsub.NewBroker[PermissionNotification](),
 		workingDir:          workingDir,
-		sessionPermissions:  make([]PermissionRequest, 0),
 		autoApproveSessions: make(map[string]bool),
 		skip:                skip,
 		allowedTools:        allowedTools,
+		policy:              policy{workingDir: workingDir, rules: rules},
 		pendingRequests:     csync.NewMap[string, chan bool](),
 	}
 }
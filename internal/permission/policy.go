@@ -0,0 +1,256 @@
+package permission
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/charmbracelet/crush/internal/config"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// PolicyDeniedError is returned when a permission rule denies a tool call.
+type PolicyDeniedError struct {
+	Rule string
+}
+
+func (e *PolicyDeniedError) Error() string {
+	return fmt.Sprintf("permission denied by policy rule %s", e.Rule)
+}
+
+// policy evaluates the configured permission rules in order.
+type policy struct {
+	workingDir string
+	rules      []config.PermissionRule
+}
+
+// match returns the first rule matching the request, and its display name.
+func (p policy) match(opts CreatePermissionRequest) (config.PermissionRule, string, bool) {
+	if len(p.rules) == 0 {
+		return config.PermissionRule{}, "", false
+	}
+	target := requestTarget(opts)
+	for i, rule := range p.rules {
+		if p.matches(rule, opts, target) {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return rule, fmt.Sprintf("%q", name), true
+		}
+	}
+	return config.PermissionRule{}, "", false
+}
+
+func (p policy) matches(rule config.PermissionRule, opts CreatePermissionRequest, target target) bool {
+	if len(rule.Tools) > 0 && !matchAny(rule.Tools, opts.ToolName, matchWildcard) {
+		return false
+	}
+	if len(rule.Actions) > 0 && !matchAny(rule.Actions, opts.Action, matchWildcard) {
+		return false
+	}
+	if len(rule.Paths) > 0 {
+		if len(target.paths) == 0 {
+			return false
+		}
+		matched := 0
+		for _, path := range target.paths {
+			if matchAny(rule.Paths, p.relative(path), matchPath) {
+				matched++
+			}
+		}
+		// As with commands, allowing must cover every file a request
+		// touches, while denying one of them denies the request.
+		if rule.Decision == config.PermissionAllow {
+			if matched != len(target.paths) {
+				return false
+			}
+		} else if matched == 0 {
+			return false
+		}
+	}
+	if len(rule.Commands) > 0 {
+		if target.command == "" {
+			return false
+		}
+		commands := splitCommands(target.command)
+		matched := 0
+		for _, cmd := range commands {
+			if !matchAny(rule.Commands, cmd.text, matchWildcard) {
+				continue
+			}
+			// Denying one command denies the whole line.
+			if rule.Decision == config.PermissionDeny {
+				return true
+			}
+			// Allowing a command must not allow whatever is chained to it,
+			// nor redirections and variables changing what it does.
+			if cmd.plain {
+				matched++
+			}
+		}
+		return rule.Decision != config.PermissionDeny && matched == len(commands)
+	}
+	return true
+}
+
+// relative returns p relative to the working directory, with forward
+// slashes. Paths outside the working directory are returned as is.
+func (p policy) relative(abs string) string {
+	if !filepath.IsAbs(abs) {
+		return filepath.ToSlash(filepath.Clean(abs))
+	}
+	rel, err := filepath.Rel(p.workingDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// target is what a tool call operates on.
+type target struct {
+	paths   []string
+	command string
+}
+
+// requestTarget extracts the file paths and shell command of a request from
+// its parameters, falling back to the request path. Requests changing
+// several files, such as workspace edits, list them in files.
+func requestTarget(opts CreatePermissionRequest) target {
+	var t target
+	if opts.Params != nil {
+		if data, err := json.Marshal(opts.Params); err == nil {
+			var params struct {
+				FilePath string `json:"file_path"`
+				Path     string `json:"path"`
+				Command  string `json:"command"`
+				Files    []struct {
+					FilePath string `json:"file_path"`
+				} `json:"files"`
+			}
+			if json.Unmarshal(data, &params) == nil {
+				for _, file := range params.Files {
+					if file.FilePath != "" {
+						t.paths = append(t.paths, file.FilePath)
+					}
+				}
+				if path := cmp.Or(params.FilePath, params.Path); path != "" && len(t.paths) == 0 {
+					t.paths = []string{path}
+				}
+				t.command = params.Command
+			}
+		}
+	}
+	if len(t.paths) == 0 && opts.Path != "" {
+		t.paths = []string{opts.Path}
+	}
+	return t
+}
+
+// shellCommand is a simple command of a shell line.
+type shellCommand struct {
+	// text is the command and its arguments.
+	text string
+	// plain is whether the command runs without redirections, variable
+	// assignments or declarations, which only allow rules can rely on.
+	plain bool
+}
+
+// splitCommands returns every simple command of a shell line, so that
+// "go test ./... && rm -rf /" yields both commands. Redirections, variable
+// assignments and declarations are returned as commands that are not plain.
+func splitCommands(line string) []shellCommand {
+	file, err := syntax.NewParser().Parse(strings.NewReader(line), "")
+	if err != nil {
+		return []shellCommand{{text: normalizeCommand(line)}}
+	}
+	var commands []shellCommand
+	printer := syntax.NewPrinter()
+	syntax.Walk(file, func(node syntax.Node) bool {
+		stmt, ok := node.(*syntax.Stmt)
+		if !ok {
+			return true
+		}
+		var sb strings.Builder
+		switch cmd := stmt.Cmd.(type) {
+		case *syntax.CallExpr:
+			if len(cmd.Args) == 0 {
+				_ = printer.Print(&sb, stmt)
+				commands = append(commands, shellCommand{text: normalizeCommand(sb.String())})
+				return true
+			}
+			for i, arg := range cmd.Args {
+				if i > 0 {
+					sb.WriteByte(' ')
+				}
+				_ = printer.Print(&sb, arg)
+			}
+			commands = append(commands, shellCommand{
+				text:  normalizeCommand(sb.String()),
+				plain: len(stmt.Redirs) == 0 && len(cmd.Assigns) == 0,
+			})
+		case *syntax.DeclClause, nil:
+			_ = printer.Print(&sb, stmt)
+			commands = append(commands, shellCommand{text: normalizeCommand(sb.String())})
+		default:
+			if len(stmt.Redirs) > 0 {
+				_ = printer.Print(&sb, stmt)
+				commands = append(commands, shellCommand{text: normalizeCommand(sb.String())})
+			}
+		}
+		return true
+	})
+	if len(commands) == 0 {
+		return []shellCommand{{text: normalizeCommand(line)}}
+	}
+	return commands
+}
+
+func normalizeCommand(cmd string) string {
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+func matchAny(patterns []string, value string, match func(pattern, value string) bool) bool {
+	for _, pattern := range patterns {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches value against a pattern where * matches any text,
+// including spaces and slashes.
+func matchWildcard(pattern, value string) bool {
+	pattern = normalizeCommand(pattern)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+func matchPath(pattern, value string) bool {
+	pattern = path.Clean(filepath.ToSlash(pattern))
+	if ok, _ := doublestar.Match(pattern, value); ok {
+		return true
+	}
+	// A pattern naming a directory also matches what is inside of it.
+	ok, _ := doublestar.Match(pattern+"/**", value)
+	return ok
+}
@@ -0,0 +1,97 @@
+package permission
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type bashParams struct {
+	Command string `json:"command"`
+}
+
+type editParams struct {
+	FilePath string `json:"file_path"`
+}
+
+type workspaceEditParams struct {
+	Files []editParams `json:"files"`
+}
+
+func testRules() []config.PermissionRule {
+	return []config.PermissionRule{
+		{Name: "tests", Decision: config.PermissionAllow, Tools: []string{"bash"}, Commands: []string{"go test ./...", "make *"}},
+		{Name: "no-push", Decision: config.PermissionDeny, Tools: []string{"bash"}, Commands: []string{"git push*"}},
+		{Name: "no-vendor", Decision: config.PermissionDeny, Tools: []string{"edit", "write"}, Paths: []string{"vendor/**"}},
+		{Name: "mcp", Decision: config.PermissionAllow, Tools: []string{"mcp_*"}},
+		{Decision: config.PermissionAsk},
+	}
+}
+
+func TestPolicyMatch(t *testing.T) {
+	t.Parallel()
+
+	p := policy{workingDir: "/work", rules: testRules()}
+	tests := []struct {
+		name string
+		opts CreatePermissionRequest
+		rule string
+	}{
+		{"exact command", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"go test ./..."}}, `"tests"`},
+		{"wildcard command", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"make   lint"}}, `"tests"`},
+		{"pipeline all allowed", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"make build && go test ./..."}}, `"tests"`},
+		{"chained command not allowed", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"go test ./... && rm -rf /"}}, `"#5"`},
+		{"redirected command not allowed", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"go test ./... > ~/.bashrc"}}, `"#5"`},
+		{"command with variables not allowed", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"GOFLAGS=-toolexec=/tmp/x go test ./..."}}, `"#5"`},
+		{"exported variable not allowed", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"export GOFLAGS=-toolexec=/tmp/x; go test ./..."}}, `"#5"`},
+		{"redirected deny", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"GIT_TRACE=1 git push origin main 2>&1"}}, `"no-push"`},
+		{"chained deny", CreatePermissionRequest{ToolName: "bash", Params: bashParams{"make && git push origin main"}}, `"no-push"`},
+		{"vendor edit", CreatePermissionRequest{ToolName: "edit", Params: editParams{"/work/vendor/x/y.go"}}, `"no-vendor"`},
+		{"relative vendor write", CreatePermissionRequest{ToolName: "write", Params: editParams{"vendor/a.go"}}, `"no-vendor"`},
+		{"other edit", CreatePermissionRequest{ToolName: "edit", Params: editParams{"/work/main.go"}}, `"#5"`},
+		{"mcp tool", CreatePermissionRequest{ToolName: "mcp_github_list"}, `"mcp"`},
+		{"workspace edit touching vendor", CreatePermissionRequest{ToolName: "edit", Path: "/work", Params: workspaceEditParams{[]editParams{{"/work/main.go"}, {"/work/vendor/a.go"}}}}, `"no-vendor"`},
+		{"workspace edit outside vendor", CreatePermissionRequest{ToolName: "edit", Path: "/work", Params: workspaceEditParams{[]editParams{{"/work/main.go"}, {"/work/lib.go"}}}}, `"#5"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, name, ok := p.match(tt.opts)
+			require.True(t, ok)
+			require.Equal(t, tt.rule, name)
+		})
+	}
+
+	_, _, ok := policy{workingDir: "/work"}.match(CreatePermissionRequest{ToolName: "bash"})
+	require.False(t, ok)
+
+	require.Equal(t, "..vendor/a.go", p.relative("/work/..vendor/a.go"))
+	require.Equal(t, "/other/a.go", p.relative("/other/a.go"))
+}
+
+func TestAuthorizeWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	service := NewPermissionService(nil, "/work", false, []string{"edit"}, testRules())
+
+	err := service.Authorize(CreatePermissionRequest{SessionID: "s", ToolName: "bash", Params: bashParams{"make test"}})
+	require.NoError(t, err)
+
+	// Deny rules win over the allowlist.
+	err = service.Authorize(CreatePermissionRequest{SessionID: "s", ToolName: "edit", Params: editParams{"/work/vendor/a.go"}})
+	var policyErr *PolicyDeniedError
+	require.True(t, errors.As(err, &policyErr))
+	require.Contains(t, err.Error(), "no-vendor")
+
+	// Ask rules cannot be answered in sessions approved automatically.
+	service.AutoApproveSession("auto")
+	err = service.Authorize(CreatePermissionRequest{SessionID: "auto", ToolName: "fetch"})
+	require.True(t, errors.As(err, &policyErr))
+
+	// Deny rules also apply when requests are skipped.
+	skipping := NewPermissionService(nil, "/work", true, nil, testRules())
+	require.False(t, skipping.Request(CreatePermissionRequest{ToolName: "bash", Params: bashParams{"git push"}}))
+	require.True(t, skipping.Request(CreatePermissionRequest{ToolName: "fetch"}))
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// clientBufferSize is the number of events buffered for each client before
+// new events are dropped for it.
+const clientBufferSize = 256
+
+// event is a single server-sent event.
+type event struct {
+	Name      string
+	SessionID string
+	Data      []byte
+}
+
+// hub fans out events to the clients connected to the event stream.
+type hub struct {
+	mu      sync.Mutex
+	clients map[chan event]struct{}
+	closed  bool
+}
+
+func newHub() *hub {
+	return &hub{clients: make(map[chan event]struct{})}
+}
+
+func (h *hub) subscribe() (chan event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan event, clientBufferSize)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.clients[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.clients[ch]; ok {
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *hub) broadcast(ev event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- ev:
+		default:
+			slog.Warn("Event dropped due to slow client", "event", ev.Name)
+		}
+	}
+}
+
+func (h *hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// handleEvents streams application events as server-sent events. The
+// session_id query parameter limits the stream to events of one session.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	sessionID := r.URL.Query().Get("session_id")
+
+	events, unsubscribe := s.hub.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if sessionID != "" && ev.SessionID != "" && ev.SessionID != sessionID {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Name, ev.Data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// agentEvent is the wire representation of agent.AgentEvent.
+type agentEvent struct {
+	Type      agent.AgentEventType `json:"type"`
+	SessionID string               `json:"session_id,omitempty"`
+	Message   *message.Message     `json:"message,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Progress  string               `json:"progress,omitempty"`
+	Done      bool                 `json:"done"`
+}
+
+// stateEvent is the wire representation of MCP and LSP state changes.
+type stateEvent struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	State string `json:"state,omitempty"`
+	Error string `json:"error,omitempty"`
+	Count int    `json:"count"`
+}
+
+// encodeEvent converts an application event into a server-sent event. The
+// event name is the kind of resource, and the payload carries the change
+// type next to the resource itself.
+func encodeEvent(msg tea.Msg) (event, bool) {
+	var (
+		name      string
+		sessionID string
+		typ       pubsub.EventType
+		payload   any
+	)
+	switch msg := msg.(type) {
+	case pubsub.Event[session.Session]:
+		name, typ, payload = "session", msg.Type, msg.Payload
+		sessionID = msg.Payload.ID
+	case pubsub.Event[message.Message]:
+		name, typ, payload = "message", msg.Type, msg.Payload
+		sessionID = msg.Payload.SessionID
+	case pubsub.Event[message.Delta]:
+		name, typ, payload = "message_delta", msg.Type, msg.Payload
+		sessionID = msg.Payload.SessionID
+	case pubsub.Event[permission.PermissionRequest]:
+		name, typ, payload = "permission_request", msg.Type, msg.Payload
+		sessionID = msg.Payload.SessionID
+	case pubsub.Event[permission.PermissionNotification]:
+		name, typ, payload = "permission_notification", msg.Type, msg.Payload
+	case pubsub.Event[history.File]:
+		name, typ, payload = "file", msg.Type, msg.Payload
+		sessionID = msg.Payload.SessionID
+	case pubsub.Event[agent.AgentEvent]:
+		ev := agentEvent{
+			Type:      msg.Payload.Type,
+			SessionID: msg.Payload.SessionID,
+			Progress:  msg.Payload.Progress,
+			Done:      msg.Payload.Done,
+		}
+		if msg.Payload.Message.ID != "" {
+			ev.Message = &msg.Payload.Message
+			ev.SessionID = msg.Payload.Message.SessionID
+		}
+		if msg.Payload.Error != nil {
+			ev.Error = msg.Payload.Error.Error()
+		}
+		name, typ, payload = "agent", msg.Type, ev
+		sessionID = ev.SessionID
+	case pubsub.Event[agent.MCPEvent]:
+		ev := stateEvent{
+			Type:  string(msg.Payload.Type),
+			Name:  msg.Payload.Name,
+			State: msg.Payload.State.String(),
+			Count: msg.Payload.ToolCount,
+		}
+		if msg.Payload.Error != nil {
+			ev.Error = msg.Payload.Error.Error()
+		}
+		name, typ, payload = "mcp", msg.Type, ev
+	case pubsub.Event[app.LSPEvent]:
+		ev := stateEvent{
+			Type:  string(msg.Payload.Type),
+			Name:  msg.Payload.Name,
+			Count: msg.Payload.DiagnosticCount,
+		}
+		if msg.Payload.Error != nil {
+			ev.Error = msg.Payload.Error.Error()
+		}
+		name, typ, payload = "lsp", msg.Type, ev
+	default:
+		return event{}, false
+	}
+
+	data, err := json.Marshal(struct {
+		Type    pubsub.EventType `json:"type"`
+		Payload any              `json:"payload"`
+	}{typ, payload})
+	if err != nil {
+		slog.Error("Failed to encode event", "event", name, "error", err)
+		return event{}, false
+	}
+	return event{Name: name, SessionID: sessionID, Data: data}, true
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	t.Parallel()
+
+	msg := message.Message{
+		ID:        "m1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "hello"}},
+	}
+	ev, ok := encodeEvent(pubsub.Event[message.Message]{Type: pubsub.UpdatedEvent, Payload: msg})
+	require.True(t, ok)
+	require.Equal(t, "message", ev.Name)
+	require.Equal(t, "s1", ev.SessionID)
+
+	var decoded struct {
+		Type    pubsub.EventType `json:"type"`
+		Payload message.Message  `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(ev.Data, &decoded))
+	require.Equal(t, pubsub.UpdatedEvent, decoded.Type)
+	require.Equal(t, msg.ID, decoded.Payload.ID)
+	require.Equal(t, "hello", decoded.Payload.Content().Text)
+
+	ev, ok = encodeEvent(pubsub.Event[message.Delta]{
+		Type:    pubsub.UpdatedEvent,
+		Payload: message.Delta{MessageID: "m1", SessionID: "s1", Type: message.ContentDelta, Offset: 5, Content: "!"},
+	})
+	require.True(t, ok)
+	require.Equal(t, "message_delta", ev.Name)
+	require.Equal(t, "s1", ev.SessionID)
+	require.Contains(t, string(ev.Data), `"offset":5`)
+
+	ev, ok = encodeEvent(pubsub.Event[agent.AgentEvent]{
+		Type:    pubsub.CreatedEvent,
+		Payload: agent.AgentEvent{Type: agent.AgentEventTypeError, Error: errors.New("boom"), SessionID: "s2"},
+	})
+	require.True(t, ok)
+	require.Equal(t, "agent", ev.Name)
+	require.Equal(t, "s2", ev.SessionID)
+	require.Contains(t, string(ev.Data), `"error":"boom"`)
+
+	_, ok = encodeEvent(struct{}{})
+	require.False(t, ok)
+}
+
+func TestHub(t *testing.T) {
+	t.Parallel()
+
+	h := newHub()
+	a, unsubscribeA := h.subscribe()
+	b, _ := h.subscribe()
+
+	h.broadcast(event{Name: "one"})
+	require.Equal(t, "one", (<-a).Name)
+	require.Equal(t, "one", (<-b).Name)
+
+	unsubscribeA()
+	_, ok := <-a
+	require.False(t, ok)
+
+	h.broadcast(event{Name: "two"})
+	require.Equal(t, "two", (<-b).Name)
+
+	h.close()
+	_, ok = <-b
+	require.False(t, ok)
+
+	c, _ := h.subscribe()
+	_, ok = <-c
+	require.False(t, ok)
+}
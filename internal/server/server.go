@@ -0,0 +1,430 @@
+// Package server exposes the application services over a local HTTP API so
+// that editors and other tools can drive Crush without the TUI.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+// Server serves the HTTP API of a running App.
+type Server struct {
+	app   *app.App
+	token string
+	mux   *http.ServeMux
+	hub   *hub
+
+	// pending holds the permission requests waiting for an answer, by ID.
+	pending *csync.Map[string, permission.PermissionRequest]
+}
+
+// New creates a server for the given app. Every request must carry token as a
+// bearer token. If token is empty, a random one is generated, see Token.
+func New(app *app.App, token string) *Server {
+	if token == "" {
+		token = rand.Text()
+	}
+	s := &Server{
+		app:     app,
+		token:   token,
+		mux:     http.NewServeMux(),
+		hub:     newHub(),
+		pending: csync.NewMap[string, permission.PermissionRequest](),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /v1/health", s.handleHealth)
+	s.mux.HandleFunc("GET /v1/events", s.handleEvents)
+
+	s.mux.HandleFunc("GET /v1/sessions", s.handleListSessions)
+	s.mux.HandleFunc("POST /v1/sessions", s.handleCreateSession)
+	s.mux.HandleFunc("GET /v1/sessions/{id}", s.handleGetSession)
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	s.mux.HandleFunc("GET /v1/sessions/{id}/messages", s.handleListMessages)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/prompt", s.handlePrompt)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/cancel", s.handleCancel)
+
+	s.mux.HandleFunc("GET /v1/permissions", s.handleListPermissions)
+	s.mux.HandleFunc("POST /v1/permissions/{id}/grant", s.handleGrantPermission)
+	s.mux.HandleFunc("POST /v1/permissions/{id}/deny", s.handleDenyPermission)
+	s.mux.HandleFunc("GET /v1/permissions/grants", s.handleListGrants)
+	s.mux.HandleFunc("DELETE /v1/permissions/grants/{id}", s.handleRevokeGrant)
+}
+
+// Token returns the bearer token the requests must carry.
+func (s *Server) Token() string {
+	return s.token
+}
+
+// ServeHTTP implements http.Handler. Besides the token, it checks that the
+// request is addressed to a loopback host and does not come from another
+// origin, so that web pages cannot reach the API, even through DNS
+// rebinding.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackHost(r.Host) {
+		writeError(w, http.StatusForbidden, errors.New("host not allowed"))
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !isSameOrigin(origin, r.Host) {
+		writeError(w, http.StatusForbidden, errors.New("origin not allowed"))
+		return
+	}
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(auth), []byte(s.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	if r.ContentLength != 0 && r.Body != nil && r.Body != http.NoBody {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("request body must be application/json"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// isLoopbackHost reports whether the host of a request, with an optional
+// port, is localhost or a loopback address.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isSameOrigin reports whether origin is the origin of the API itself.
+func isSameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "http" && strings.EqualFold(u.Host, host)
+}
+
+// Serve serves the API on ln until ctx is done.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go s.app.SubscribeFunc(s.dispatch)
+
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		s.hub.close()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shutdown server", "error", err)
+		}
+	}()
+
+	slog.Info("Server listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// dispatch keeps track of pending permission requests and forwards the
+// event to the connected clients.
+func (s *Server) dispatch(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case pubsub.Event[permission.PermissionRequest]:
+		s.pending.Set(msg.Payload.ID, msg.Payload)
+	case pubsub.Event[permission.PermissionNotification]:
+		if msg.Payload.Granted || msg.Payload.Denied {
+			for id, req := range s.pending.Seq2() {
+				if req.ToolCallID == msg.Payload.ToolCallID {
+					s.pending.Del(id)
+				}
+			}
+		}
+	case pubsub.Event[agent.AgentEvent]:
+		// The requests left when a run ends were made by a cancelled run
+		// and are no longer waited for.
+		if msg.Payload.Type != agent.AgentEventTypeSummarize && msg.Payload.SessionID != "" {
+			for id, req := range s.pending.Seq2() {
+				if req.SessionID == msg.Payload.SessionID {
+					s.pending.Del(id)
+				}
+			}
+		}
+	}
+	if ev, ok := encodeEvent(msg); ok {
+		s.hub.broadcast(ev)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":     "ok",
+		"configured": s.app.Config().IsConfigured(),
+	})
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.app.Sessions.List(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Title == "" {
+		req.Title = "New Session"
+	}
+	session, err := s.app.Sessions.Create(r.Context(), req.Title)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	session, err := s.app.Sessions.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if s.app.CoderAgent != nil && s.app.CoderAgent.IsSessionBusy(id) {
+		writeError(w, http.StatusConflict, agent.ErrSessionBusy)
+		return
+	}
+	if err := s.app.Sessions.Delete(r.Context(), id); err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.app.Sessions.Get(r.Context(), id); err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	messages, err := s.app.Messages.List(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// handlePrompt starts an agent run in the session. The run continues in the
+// background and its progress is reported through the event stream, unless
+// the request asks to wait for the final response.
+func (s *Server) handlePrompt(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		Prompt string `json:"prompt"`
+		Wait   bool   `json:"wait"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("prompt is required"))
+		return
+	}
+	if s.app.CoderAgent == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no providers configured"))
+		return
+	}
+	if _, err := s.app.Sessions.Get(r.Context(), id); err != nil {
+		writeLookupError(w, err)
+		return
+	}
+
+	done, err := s.app.CoderAgent.Run(context.Background(), id, req.Prompt)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if done == nil {
+		// The session is busy, the prompt was queued.
+		writeJSON(w, http.StatusAccepted, map[string]any{"session_id": id, "queued": true})
+		return
+	}
+	if !req.Wait {
+		go func() {
+			// Drain the result, it is also published as an agent event.
+			<-done
+		}()
+		writeJSON(w, http.StatusAccepted, map[string]any{"session_id": id, "queued": false})
+		return
+	}
+
+	select {
+	case result := <-done:
+		if result.Error != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(result.Error, agent.ErrRequestCancelled) {
+				status = http.StatusConflict
+			}
+			writeError(w, status, result.Error)
+			return
+		}
+		writeJSON(w, http.StatusOK, result.Message)
+	case <-r.Context().Done():
+		// The client went away, the run keeps going.
+		go func() { <-done }()
+	}
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if s.app.CoderAgent != nil {
+		s.app.CoderAgent.Cancel(r.PathValue("id"))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListPermissions(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session_id")
+	requests := []permission.PermissionRequest{}
+	for _, req := range s.pending.Seq2() {
+		if sessionID == "" || req.SessionID == sessionID {
+			requests = append(requests, req)
+		}
+	}
+	writeJSON(w, http.StatusOK, requests)
+}
+
+func (s *Server) handleGrantPermission(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		// Persistent grants the permission for the rest of the session.
+		Persistent bool `json:"persistent"`
+		// Scope always allows the permission in the session, the project or
+		// globally.
+		Scope permission.GrantScope `json:"scope"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch req.Scope {
+	case "", permission.GrantScopeSession, permission.GrantScopeProject, permission.GrantScopeGlobal:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid scope %q", req.Scope))
+		return
+	}
+	perm, ok := s.pending.Take(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("permission request not found"))
+		return
+	}
+	switch {
+	case req.Scope != "":
+		s.app.Permissions.GrantScoped(perm, req.Scope)
+	case req.Persistent:
+		s.app.Permissions.GrantPersistent(perm)
+	default:
+		s.app.Permissions.Grant(perm)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDenyPermission(w http.ResponseWriter, r *http.Request) {
+	perm, ok := s.pending.Take(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("permission request not found"))
+		return
+	}
+	s.app.Permissions.Deny(perm)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListGrants(w http.ResponseWriter, r *http.Request) {
+	grants, err := s.app.Permissions.ListGrants(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, grants)
+}
+
+func (s *Server) handleRevokeGrant(w http.ResponseWriter, r *http.Request) {
+	if err := s.app.Permissions.RevokeGrant(r.Context(), r.PathValue("id")); err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSON decodes the request body into v. An empty body is accepted and
+// leaves v untouched.
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTPRejections(t *testing.T) {
+	t.Parallel()
+
+	s := New(nil, "secret")
+	serve := func(method, host, body string, header map[string]string) int {
+		var r *http.Request
+		if body == "" {
+			r = httptest.NewRequest(method, "/v1/unknown", nil)
+		} else {
+			r = httptest.NewRequest(method, "/v1/unknown", strings.NewReader(body))
+		}
+		r.Host = host
+		r.Header.Set("Authorization", "Bearer secret")
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Allowed requests reach the routes.
+	require.Equal(t, http.StatusNotFound, serve("GET", "127.0.0.1:4000", "", nil))
+	require.Equal(t, http.StatusNotFound, serve("GET", "localhost:4000", "", nil))
+	require.Equal(t, http.StatusNotFound, serve("GET", "[::1]:4000", "", nil))
+	require.Equal(t, http.StatusNotFound, serve("POST", "127.0.0.1:4000", "{}", map[string]string{
+		"Content-Type": "application/json; charset=utf-8",
+		"Origin":       "http://127.0.0.1:4000",
+	}))
+
+	// Hosts other than loopback, as with DNS rebinding.
+	require.Equal(t, http.StatusForbidden, serve("GET", "evil.example:4000", "", nil))
+	require.Equal(t, http.StatusForbidden, serve("GET", "192.168.1.10:4000", "", nil))
+
+	// Cross-origin requests from web pages.
+	require.Equal(t, http.StatusForbidden, serve("GET", "127.0.0.1:4000", "", map[string]string{
+		"Origin": "https://evil.example",
+	}))
+	require.Equal(t, http.StatusForbidden, serve("GET", "127.0.0.1:4000", "", map[string]string{
+		"Origin": "http://localhost:4000",
+	}))
+
+	// Bodies that are not JSON, as sent by forms and simple requests.
+	require.Equal(t, http.StatusUnsupportedMediaType, serve("POST", "127.0.0.1:4000", "{}", map[string]string{
+		"Content-Type": "text/plain",
+	}))
+	require.Equal(t, http.StatusUnsupportedMediaType, serve("POST", "127.0.0.1:4000", "{}", nil))
+
+	// Missing or wrong tokens.
+	require.Equal(t, http.StatusUnauthorized, serve("GET", "127.0.0.1:4000", "", map[string]string{
+		"Authorization": "Bearer wrong",
+	}))
+	require.Equal(t, http.StatusUnauthorized, serve("GET", "127.0.0.1:4000", "", map[string]string{
+		"Authorization": "",
+	}))
+}
+
+func TestGeneratedToken(t *testing.T) {
+	t.Parallel()
+
+	s := New(nil, "")
+	require.NotEmpty(t, s.Token())
+	require.NotEqual(t, s.Token(), New(nil, "").Token())
+
+	r := httptest.NewRequest("GET", "/v1/unknown", nil)
+	r.Host = "127.0.0.1:4000"
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestDispatchDropsPendingPermissionsOfEndedRuns(t *testing.T) {
+	t.Parallel()
+
+	s := New(nil, "secret")
+	s.dispatch(pubsub.Event[permission.PermissionRequest]{
+		Type:    pubsub.CreatedEvent,
+		Payload: permission.PermissionRequest{ID: "p1", SessionID: "s1", ToolCallID: "c1"},
+	})
+	s.dispatch(pubsub.Event[permission.PermissionRequest]{
+		Type:    pubsub.CreatedEvent,
+		Payload: permission.PermissionRequest{ID: "p2", SessionID: "s2", ToolCallID: "c2"},
+	})
+	require.Equal(t, 2, s.pending.Len())
+
+	// Summaries do not end the run.
+	s.dispatch(pubsub.Event[agent.AgentEvent]{
+		Type:    pubsub.CreatedEvent,
+		Payload: agent.AgentEvent{Type: agent.AgentEventTypeSummarize, SessionID: "s1"},
+	})
+	require.Equal(t, 2, s.pending.Len())
+
+	s.dispatch(pubsub.Event[agent.AgentEvent]{
+		Type:    pubsub.CreatedEvent,
+		Payload: agent.AgentEvent{Type: agent.AgentEventTypeError, SessionID: "s1", Error: agent.ErrRequestCancelled},
+	})
+	_, ok := s.pending.Get("p1")
+	require.False(t, ok)
+	_, ok = s.pending.Get("p2")
+	require.True(t, ok)
+}
@@ -3,23 +3,30 @@ package session
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/google/uuid"
 )
 
 type Session struct {
-	ID               string
-	ParentSessionID  string
-	Title            string
-	MessageCount     int64
-	PromptTokens     int64
-	CompletionTokens int64
-	SummaryMessageID string
-	Cost             float64
-	CreatedAt        int64
-	UpdatedAt        int64
+	ID               string `json:"id"`
+	ParentSessionID  string `json:"parent_session_id,omitempty"`
+	Title            string `json:"title"`
+	MessageCount     int64  `json:"message_count"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	SummaryMessageID string `json:"summary_message_id,omitempty"`
+	// ForkedFromMessageID is the message of the parent session this session
+	// was forked from. It is empty for other sessions.
+	ForkedFromMessageID string  `json:"forked_from_message_id,omitempty"`
+	Cost                float64 `json:"cost"`
+	CreatedAt           int64   `json:"created_at"`
+	UpdatedAt           int64   `json:"updated_at"`
 }
 
 type Service interface {
@@ -28,14 +35,17 @@ type Service interface {
 	CreateTitleSession(ctx context.Context, parentSessionID string) (Session, error)
 	CreateTaskSession(ctx context.Context, toolCallID, parentSessionID, title string) (Session, error)
 	Get(ctx context.Context, id string) (Session, error)
+	GetLatest(ctx context.Context) (Session, error)
 	List(ctx context.Context) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
 	Delete(ctx context.Context, id string) error
+	Fork(ctx context.Context, sessionID, messageID string) (Session, error)
 }
 
 type service struct {
 	*pubsub.Broker[Session]
-	q db.Querier
+	q    *db.Queries
+	conn *sql.DB
 }
 
 func (s *service) Create(ctx context.Context, title string) (Session, error) {
@@ -92,6 +102,148 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Fork copies the session up to the given message into a new session that
+// links to the original. The tool results answering the message are copied
+// with it, along with the file versions and checkpoints up to the message.
+func (s *service) Fork(ctx context.Context, sessionID, messageID string) (Session, error) {
+	original, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	msgs, err := s.q.ListMessagesBySession(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	end := slices.IndexFunc(msgs, func(msg db.Message) bool { return msg.ID == messageID })
+	if end == -1 {
+		return Session{}, fmt.Errorf("message %s not found in session", messageID)
+	}
+	end++
+	for end < len(msgs) && msgs[end].Role == string(message.Tool) {
+		end++
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	qtx := s.q.WithTx(tx)
+
+	dbSession, err := qtx.CreateSession(ctx, db.CreateSessionParams{
+		ID:                  uuid.New().String(),
+		ParentSessionID:     sql.NullString{String: original.ID, Valid: true},
+		Title:               original.Title + " (fork)",
+		ForkedFromMessageID: sql.NullString{String: messageID, Valid: true},
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	fork := s.fromDBItem(dbSession)
+	if err := copyToFork(ctx, qtx, original, fork, msgs, end); err != nil {
+		return Session{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Session{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	fork, err = s.Get(ctx, fork.ID)
+	if err != nil {
+		return Session{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, fork)
+	return fork, nil
+}
+
+// copyToFork copies the first end messages of the original session to the
+// fork, with the checkpoints of these messages and the file versions created
+// before the next message.
+func copyToFork(ctx context.Context, q *db.Queries, original, fork Session, msgs []db.Message, end int) error {
+	messageIDs := make(map[string]string, end)
+	for _, msg := range msgs[:end] {
+		created, err := q.CreateMessage(ctx, db.CreateMessageParams{
+			ID:        uuid.New().String(),
+			SessionID: fork.ID,
+			Role:      msg.Role,
+			Parts:     msg.Parts,
+			Model:     msg.Model,
+			Provider:  msg.Provider,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy message: %w", err)
+		}
+		messageIDs[msg.ID] = created.ID
+	}
+
+	checkpoints, err := q.ListCheckpointsBySession(ctx, original.ID)
+	if err != nil {
+		return err
+	}
+	// The checkpoint of the next message, if any, has the file versions at
+	// the end of the fork. Without it, the versions are told apart by time.
+	var versions map[string]int64
+	for _, checkpoint := range checkpoints {
+		if end < len(msgs) && checkpoint.MessageID == msgs[end].ID {
+			if err := json.Unmarshal([]byte(checkpoint.Files), &versions); err != nil {
+				return fmt.Errorf("failed to unmarshal checkpoint files: %w", err)
+			}
+			continue
+		}
+		messageID, ok := messageIDs[checkpoint.MessageID]
+		if !ok {
+			continue
+		}
+		if _, err := q.CreateCheckpoint(ctx, db.CreateCheckpointParams{
+			ID:        uuid.New().String(),
+			SessionID: fork.ID,
+			MessageID: messageID,
+			Files:     checkpoint.Files,
+		}); err != nil {
+			return fmt.Errorf("failed to copy checkpoint: %w", err)
+		}
+	}
+
+	files, err := q.ListFilesBySession(ctx, original.ID)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		switch {
+		case versions != nil:
+			if version, ok := versions[file.Path]; !ok || file.Version > version {
+				continue
+			}
+		case end < len(msgs):
+			if file.CreatedAt > msgs[end].CreatedAt {
+				continue
+			}
+		}
+		if _, err := q.CreateFile(ctx, db.CreateFileParams{
+			ID:        uuid.New().String(),
+			SessionID: fork.ID,
+			Path:      file.Path,
+			Content:   file.Content,
+			Version:   file.Version,
+			IsNew:     file.IsNew,
+		}); err != nil {
+			return fmt.Errorf("failed to copy file: %w", err)
+		}
+	}
+
+	// Keep the summary when it is part of the fork, so the fork starts
+	// with the same context.
+	if summaryID, ok := messageIDs[original.SummaryMessageID]; ok {
+		if _, err := q.UpdateSession(ctx, db.UpdateSessionParams{
+			ID:               fork.ID,
+			Title:            fork.Title,
+			SummaryMessageID: sql.NullString{String: summaryID, Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *service) Get(ctx context.Context, id string) (Session, error) {
 	dbSession, err := s.q.GetSessionByID(ctx, id)
 	if err != nil {
@@ -100,6 +252,15 @@ func (s *service) Get(ctx context.Context, id string) (Session, error) {
 	return s.fromDBItem(dbSession), nil
 }
 
+// GetLatest returns the most recently updated top level session.
+func (s *service) GetLatest(ctx context.Context) (Session, error) {
+	dbSession, err := s.q.GetLatestSession(ctx)
+	if err != nil {
+		return Session{}, err
+	}
+	return s.fromDBItem(dbSession), nil
+}
+
 func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 	dbSession, err := s.q.UpdateSession(ctx, db.UpdateSessionParams{
 		ID:               session.ID,
@@ -134,23 +295,25 @@ func (s *service) List(ctx context.Context) ([]Session, error) {
 
 func (s service) fromDBItem(item db.Session) Session {
 	return Session{
-		ID:               item.ID,
-		ParentSessionID:  item.ParentSessionID.String,
-		Title:            item.Title,
-		MessageCount:     item.MessageCount,
-		PromptTokens:     item.PromptTokens,
-		CompletionTokens: item.CompletionTokens,
-		SummaryMessageID: item.SummaryMessageID.String,
-		Cost:             item.Cost,
-		CreatedAt:        item.CreatedAt,
-		UpdatedAt:        item.UpdatedAt,
+		ID:                  item.ID,
+		ParentSessionID:     item.ParentSessionID.String,
+		Title:               item.Title,
+		MessageCount:        item.MessageCount,
+		PromptTokens:        item.PromptTokens,
+		CompletionTokens:    item.CompletionTokens,
+		SummaryMessageID:    item.SummaryMessageID.String,
+		ForkedFromMessageID: item.ForkedFromMessageID.String,
+		Cost:                item.Cost,
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
 	}
 }
 
-func NewService(q db.Querier) Service {
+func NewService(q *db.Queries, conn *sql.DB) Service {
 	broker := pubsub.NewBroker[Session]()
 	return &service{
 		broker,
 		q,
+		conn,
 	}
 }
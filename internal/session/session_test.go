@@ -0,0 +1,89 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFork(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	q := db.New(conn)
+	sessions := NewService(q, conn)
+	messages := message.NewService(q)
+	files := history.NewService(q, conn)
+
+	sess, err := sessions.Create(ctx, "Original")
+	require.NoError(t, err)
+	create := func(role message.MessageRole, parts ...message.ContentPart) message.Message {
+		msg, err := messages.Create(ctx, sess.ID, message.CreateMessageParams{Role: role, Parts: parts})
+		require.NoError(t, err)
+		return msg
+	}
+	first := create(message.User, message.TextContent{Text: "read main.go"})
+	_, err = files.CreateCheckpoint(ctx, sess.ID, first.ID)
+	require.NoError(t, err)
+	call := create(message.Assistant,
+		message.ToolCall{ID: "call-1", Name: "view", Input: `{"file_path":"main.go"}`, Finished: true},
+		message.Finish{Reason: message.FinishReasonToolUse},
+	)
+	create(message.Tool, message.ToolResult{ToolCallID: "call-1", Content: "package main"})
+	_, err = files.Create(ctx, sess.ID, "/work/main.go", "v0")
+	require.NoError(t, err)
+	_, err = files.CreateVersion(ctx, sess.ID, "/work/main.go", "v1")
+	require.NoError(t, err)
+	next := create(message.User, message.TextContent{Text: "now delete it"})
+	_, err = files.CreateCheckpoint(ctx, sess.ID, next.ID)
+	require.NoError(t, err)
+	_, err = files.CreateVersion(ctx, sess.ID, "/work/main.go", "")
+	require.NoError(t, err)
+
+	fork, err := sessions.Fork(ctx, sess.ID, call.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, sess.ID, fork.ID)
+	require.Equal(t, sess.ID, fork.ParentSessionID)
+	require.Equal(t, call.ID, fork.ForkedFromMessageID)
+	require.Equal(t, "Original (fork)", fork.Title)
+
+	// The results of the tool calls of the selected message come along.
+	forked, err := messages.List(ctx, fork.ID)
+	require.NoError(t, err)
+	require.Len(t, forked, 3)
+	require.Equal(t, message.User, forked[0].Role)
+	require.Equal(t, "call-1", forked[1].ToolCalls()[0].ID)
+	require.Equal(t, "package main", forked[2].ToolResults()[0].Content)
+	for _, msg := range forked {
+		require.Equal(t, fork.ID, msg.SessionID)
+	}
+
+	// Files come with their versions up to the fork, and checkpoints of the
+	// copied messages.
+	forkedFiles, err := files.ListBySession(ctx, fork.ID)
+	require.NoError(t, err)
+	require.Len(t, forkedFiles, 2)
+	require.Equal(t, "v0", forkedFiles[0].Content)
+	require.Equal(t, "v1", forkedFiles[1].Content)
+	checkpoints, err := files.ListCheckpoints(ctx, fork.ID)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	require.Equal(t, forked[0].ID, checkpoints[0].MessageID)
+
+	listed, err := sessions.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	_, err = sessions.Fork(ctx, sess.ID, "missing")
+	require.Error(t, err)
+	listed, err = sessions.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+}
@@ -0,0 +1,255 @@
+package shell
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const (
+	// backgroundOutputLimit is the most output of a background job kept
+	// until it is read, per stream. Older output is dropped.
+	backgroundOutputLimit = 1024 * 1024
+	// backgroundKillTimeout is how long killing a background job waits for
+	// it to stop.
+	backgroundKillTimeout = 5 * time.Second
+	// backgroundHistoryLimit is how many ended background jobs are kept.
+	// Older ones are forgotten along with their output.
+	backgroundHistoryLimit = 50
+)
+
+type BackgroundState string
+
+const (
+	BackgroundRunning BackgroundState = "running"
+	BackgroundExited  BackgroundState = "exited"
+	BackgroundKilled  BackgroundState = "killed"
+)
+
+// BackgroundJob describes a command running in the background.
+type BackgroundJob struct {
+	ID         string
+	Command    string
+	WorkingDir string
+	State      BackgroundState
+	ExitCode   int
+	StartedAt  time.Time
+	EndedAt    time.Time
+}
+
+// backgroundProcess is a background job with the output not read yet.
+type backgroundProcess struct {
+	mu     sync.Mutex
+	job    BackgroundJob
+	stdout backgroundOutput
+	stderr backgroundOutput
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// backgroundOutput is the output of a stream not read yet.
+type backgroundOutput struct {
+	unread  []byte
+	dropped int
+}
+
+// backgroundWriter appends to an output of a background process.
+type backgroundWriter struct {
+	process *backgroundProcess
+	output  *backgroundOutput
+}
+
+func (w backgroundWriter) Write(p []byte) (int, error) {
+	w.process.mu.Lock()
+	defer w.process.mu.Unlock()
+	o := w.output
+	o.unread = append(o.unread, p...)
+	if extra := len(o.unread) - backgroundOutputLimit; extra > 0 {
+		o.unread = o.unread[:copy(o.unread, o.unread[extra:])]
+		o.dropped += extra
+	}
+	return len(p), nil
+}
+
+// read returns the output not read yet.
+func (o *backgroundOutput) read() string {
+	output := string(o.unread)
+	if o.dropped > 0 {
+		output = fmt.Sprintf("[%d bytes of earlier output dropped]\n", o.dropped) + output
+	}
+	o.unread = nil
+	o.dropped = 0
+	return output
+}
+
+var (
+	backgroundIDs       atomic.Int64
+	backgroundProcesses = csync.NewMap[string, *backgroundProcess]()
+	backgroundBroker    = pubsub.NewBroker[BackgroundJob]()
+)
+
+// ExecBackground starts a command in the background. It runs in a copy of
+// the shell, so it does not change the working directory or the environment
+// of the shell.
+func (s *Shell) ExecBackground(command string) (BackgroundJob, error) {
+	line, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return BackgroundJob{}, fmt.Errorf("could not parse command: %w", err)
+	}
+
+	s.mu.Lock()
+	sh := s.clone()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &backgroundProcess{
+		job: BackgroundJob{
+			ID:         fmt.Sprintf("bash_%d", backgroundIDs.Add(1)),
+			Command:    command,
+			WorkingDir: sh.cwd,
+			State:      BackgroundRunning,
+			StartedAt:  time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	job := p.job
+	backgroundProcesses.Set(job.ID, p)
+	backgroundBroker.Publish(pubsub.CreatedEvent, job)
+
+	go func() {
+		defer close(p.done)
+		defer cancel()
+		err := sh.runPOSIX(ctx, line, nil, backgroundWriter{p, &p.stdout}, backgroundWriter{p, &p.stderr})
+		sh.logger.InfoPersist("POSIX background command finished", "command", command, "err", err)
+
+		p.mu.Lock()
+		var status interp.ExitStatus
+		if err != nil && !IsInterrupt(err) && !errors.As(err, &status) {
+			p.stderr.unread = append(p.stderr.unread, err.Error()+"\n"...)
+		}
+		p.job.EndedAt = time.Now()
+		p.job.ExitCode = ExitCode(err)
+		if p.job.State == BackgroundRunning {
+			p.job.State = BackgroundExited
+		}
+		job := p.job
+		p.mu.Unlock()
+		backgroundBroker.Publish(pubsub.UpdatedEvent, job)
+		pruneBackgroundJobs()
+	}()
+	return job, nil
+}
+
+// pruneBackgroundJobs forgets the oldest ended background jobs beyond the
+// history limit.
+func pruneBackgroundJobs() {
+	var ended []BackgroundJob
+	for _, job := range BackgroundJobs() {
+		if !job.EndedAt.IsZero() {
+			ended = append(ended, job)
+		}
+	}
+	if len(ended) <= backgroundHistoryLimit {
+		return
+	}
+	slices.SortFunc(ended, func(a, b BackgroundJob) int {
+		return a.EndedAt.Compare(b.EndedAt)
+	})
+	for _, job := range ended[:len(ended)-backgroundHistoryLimit] {
+		backgroundProcesses.Del(job.ID)
+		backgroundBroker.Publish(pubsub.DeletedEvent, job)
+	}
+}
+
+func getBackgroundProcess(id string) (*backgroundProcess, error) {
+	p, ok := backgroundProcesses.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("background process %s not found", id)
+	}
+	return p, nil
+}
+
+// BackgroundOutput returns a background job with its output since the last
+// call.
+func BackgroundOutput(id string) (job BackgroundJob, stdout, stderr string, err error) {
+	p, err := getBackgroundProcess(id)
+	if err != nil {
+		return BackgroundJob{}, "", "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.job, p.stdout.read(), p.stderr.read(), nil
+}
+
+// KillBackground stops a background job and waits for it to exit.
+func KillBackground(id string) (BackgroundJob, error) {
+	p, err := getBackgroundProcess(id)
+	if err != nil {
+		return BackgroundJob{}, err
+	}
+	p.kill()
+	select {
+	case <-p.done:
+	case <-time.After(backgroundKillTimeout):
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.job, nil
+}
+
+func (p *backgroundProcess) kill() {
+	p.mu.Lock()
+	if p.job.State == BackgroundRunning {
+		p.job.State = BackgroundKilled
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// BackgroundJobs returns the background jobs, in the order they started.
+func BackgroundJobs() []BackgroundJob {
+	var jobs []BackgroundJob
+	for p := range backgroundProcesses.Seq() {
+		p.mu.Lock()
+		jobs = append(jobs, p.job)
+		p.mu.Unlock()
+	}
+	slices.SortFunc(jobs, func(a, b BackgroundJob) int {
+		return cmp.Compare(a.StartedAt.UnixNano(), b.StartedAt.UnixNano())
+	})
+	return jobs
+}
+
+// SubscribeBackgroundEvents returns a channel for the background jobs
+// starting and ending.
+func SubscribeBackgroundEvents(ctx context.Context) <-chan pubsub.Event[BackgroundJob] {
+	return backgroundBroker.Subscribe(ctx)
+}
+
+// KillBackgroundJobs stops the running background jobs and waits for them to
+// exit. This should be called during application shutdown.
+func KillBackgroundJobs() {
+	var wg sync.WaitGroup
+	for p := range backgroundProcesses.Seq() {
+		p.kill()
+		wg.Go(func() {
+			select {
+			case <-p.done:
+			case <-time.After(backgroundKillTimeout):
+			}
+		})
+	}
+	wg.Wait()
+}
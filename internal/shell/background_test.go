@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecBackground(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	shell := NewShell(&Options{WorkingDir: dir})
+
+	job, err := shell.ExecBackground("cd / && echo out && echo err >&2 && exit 3")
+	require.NoError(t, err)
+	require.Equal(t, BackgroundRunning, job.State)
+	require.Equal(t, dir, job.WorkingDir)
+	var stdout, stderr string
+	require.Eventually(t, func() bool {
+		var out, errOut string
+		job, out, errOut, err = BackgroundOutput(job.ID)
+		stdout += out
+		stderr += errOut
+		return err == nil && job.State == BackgroundExited
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, job.ExitCode)
+	require.Equal(t, "out\n", stdout)
+	require.Equal(t, "err\n", stderr)
+	// The output is only returned once.
+	_, stdout, stderr, err = BackgroundOutput(job.ID)
+	require.NoError(t, err)
+	require.Empty(t, stdout)
+	require.Empty(t, stderr)
+	// Background jobs do not change the shell.
+	require.Equal(t, dir, shell.GetWorkingDir())
+
+	job, err = shell.ExecBackground("echo started && sleep 30")
+	require.NoError(t, err)
+	var output string
+	require.Eventually(t, func() bool {
+		_, stdout, _, err := BackgroundOutput(job.ID)
+		output += stdout
+		return err == nil && output == "started\n"
+	}, 5*time.Second, 10*time.Millisecond)
+	job, err = KillBackground(job.ID)
+	require.NoError(t, err)
+	require.Equal(t, BackgroundKilled, job.State)
+	require.False(t, job.EndedAt.IsZero())
+	require.Contains(t, BackgroundJobs(), job)
+
+	_, _, _, err = BackgroundOutput("bash_missing")
+	require.Error(t, err)
+	_, err = shell.ExecBackground("echo 'unterminated")
+	require.Error(t, err)
+}
+
+func TestBackgroundHistoryLimit(t *testing.T) {
+	shell := NewShell(&Options{WorkingDir: t.TempDir()})
+
+	first, err := shell.ExecBackground("true")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, _, _, err := BackgroundOutput(first.ID)
+		return err == nil && job.State == BackgroundExited
+	}, 5*time.Second, 10*time.Millisecond)
+	for range backgroundHistoryLimit {
+		_, err := shell.ExecBackground("true")
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		_, _, _, err := BackgroundOutput(first.ID)
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.LessOrEqual(t, len(BackgroundJobs()), backgroundHistoryLimit)
+}
@@ -16,10 +16,11 @@ package shell
 //	shell.Exec(ctx, "export FOO=bar")
 //	shell.Exec(ctx, "echo $FOO")  // Will print "bar"
 //
-// 3. For the singleton persistent shell (used by tools):
+// 3. For the persistent shell of a session (used by tools):
 //
-//	shell := shell.GetPersistentShell("/path/to/cwd")
-//	stdout, stderr, err := shell.Exec(ctx, "ls -la")
+//	shell.ConfigureSessionShells("/path/to/cwd", nil, nil)
+//	sh := shell.GetSessionShell(sessionID)
+//	stdout, stderr, err := sh.Exec(ctx, "ls -la")
 //
 // 4. Managing environment and working directory:
 //
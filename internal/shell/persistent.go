@@ -3,30 +3,89 @@ package shell
 import (
 	"log/slog"
 	"sync"
+
+	"github.com/charmbracelet/crush/internal/csync"
 )
 
-// PersistentShell is a singleton shell instance that maintains state across the application
+// PersistentShell is the shell of a session, keeping its working directory
+// and environment across the commands of the session
 type PersistentShell struct {
 	*Shell
 }
 
 var (
-	once          sync.Once
-	shellInstance *PersistentShell
+	sessionShells = csync.NewMap[string, *PersistentShell]()
+
+	sessionShellsMu   sync.Mutex
+	sessionShellsOpts = Options{Logger: &loggingAdapter{}}
 )
 
-// GetPersistentShell returns the singleton persistent shell instance
-// This maintains backward compatibility with the existing API
-func GetPersistentShell(cwd string) *PersistentShell {
-	once.Do(func() {
-		shellInstance = &PersistentShell{
-			Shell: NewShell(&Options{
-				WorkingDir: cwd,
-				Logger:     &loggingAdapter{},
-			}),
-		}
-	})
-	return shellInstance
+// ConfigureSessionShells sets up the shells of the sessions. New shells start
+// in workingDir, and all shells block the commands matched by blockFuncs and
+// run them in the sandbox, or without one when nil.
+func ConfigureSessionShells(workingDir string, blockFuncs []BlockFunc, sandbox *Sandbox) {
+	sessionShellsMu.Lock()
+	defer sessionShellsMu.Unlock()
+	sessionShellsOpts.WorkingDir = workingDir
+	sessionShellsOpts.BlockFuncs = blockFuncs
+	sessionShellsOpts.Sandbox = sandbox
+	for sh := range sessionShells.Seq() {
+		sh.SetBlockFuncs(blockFuncs)
+		sh.SetSandbox(sandbox)
+	}
+}
+
+// GetSessionShell returns the shell of a session, starting a new one when the
+// session has none yet
+func GetSessionShell(sessionID string) *PersistentShell {
+	sessionShellsMu.Lock()
+	defer sessionShellsMu.Unlock()
+	return sessionShells.GetOrSet(sessionID, newSessionShell)
+}
+
+// LookupSessionShell returns the shell of a session, if it has one
+func LookupSessionShell(sessionID string) (*PersistentShell, bool) {
+	return sessionShells.Get(sessionID)
+}
+
+// InheritSessionShell starts the shell of a session as a copy of the shell of
+// its parent session, in the same working directory and with the same
+// environment. Changes in either shell do not affect the other. It does
+// nothing when the parent session has no shell.
+func InheritSessionShell(parentID, sessionID string) {
+	sessionShellsMu.Lock()
+	defer sessionShellsMu.Unlock()
+	parent, ok := sessionShells.Get(parentID)
+	if !ok {
+		return
+	}
+	parent.mu.Lock()
+	sh := parent.clone()
+	parent.mu.Unlock()
+	sessionShells.Set(sessionID, &PersistentShell{Shell: sh})
+}
+
+// ResetSessionShell replaces the shell of a session with a new one, in the
+// initial working directory and environment
+func ResetSessionShell(sessionID string) *PersistentShell {
+	sessionShellsMu.Lock()
+	defer sessionShellsMu.Unlock()
+	sh := newSessionShell()
+	sessionShells.Set(sessionID, sh)
+	return sh
+}
+
+// RemoveSessionShell forgets the shell of a session. This should be called
+// when the session ends.
+func RemoveSessionShell(sessionID string) {
+	sessionShells.Del(sessionID)
+}
+
+// newSessionShell starts a shell with the session shell options. The caller
+// must hold sessionShellsMu.
+func newSessionShell() *PersistentShell {
+	opts := sessionShellsOpts
+	return &PersistentShell{Shell: NewShell(&opts)}
 }
 
 // slog.dapter adapts the internal slog.package to the Logger interface
@@ -0,0 +1,42 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionShells(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ConfigureSessionShells(dir, nil, nil)
+
+	first := GetSessionShell("session-shells-first")
+	require.Same(t, first, GetSessionShell("session-shells-first"))
+	_, _, err := first.Exec(t.Context(), "mkdir sub && cd sub && export FOO=bar")
+	require.NoError(t, err)
+
+	// Sessions do not share their working directory or environment.
+	second := GetSessionShell("session-shells-second")
+	require.Equal(t, dir, second.GetWorkingDir())
+	stdout, _, err := second.Exec(t.Context(), "echo $FOO")
+	require.NoError(t, err)
+	require.Equal(t, "\n", stdout)
+
+	// Child sessions start where the parent is, without changing it.
+	InheritSessionShell("session-shells-first", "session-shells-child")
+	child, ok := LookupSessionShell("session-shells-child")
+	require.True(t, ok)
+	stdout, _, err = child.Exec(t.Context(), "pwd && echo $FOO && cd ..")
+	require.NoError(t, err)
+	require.Equal(t, dir+"/sub\nbar\n", stdout)
+	require.Equal(t, dir+"/sub", first.GetWorkingDir())
+	RemoveSessionShell("session-shells-child")
+	_, ok = LookupSessionShell("session-shells-child")
+	require.False(t, ok)
+
+	reset := ResetSessionShell("session-shells-first")
+	require.NotSame(t, first, reset)
+	require.Equal(t, dir, reset.GetWorkingDir())
+}
@@ -0,0 +1,122 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/interp"
+)
+
+// ErrSandboxDenied is returned when the shell opens a file for writing
+// outside of the sandbox.
+var ErrSandboxDenied = errors.New("permission denied by the sandbox")
+
+// Sandbox restricts what the commands run by a shell can do. It is only
+// enforced on Linux, where SandboxAvailable reports whether the kernel
+// supports it.
+type Sandbox struct {
+	// WritablePaths are the paths commands can write to, on top of the
+	// temporary directories.
+	WritablePaths []string
+	// AllowNetwork lets commands open TCP connections and listen on TCP
+	// ports. Landlock does not restrict other sockets, so UDP, raw and Unix
+	// sockets are always allowed.
+	AllowNetwork bool
+}
+
+// sandboxDevices are the device files commands can always write to.
+var sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/tty", "/dev/pts", "/dev/shm"}
+
+// writablePaths returns the resolved paths commands can write to.
+func (sb *Sandbox) writablePaths() []string {
+	paths := append([]string{os.TempDir(), "/tmp", "/var/tmp"}, sandboxDevices...)
+	paths = append(paths, sb.WritablePaths...)
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			path = real
+		}
+		resolved = append(resolved, filepath.Clean(path))
+	}
+	return resolved
+}
+
+// canWrite reports whether commands can write to the absolute path.
+func (sb *Sandbox) canWrite(path string) bool {
+	// The links are resolved so that they cannot point out of the sandbox.
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	} else if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	for _, writable := range sb.writablePaths() {
+		rel, err := filepath.Rel(writable, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// openHandler denies the redirections writing outside of the sandbox, which
+// the interpreter opens itself.
+func (sb *Sandbox) openHandler(ctx context.Context, path string, flag int, perm os.FileMode) (io.ReadWriteCloser, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		abs := path
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(interp.HandlerCtx(ctx).Dir, abs)
+		}
+		if !sb.canWrite(abs) {
+			return nil, &os.PathError{Op: "open", Path: path, Err: ErrSandboxDenied}
+		}
+	}
+	return interp.DefaultOpenHandler()(ctx, path, flag, perm)
+}
+
+// sandboxViolations are the errors of commands denied by the sandbox.
+var sandboxViolations = []string{
+	"permission denied",
+	"operation not permitted",
+	"read-only file system",
+}
+
+// sandboxNetworkViolations are the errors of commands denied the network.
+var sandboxNetworkViolations = []string{
+	"network is unreachable",
+	"could not resolve host",
+	"temporary failure in name resolution",
+	"name or service not known",
+	"no such host",
+}
+
+// Explain returns a note for the model when the error output of a failed
+// command looks like the sandbox denied it, or an empty string.
+func (sb *Sandbox) Explain(stderr string) string {
+	stderr = strings.ToLower(stderr)
+	violations := sandboxViolations
+	if !sb.AllowNetwork {
+		violations = slices.Concat(violations, sandboxNetworkViolations)
+	}
+	for _, violation := range violations {
+		if !strings.Contains(stderr, violation) {
+			continue
+		}
+		network := "denies TCP network access, though not UDP or Unix sockets"
+		if sb.AllowNetwork {
+			network = "allows network access"
+		}
+		writable := append(slices.Clone(sb.WritablePaths), "the temporary directories")
+		return fmt.Sprintf(
+			"The command runs in a sandbox that only allows writes to %s, and %s. "+
+				"The failure above is likely a sandbox violation: do not retry the command as is; work around it or ask the user to change the sandbox settings.",
+			strings.Join(writable, ", "), network,
+		)
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+//go:build linux
+
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// sandboxKillTimeout is how long an interrupted command has to stop before
+// it is killed, as with the default exec handler of the interpreter.
+const sandboxKillTimeout = 2 * time.Second
+
+const (
+	// landlockFileAccess are the write rights on files.
+	landlockFileAccess = unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	// landlockWriteAccess are the write rights on files and directories.
+	landlockWriteAccess = landlockFileAccess |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM |
+		unix.LANDLOCK_ACCESS_FS_REFER
+	// landlockNetAccess are the TCP rights.
+	landlockNetAccess = unix.LANDLOCK_ACCESS_NET_BIND_TCP | unix.LANDLOCK_ACCESS_NET_CONNECT_TCP
+)
+
+// landlockABI returns the version of the Landlock ABI of the kernel, or 0
+// when Landlock is not available.
+var landlockABI = sync.OnceValue(func() int {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0
+	}
+	return int(abi)
+})
+
+// SandboxAvailable reports whether the kernel supports the sandbox.
+func SandboxAvailable() bool {
+	return landlockABI() > 0
+}
+
+// restrictThread restricts the writes of the calling thread, and of the
+// processes it starts, to the writable paths. Unless the network is allowed,
+// TCP connections and listening sockets are denied too. The thread must be
+// locked and never be unlocked.
+func (sb *Sandbox) restrictThread() error {
+	abi := landlockABI()
+	if abi < 4 && !sb.AllowNetwork {
+		return errors.New("denying the network needs Linux 6.7 or later, allow the network in the sandbox settings")
+	}
+	attr := unix.LandlockRulesetAttr{Access_fs: landlockWriteAccess}
+	if abi < 2 {
+		attr.Access_fs &^= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi < 3 {
+		attr.Access_fs &^= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if !sb.AllowNetwork {
+		attr.Access_net = landlockNetAccess
+	}
+
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	for _, path := range sb.writablePaths() {
+		if err := addLandlockRule(int(ruleset), path, attr.Access_fs); err != nil {
+			return err
+		}
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no new privileges: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to enforce landlock ruleset: %w", errno)
+	}
+	return nil
+}
+
+// addLandlockRule allows the access beneath a path. Missing paths are
+// skipped.
+func addLandlockRule(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to add landlock rule for %s: %w", path, errno)
+	}
+	return nil
+}
+
+// execHandler runs the commands in the sandbox. It replaces the default exec
+// handler of the interpreter, so it never calls the next handler.
+func (sb *Sandbox) execHandler(interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+	return func(ctx context.Context, args []string) error {
+		hc := interp.HandlerCtx(ctx)
+		path, err := interp.LookPathDir(hc.Dir, hc.Env, args[0])
+		if err != nil {
+			fmt.Fprintln(hc.Stderr, err)
+			return interp.ExitStatus(127)
+		}
+		cmd := &exec.Cmd{
+			Path:        path,
+			Args:        args,
+			Env:         execEnv(hc.Env),
+			Dir:         hc.Dir,
+			Stdin:       hc.Stdin,
+			Stdout:      hc.Stdout,
+			Stderr:      hc.Stderr,
+			SysProcAttr: &syscall.SysProcAttr{Setpgid: true},
+		}
+
+		// Landlock restricts the thread the command is started from. The
+		// thread is never unlocked, so it exits with the goroutine.
+		errc := make(chan error, 1)
+		go func() {
+			runtime.LockOSThread()
+			if err := sb.restrictThread(); err != nil {
+				errc <- fmt.Errorf("failed to set up the sandbox: %w", err)
+				return
+			}
+			if err := cmd.Start(); err != nil {
+				errc <- err
+				return
+			}
+			stop := context.AfterFunc(ctx, func() {
+				_ = unix.Kill(-cmd.Process.Pid, unix.SIGINT)
+				time.Sleep(sandboxKillTimeout)
+				_ = unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+			})
+			defer stop()
+			errc <- cmd.Wait()
+		}()
+		err = <-errc
+
+		var exitErr *exec.ExitError
+		var execErr *exec.Error
+		switch {
+		case errors.As(err, &exitErr):
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return interp.ExitStatus(128 + status.Signal())
+			}
+			return interp.ExitStatus(exitErr.ExitCode())
+		case errors.As(err, &execErr):
+			fmt.Fprintf(hc.Stderr, "%v\n", err)
+			return interp.ExitStatus(127)
+		}
+		return err
+	}
+}
+
+// execEnv returns the exported variables of the shell, as the interpreter
+// passes them to the commands it runs.
+func execEnv(env expand.Environ) []string {
+	list := make([]string, 0, 64)
+	for name, vr := range env.Each {
+		if !vr.IsSet() {
+			// The variable may be set globally but unset in the shell.
+			for i, kv := range list {
+				if strings.HasPrefix(kv, name+"=") {
+					list[i] = ""
+				}
+			}
+		}
+		if vr.Exported && vr.Kind == expand.String {
+			list = append(list, name+"="+vr.String())
+		}
+	}
+	return list
+}
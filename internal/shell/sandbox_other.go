@@ -0,0 +1,14 @@
+//go:build !linux
+
+package shell
+
+import "mvdan.cc/sh/v3/interp"
+
+// SandboxAvailable reports whether the kernel supports the sandbox.
+func SandboxAvailable() bool {
+	return false
+}
+
+func (sb *Sandbox) execHandler(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+	return next
+}
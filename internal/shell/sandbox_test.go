@@ -0,0 +1,53 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox(t *testing.T) {
+	if !SandboxAvailable() {
+		t.Skip("Sandbox not supported on this system")
+	}
+	t.Parallel()
+
+	workingDir := t.TempDir()
+	// The temporary directories are writable, the package directory is not.
+	outside, err := os.MkdirTemp(".", "sandbox-test-")
+	require.NoError(t, err)
+	outside, err = filepath.Abs(outside)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(outside) })
+
+	sandbox := &Sandbox{WritablePaths: []string{workingDir}}
+	shell := NewShell(&Options{WorkingDir: workingDir, Sandbox: sandbox})
+
+	_, _, err = shell.Exec(t.Context(), "touch inside && echo hello > inside && cat inside > /dev/null")
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(workingDir, "inside"))
+
+	// Commands cannot write outside of the sandbox.
+	_, stderr, err := shell.Exec(t.Context(), "touch "+filepath.Join(outside, "touched"))
+	require.NotZero(t, ExitCode(err))
+	require.NoFileExists(t, filepath.Join(outside, "touched"))
+	require.NotEmpty(t, sandbox.Explain(stderr))
+
+	// Neither can the redirections, even through links.
+	_, stderr, err = shell.Exec(t.Context(), "echo hello > "+filepath.Join(outside, "redirected"))
+	require.NotZero(t, ExitCode(err))
+	require.Contains(t, stderr, ErrSandboxDenied.Error())
+	require.NoError(t, os.Symlink(outside, filepath.Join(workingDir, "link")))
+	_, _, err = shell.Exec(t.Context(), "echo hello > link/redirected")
+	require.NotZero(t, ExitCode(err))
+	require.NoFileExists(t, filepath.Join(outside, "redirected"))
+
+	// Reads are not restricted.
+	stdout, _, err := shell.Exec(t.Context(), "cat "+filepath.Join(workingDir, "inside"))
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", stdout)
+
+	require.Empty(t, sandbox.Explain("main.go:3: undefined: foo"))
+}
@@ -2,7 +2,7 @@
 //
 // This package offers two main types:
 // - Shell: A general-purpose shell executor for one-off or managed commands
-// - PersistentShell: A singleton shell that maintains state across the application
+// - PersistentShell: The shell of a session, which maintains state across its commands
 //
 // WINDOWS COMPATIBILITY:
 // This implementation provides both POSIX shell emulation (mvdan.cc/sh/v3),
@@ -15,6 +15,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"slices"
 	"strings"
@@ -56,6 +57,7 @@ type Shell struct {
 	mu         sync.Mutex
 	logger     Logger
 	blockFuncs []BlockFunc
+	sandbox    *Sandbox
 }
 
 // Options for creating a new shell
@@ -64,6 +66,7 @@ type Options struct {
 	Env        []string
 	Logger     Logger
 	BlockFuncs []BlockFunc
+	Sandbox    *Sandbox
 }
 
 // NewShell creates a new shell instance with the given options
@@ -92,6 +95,7 @@ func NewShell(opts *Options) *Shell {
 		env:        env,
 		logger:     logger,
 		blockFuncs: opts.BlockFuncs,
+		sandbox:    opts.Sandbox,
 	}
 }
 
@@ -100,7 +104,28 @@ func (s *Shell) Exec(ctx context.Context, command string) (string, string, error
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.execPOSIX(ctx, command)
+	return s.execPOSIX(ctx, command, nil)
+}
+
+// ExecInput executes a command in the shell, reading its standard input from
+// stdin
+func (s *Shell) ExecInput(ctx context.Context, command string, stdin io.Reader) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.execPOSIX(ctx, command, stdin)
+}
+
+// clone returns a copy of the shell, in the same working directory and with
+// the same environment. The caller must hold s.mu.
+func (s *Shell) clone() *Shell {
+	return &Shell{
+		cwd:        s.cwd,
+		env:        slices.Clone(s.env),
+		logger:     s.logger,
+		blockFuncs: s.blockFuncs,
+		sandbox:    s.sandbox,
+	}
 }
 
 // GetWorkingDir returns the current working directory
@@ -157,6 +182,29 @@ func (s *Shell) SetBlockFuncs(blockFuncs []BlockFunc) {
 	s.blockFuncs = blockFuncs
 }
 
+// SetSandbox sets the sandbox of the commands run by the shell, or disables
+// it when nil
+func (s *Shell) SetSandbox(sandbox *Sandbox) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sandbox = sandbox
+}
+
+// ExplainFailure returns a note for the model when the error output of a
+// failed command looks like the sandbox denied it, or an empty string
+func (s *Shell) ExplainFailure(stderr string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.sandboxed() {
+		return ""
+	}
+	return s.sandbox.Explain(stderr)
+}
+
+func (s *Shell) sandboxed() bool {
+	return s.sandbox != nil && SandboxAvailable()
+}
+
 // CommandsBlocker creates a BlockFunc that blocks exact command matches
 func CommandsBlocker(cmds []string) BlockFunc {
 	bannedSet := make(map[string]struct{})
@@ -229,22 +277,38 @@ func (s *Shell) blockHandler() func(next interp.ExecHandlerFunc) interp.ExecHand
 }
 
 // execPOSIX executes commands using POSIX shell emulation (cross-platform)
-func (s *Shell) execPOSIX(ctx context.Context, command string) (string, string, error) {
+func (s *Shell) execPOSIX(ctx context.Context, command string, stdin io.Reader) (string, string, error) {
 	line, err := syntax.NewParser().Parse(strings.NewReader(command), "")
 	if err != nil {
 		return "", "", fmt.Errorf("could not parse command: %w", err)
 	}
 
 	var stdout, stderr bytes.Buffer
-	runner, err := interp.New(
-		interp.StdIO(nil, &stdout, &stderr),
+	err = s.runPOSIX(ctx, line, stdin, &stdout, &stderr)
+	s.logger.InfoPersist("POSIX command finished", "command", command, "err", err)
+	return stdout.String(), stderr.String(), err
+}
+
+// runPOSIX runs parsed commands, writing their output as it comes
+func (s *Shell) runPOSIX(ctx context.Context, line *syntax.File, stdin io.Reader, stdout, stderr io.Writer) error {
+	opts := []interp.RunnerOption{
+		interp.StdIO(stdin, stdout, stderr),
 		interp.Interactive(false),
 		interp.Env(expand.ListEnviron(s.env...)),
 		interp.Dir(s.cwd),
-		interp.ExecHandlers(s.blockHandler(), coreutils.ExecHandler),
-	)
+	}
+	if s.sandboxed() {
+		// The core utils would run in this process, out of the sandbox.
+		opts = append(opts,
+			interp.ExecHandlers(s.blockHandler(), s.sandbox.execHandler),
+			interp.OpenHandler(s.sandbox.openHandler),
+		)
+	} else {
+		opts = append(opts, interp.ExecHandlers(s.blockHandler(), coreutils.ExecHandler))
+	}
+	runner, err := interp.New(opts...)
 	if err != nil {
-		return "", "", fmt.Errorf("could not run command: %w", err)
+		return fmt.Errorf("could not run command: %w", err)
 	}
 
 	err = runner.Run(ctx, line)
@@ -253,8 +317,7 @@ func (s *Shell) execPOSIX(ctx context.Context, command string) (string, string,
 	for name, vr := range runner.Vars {
 		s.env = append(s.env, fmt.Sprintf("%s=%s", name, vr.Str))
 	}
-	s.logger.InfoPersist("POSIX command finished", "command", command, "err", err)
-	return stdout.String(), stderr.String(), err
+	return err
 }
 
 // IsInterrupt checks if an error is due to interruption
@@ -0,0 +1,101 @@
+package background
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+)
+
+// RenderOptions contains options for rendering background process lists.
+type RenderOptions struct {
+	MaxWidth    int
+	MaxItems    int
+	ShowSection bool
+	SectionName string
+}
+
+// RenderBackgroundList renders a list of background process status items with
+// the given options.
+func RenderBackgroundList(jobs []shell.BackgroundJob, opts RenderOptions) []string {
+	t := styles.CurrentTheme()
+	jobList := []string{}
+
+	if opts.ShowSection {
+		sectionName := opts.SectionName
+		if sectionName == "" {
+			sectionName = "Background"
+		}
+		section := t.S().Subtle.Render(sectionName)
+		jobList = append(jobList, section, "")
+	}
+
+	if len(jobs) == 0 {
+		jobList = append(jobList, t.S().Base.Foreground(t.Border).Render("None"))
+		return jobList
+	}
+
+	// Show the most recent jobs
+	if opts.MaxItems > 0 && len(jobs) > opts.MaxItems {
+		jobs = jobs[len(jobs)-opts.MaxItems:]
+	}
+
+	for _, job := range jobs {
+		icon := t.ItemOfflineIcon
+		extraContent := ""
+		switch job.State {
+		case shell.BackgroundRunning:
+			icon = t.ItemOnlineIcon
+		case shell.BackgroundExited:
+			if job.ExitCode != 0 {
+				icon = t.ItemErrorIcon
+			}
+			extraContent = t.S().Subtle.Render(fmt.Sprintf("exit %d", job.ExitCode))
+		case shell.BackgroundKilled:
+			extraContent = t.S().Subtle.Render("killed")
+		}
+
+		jobList = append(jobList,
+			core.Status(
+				core.StatusOpts{
+					Icon:         icon.String(),
+					Title:        job.ID,
+					Description:  strings.Join(strings.Fields(job.Command), " "),
+					ExtraContent: extraContent,
+				},
+				opts.MaxWidth,
+			),
+		)
+	}
+
+	return jobList
+}
+
+// RenderBackgroundBlock renders a complete background process block with
+// optional truncation indicator.
+func RenderBackgroundBlock(jobs []shell.BackgroundJob, opts RenderOptions, showTruncationIndicator bool) string {
+	t := styles.CurrentTheme()
+	jobList := RenderBackgroundList(jobs, opts)
+
+	// Add truncation indicator if needed
+	if showTruncationIndicator && opts.MaxItems > 0 && len(jobs) > opts.MaxItems {
+		remaining := len(jobs) - opts.MaxItems
+		if remaining == 1 {
+			jobList = append(jobList, t.S().Base.Foreground(t.FgMuted).Render("…"))
+		} else {
+			jobList = append(jobList,
+				t.S().Base.Foreground(t.FgSubtle).Render(fmt.Sprintf("…and %d more", remaining)),
+			)
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, jobList...)
+	if opts.MaxWidth > 0 {
+		return lipgloss.NewStyle().Width(opts.MaxWidth).Render(content)
+	}
+	return content
+}
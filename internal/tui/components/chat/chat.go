@@ -73,6 +73,10 @@ type messageListCmp struct {
 	lastClickY    int
 	clickCount    int
 	promptQueue   int
+
+	// streaming holds the assistant messages of the session being streamed,
+	// the deltas are applied to them.
+	streaming map[string]message.Message
 }
 
 // New creates a new message list component with custom keybindings
@@ -199,12 +203,20 @@ func (m *messageListCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	case SessionClearedMsg:
 		m.session = session.Session{}
+		m.streaming = nil
 		cmds = append(cmds, m.listCmp.SetItems([]list.Item{}))
 		return m, tea.Batch(cmds...)
+	case util.ThemeChangedMsg:
+		// Render the messages again, the list caches them.
+		cmds = append(cmds, m.listCmp.SetItems(m.listCmp.Items()))
+		return m, tea.Batch(cmds...)
 
 	case pubsub.Event[message.Message]:
 		cmds = append(cmds, m.handleMessageEvent(msg))
 		return m, tea.Batch(cmds...)
+	case pubsub.Event[message.Delta]:
+		cmds = append(cmds, m.handleDelta(msg.Payload))
+		return m, tea.Batch(cmds...)
 
 	case tea.MouseWheelMsg:
 		u, cmd := m.listCmp.Update(msg)
@@ -333,14 +345,68 @@ func (m *messageListCmp) handleMessageEvent(event pubsub.Event[message.Message])
 		}
 		switch event.Payload.Role {
 		case message.Assistant:
+			m.trackStreaming(event.Payload)
 			return m.handleUpdateAssistantMessage(event.Payload)
 		case message.Tool:
 			return m.handleToolMessage(event.Payload)
 		}
+	case pubsub.DeletedEvent:
+		if event.Payload.SessionID != m.session.ID {
+			return nil
+		}
+		return m.handleDeletedMessage(event.Payload)
 	}
 	return nil
 }
 
+// trackStreaming keeps a copy of an assistant message until it finishes, to
+// apply its deltas.
+func (m *messageListCmp) trackStreaming(msg message.Message) {
+	if msg.IsFinished() {
+		delete(m.streaming, msg.ID)
+		return
+	}
+	if m.streaming == nil {
+		m.streaming = make(map[string]message.Message)
+	}
+	m.streaming[msg.ID] = msg.Clone()
+}
+
+// handleDelta applies a delta to the assistant message being streamed. The
+// delta is dropped when deltas before it were missed, the next update of the
+// message catches up.
+func (m *messageListCmp) handleDelta(delta message.Delta) tea.Cmd {
+	msg, ok := m.streaming[delta.MessageID]
+	if !ok || delta.SessionID != m.session.ID || !msg.ApplyDelta(delta) {
+		return nil
+	}
+	m.streaming[msg.ID] = msg
+	return m.handleUpdateAssistantMessage(msg.Clone())
+}
+
+// handleDeletedMessage removes every item rendered for a deleted message:
+// the message itself, its tool calls and its assistant info section.
+func (m *messageListCmp) handleDeletedMessage(msg message.Message) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, item := range m.listCmp.Items() {
+		switch item := item.(type) {
+		case messages.MessageCmp:
+			if item.GetMessage().ID == msg.ID {
+				cmds = append(cmds, m.listCmp.DeleteItem(item.ID()))
+			}
+		case messages.ToolCallCmp:
+			if item.ParentMessageID() == msg.ID {
+				cmds = append(cmds, m.listCmp.DeleteItem(item.ID()))
+			}
+		case messages.AssistantSection:
+			if item.MessageID() == msg.ID {
+				cmds = append(cmds, m.listCmp.DeleteItem(item.ID()))
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 // messageExists checks if a message with the given ID already exists in the list.
 func (m *messageListCmp) messageExists(messageID string) bool {
 	items := m.listCmp.Items()
@@ -514,6 +580,7 @@ func (m *messageListCmp) updateOrAddToolCall(msg message.Message, tc message.Too
 // handleNewAssistantMessage processes new assistant messages and their tool calls.
 func (m *messageListCmp) handleNewAssistantMessage(msg message.Message) tea.Cmd {
 	var cmds []tea.Cmd
+	m.trackStreaming(msg)
 
 	// Add assistant message if it should be displayed
 	if m.shouldShowAssistantMessage(msg) {
@@ -541,6 +608,7 @@ func (m *messageListCmp) SetSession(session session.Session) tea.Cmd {
 	}
 
 	m.session = session
+	m.streaming = nil
 	sessionMessages, err := m.app.Messages.List(context.Background(), session.ID)
 	if err != nil {
 		return util.ReportError(err)
@@ -18,6 +18,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
@@ -196,7 +197,7 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.repositionCompletions
 	case filepicker.FilePickedMsg:
 		if len(m.attachments) >= maxAttachments {
-			return m, util.ReportError(fmt.Errorf("cannot add more than %d images", maxAttachments))
+			return m, util.ReportError(fmt.Errorf("cannot add more than %d attachments", maxAttachments))
 		}
 		m.attachments = append(m.attachments, msg.Attachment)
 		return m, nil
@@ -226,6 +227,20 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.completionsStartIndex = 0
 			}
 		}
+		if resource, ok := msg.Value.(agent.MCPResource); ok {
+			if msg.Insert {
+				return m, nil
+			}
+			// Resources are attached rather than inserted, so remove the query
+			value := m.textarea.Value()
+			value = value[:m.completionsStartIndex] + value[m.completionsStartIndex+len(m.textarea.Word()):]
+			m.textarea.SetValue(value)
+			m.textarea.MoveToEnd()
+			m.isCompletionsOpen = false
+			m.currentQuery = ""
+			m.completionsStartIndex = 0
+			return m, attachMCPResource(resource)
+		}
 
 	case OpenExternalEditorMsg:
 		if m.app.CoderAgent.IsSessionBusy(m.session.ID) {
@@ -288,18 +303,28 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case util.ToggleYoloModeMsg:
 		m.setEditorPrompt()
 		return m, nil
+	case util.ThemeChangedMsg:
+		m.textarea.SetStyles(styles.CurrentTheme().S().TextArea)
+		m.setEditorPrompt()
+		return m, nil
 	case tea.KeyPressMsg:
 		cur := m.textarea.Cursor()
 		curIdx := m.textarea.Width()*cur.Y + cur.X
 		switch {
 		// Completions
-		case msg.String() == "/" && !m.isCompletionsOpen &&
+		case key.Matches(msg, m.keyMap.AddFile) && !m.isCompletionsOpen &&
 			// only show if beginning of prompt, or if previous char is a space or newline:
 			(len(m.textarea.Value()) == 0 || unicode.IsSpace(rune(m.textarea.Value()[len(m.textarea.Value())-1]))):
 			m.isCompletionsOpen = true
 			m.currentQuery = ""
 			m.completionsStartIndex = curIdx
 			cmds = append(cmds, m.startCompletions)
+			if msg.String() != "/" {
+				// Completions filter the word after "/", so type it when the
+				// key is remapped.
+				m.textarea.InsertString("/")
+				return m, tea.Batch(cmds...)
+			}
 		case m.isCompletionsOpen && curIdx <= m.completionsStartIndex:
 			cmds = append(cmds, util.CmdHandler(completions.CloseCompletionsMsg{}))
 		}
@@ -527,6 +552,13 @@ func (m *editorCmp) startCompletions() tea.Msg {
 		})
 	}
 
+	for _, resource := range agent.GetMCPResources() {
+		completionItems = append(completionItems, completions.Completion{
+			Title: resource.MCPName + ": " + resource.Resource.Name,
+			Value: resource,
+		})
+	}
+
 	x, y := m.completionsPosition()
 	return completions.OpenCompletionsMsg{
 		Completions: completionItems,
@@ -535,6 +567,17 @@ func (m *editorCmp) startCompletions() tea.Msg {
 	}
 }
 
+// attachMCPResource reads an MCP resource and attaches it to the message.
+func attachMCPResource(resource agent.MCPResource) tea.Cmd {
+	return func() tea.Msg {
+		attachment, err := agent.MCPResourceAttachment(context.Background(), resource)
+		if err != nil {
+			return util.ReportError(err)()
+		}
+		return filepicker.FilePickedMsg{Attachment: attachment}
+	}
+}
+
 // Blur implements Container.
 func (c *editorCmp) Blur() tea.Cmd {
 	c.textarea.Blur()
@@ -2,6 +2,7 @@ package editor
 
 import (
 	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 )
 
 type EditorKeyMap struct {
@@ -13,25 +14,10 @@ type EditorKeyMap struct {
 
 func DefaultEditorKeyMap() EditorKeyMap {
 	return EditorKeyMap{
-		AddFile: key.NewBinding(
-			key.WithKeys("/"),
-			key.WithHelp("/", "add file"),
-		),
-		SendMessage: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "send"),
-		),
-		OpenEditor: key.NewBinding(
-			key.WithKeys("ctrl+o"),
-			key.WithHelp("ctrl+o", "open editor"),
-		),
-		Newline: key.NewBinding(
-			key.WithKeys("shift+enter", "ctrl+j"),
-			// "ctrl+j" is a common keybinding for newline in many editors. If
-			// the terminal supports "shift+enter", we substitute the help text
-			// to reflect that.
-			key.WithHelp("ctrl+j", "newline"),
-		),
+		AddFile:     keybindings.Binding("add_file"),
+		SendMessage: keybindings.Binding("send_message"),
+		OpenEditor:  keybindings.Binding("open_editor"),
+		Newline:     keybindings.Binding("newline"),
 	}
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/crush/internal/lsp/protocol"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/shell"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/lipgloss/v2"
@@ -135,9 +136,15 @@ func (h *header) details(availWidth int) string {
 	metadata := strings.Join(parts, dot)
 	metadata = dot + metadata
 
+	// Show where the shell of the session is, once it has one.
+	workingDir := config.Get().WorkingDir()
+	if sh, ok := shell.LookupSessionShell(h.session.ID); ok {
+		workingDir = sh.GetWorkingDir()
+	}
+
 	// Truncate cwd if necessary, and insert it at the beginning.
 	const dirTrimLimit = 4
-	cwd := fsext.DirTrim(fsext.PrettyPath(config.Get().WorkingDir()), dirTrimLimit)
+	cwd := fsext.DirTrim(fsext.PrettyPath(workingDir), dirTrimLimit)
 	cwd = ansi.Truncate(cwd, max(0, availWidth-lipgloss.Width(metadata)), "…")
 	cwd = s.Muted.Render(cwd)
 
@@ -28,6 +28,12 @@ import (
 // CopyKey is the key binding for copying message content to the clipboard.
 var CopyKey = key.NewBinding(key.WithKeys("c", "y", "C", "Y"), key.WithHelp("c/y", "copy"))
 
+// ForkKey is the key binding for forking the session from the focused message.
+var ForkKey = key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "fork from here"))
+
+// ExpandKey is the key binding for expanding the output of the focused tool call.
+var ExpandKey = key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "expand"))
+
 // ClearSelectionKey is the key binding for clearing the current selection in the chat interface.
 var ClearSelectionKey = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear selection"))
 
@@ -113,6 +119,9 @@ func (m *messageCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				util.ReportInfo("Message copied to clipboard"),
 			)
 		}
+		if key.Matches(msg, ForkKey) && m.message.ID != "" {
+			return m, util.CmdHandler(util.ForkSessionMsg{MessageID: m.message.ID})
+		}
 	}
 	return m, nil
 }
@@ -358,6 +367,7 @@ func (m *messageCmp) Spinning() bool {
 type AssistantSection interface {
 	list.Item
 	layout.Sizeable
+	MessageID() string
 }
 type assistantSectionModel struct {
 	width               int
@@ -371,6 +381,11 @@ func (m *assistantSectionModel) ID() string {
 	return m.id
 }
 
+// MessageID returns the ID of the assistant message the section belongs to.
+func (m *assistantSectionModel) MessageID() string {
+	return m.message.ID
+}
+
 func NewAssistantSection(message message.Message, lastUserMessageTime time.Time) AssistantSection {
 	return &assistantSectionModel{
 		width:               0,
@@ -1,6 +1,7 @@
 package messages
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -163,6 +164,8 @@ func (br baseRenderer) renderError(v *toolCallCmp, message string) string {
 // Register tool renderers
 func init() {
 	registry.register(tools.BashToolName, func() renderer { return bashRenderer{} })
+	registry.register(tools.BashOutputToolName, func() renderer { return bashBackgroundRenderer{name: "Bash Output"} })
+	registry.register(tools.BashKillToolName, func() renderer { return bashBackgroundRenderer{name: "Bash Kill"} })
 	registry.register(tools.DownloadToolName, func() renderer { return downloadRenderer{} })
 	registry.register(tools.ViewToolName, func() renderer { return viewRenderer{} })
 	registry.register(tools.EditToolName, func() renderer { return editRenderer{} })
@@ -174,6 +177,13 @@ func init() {
 	registry.register(tools.LSToolName, func() renderer { return lsRenderer{} })
 	registry.register(tools.SourcegraphToolName, func() renderer { return sourcegraphRenderer{} })
 	registry.register(tools.DiagnosticsToolName, func() renderer { return diagnosticsRenderer{} })
+	registry.register(tools.DefinitionToolName, func() renderer { return navigationRenderer{name: "Definition"} })
+	registry.register(tools.ReferencesToolName, func() renderer { return navigationRenderer{name: "References"} })
+	registry.register(tools.HoverToolName, func() renderer { return navigationRenderer{name: "Hover"} })
+	registry.register(tools.SymbolsToolName, func() renderer { return symbolsRenderer{} })
+	registry.register(tools.CallGraphToolName, func() renderer { return callGraphRenderer{} })
+	registry.register(tools.RenameToolName, func() renderer { return workspaceEditRenderer{} })
+	registry.register(tools.CodeActionToolName, func() renderer { return workspaceEditRenderer{} })
 	registry.register(agent.AgentToolName, func() renderer { return agentRenderer{} })
 }
 
@@ -211,7 +221,7 @@ func (br bashRenderer) Render(v *toolCallCmp) string {
 
 	cmd := strings.ReplaceAll(params.Command, "\n", " ")
 	cmd = strings.ReplaceAll(cmd, "\t", "    ")
-	args := newParamBuilder().addMain(cmd).build()
+	args := newParamBuilder().addMain(cmd).addFlag("background", params.RunInBackground).build()
 
 	return br.renderWithParams(v, "Bash", args, func() string {
 		var meta tools.BashResponseMetadata
@@ -230,6 +240,30 @@ func (br bashRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Bash background renderer
+// -----------------------------------------------------------------------------
+
+// bashBackgroundRenderer handles reading and stopping background processes
+type bashBackgroundRenderer struct {
+	baseRenderer
+	name string
+}
+
+// Render displays the background process ID and the plain output
+func (br bashBackgroundRenderer) Render(v *toolCallCmp) string {
+	var params tools.BashOutputParams
+	if err := br.unmarshalParams(v.call.Input, &params); err != nil {
+		return br.renderError(v, "Invalid "+br.name+" parameters")
+	}
+
+	args := newParamBuilder().addMain(params.ID).build()
+
+	return br.renderWithParams(v, br.name, args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  View renderer
 // -----------------------------------------------------------------------------
@@ -589,6 +623,208 @@ func (dr diagnosticsRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Navigation renderers
+// -----------------------------------------------------------------------------
+
+// navigationRenderer handles the LSP tools that look up a symbol position
+type navigationRenderer struct {
+	baseRenderer
+	name string
+}
+
+// Render displays the symbol or file position being looked up
+func (nr navigationRenderer) Render(v *toolCallCmp) string {
+	var params tools.NavigationParams
+	var args []string
+	if err := nr.unmarshalParams(v.call.Input, &params); err == nil {
+		main := params.Symbol
+		if params.FilePath != "" {
+			position := fsext.PrettyPath(params.FilePath)
+			if params.Line > 0 {
+				position = fmt.Sprintf("%s:%d", position, params.Line)
+			}
+			if main == "" {
+				main = position
+			} else {
+				args = append(args, "in", position)
+			}
+		}
+		args = append([]string{main}, args...)
+	}
+
+	return nr.renderWithParams(v, nr.name, args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// symbolsRenderer handles file outlines and workspace symbol searches
+type symbolsRenderer struct {
+	baseRenderer
+}
+
+// Render displays the outlined file or the symbol query
+func (sr symbolsRenderer) Render(v *toolCallCmp) string {
+	var params tools.SymbolsParams
+	var args []string
+	if err := sr.unmarshalParams(v.call.Input, &params); err == nil {
+		main := params.Query
+		if params.FilePath != "" {
+			main = fsext.PrettyPath(params.FilePath)
+		}
+		args = newParamBuilder().addMain(main).build()
+	}
+
+	return sr.renderWithParams(v, "Symbols", args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// callGraphRenderer handles call and type hierarchies
+type callGraphRenderer struct {
+	baseRenderer
+}
+
+// Render displays the hierarchy as a tree, showing the first level until the
+// tool call is expanded
+func (cr callGraphRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.CallGraphParams
+	var args []string
+	if err := cr.unmarshalParams(v.call.Input, &params); err == nil {
+		position := fsext.PrettyPath(params.FilePath)
+		if params.Line > 0 {
+			position = fmt.Sprintf("%s:%d", position, params.Line)
+		}
+		args = newParamBuilder().
+			addMain(cmp.Or(params.Symbol, position)).
+			addKeyValue("direction", params.Direction).
+			addKeyValue("depth", formatNonZero(params.Depth)).
+			build()
+	}
+
+	return cr.renderWithParams(v, "Call Graph", args, func() string {
+		var meta tools.CallGraphResponseMetadata
+		if err := cr.unmarshalParams(v.result.Metadata, &meta); err != nil || len(meta.Roots) == 0 {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		width := v.textWidth() - 2 // -2 for left padding
+		hidden := 0
+		var build func(node tools.CallGraphNode, depth int) *tree.Tree
+		build = func(node tools.CallGraphNode, depth int) *tree.Tree {
+			location := fmt.Sprintf("%s %s:%d", node.Kind, node.FilePath, node.Line)
+			if node.Recursive {
+				location += " (recursive)"
+			}
+			label := t.S().Base.Foreground(t.FgBase).Render(node.Name) + " " + t.S().Subtle.Render(location)
+			branch := tree.Root(label).Enumerator(RoundedEnumerator)
+			for _, child := range node.Children {
+				if !v.expanded && depth >= 1 {
+					hidden += countCallGraphNodes(child)
+					continue
+				}
+				branch.Child(build(child, depth+1))
+			}
+			return branch
+		}
+
+		var parts []string
+		for _, root := range meta.Roots {
+			parts = append(parts, build(root, 0).String())
+		}
+		lines := strings.Split(strings.Join(parts, "\n"), "\n")
+		if !v.expanded && len(lines) > responseContextHeight {
+			hidden += len(lines) - responseContextHeight
+			lines = lines[:responseContextHeight]
+		}
+		for i, ln := range lines {
+			lines[i] = t.S().Base.Background(t.BgBaseLighter).Width(width).Render(v.fit(" "+ln, width))
+		}
+		if hidden > 0 {
+			lines = append(lines, t.S().Muted.
+				Background(t.BgBaseLighter).
+				Width(width).
+				Render(fmt.Sprintf("… (%d more, press %s to expand)", hidden, ExpandKey.Help().Key)))
+		}
+		return strings.Join(lines, "\n")
+	})
+}
+
+// countCallGraphNodes returns the number of nodes in a hierarchy.
+func countCallGraphNodes(node tools.CallGraphNode) int {
+	n := 1
+	for _, child := range node.Children {
+		n += countCallGraphNodes(child)
+	}
+	return n
+}
+
+// workspaceEditRenderer handles the LSP tools that change several files
+type workspaceEditRenderer struct {
+	baseRenderer
+}
+
+// Render displays the rename or code action with the diff of every changed file
+func (wr workspaceEditRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var args []string
+	name := prettifyToolName(v.call.Name)
+	switch v.call.Name {
+	case tools.RenameToolName:
+		var params tools.RenameParams
+		if err := wr.unmarshalParams(v.call.Input, &params); err == nil {
+			position := fsext.PrettyPath(params.FilePath)
+			if params.Line > 0 {
+				position = fmt.Sprintf("%s:%d", position, params.Line)
+			}
+			args = newParamBuilder().
+				addMain(cmp.Or(params.Symbol, position)).
+				addKeyValue("to", params.NewName).
+				build()
+		}
+	case tools.CodeActionToolName:
+		var params tools.CodeActionParams
+		if err := wr.unmarshalParams(v.call.Input, &params); err == nil {
+			args = newParamBuilder().
+				addMain(fmt.Sprintf("%s:%d", fsext.PrettyPath(params.FilePath), params.Line)).
+				addKeyValue("apply", formatNonZero(params.Apply)).
+				build()
+		}
+	}
+
+	return wr.renderWithParams(v, name, args, func() string {
+		var meta tools.WorkspaceEditResponseMetadata
+		if err := wr.unmarshalParams(v.result.Metadata, &meta); err != nil || len(meta.Files) == 0 {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		var parts []string
+		for _, file := range meta.Files {
+			formatter := core.DiffFormatter().
+				Before(fsext.PrettyPath(file.FilePath), file.OldContent).
+				After(fsext.PrettyPath(file.FilePath), file.NewContent).
+				Width(v.textWidth() - 2) // -2 for padding
+			if v.textWidth() > 120 {
+				formatter = formatter.Split()
+			}
+			parts = append(parts, formatter.String())
+		}
+		// add a message to the bottom if the content was truncated
+		formatted := strings.Join(parts, "\n")
+		if lipgloss.Height(formatted) > responseContextHeight {
+			contentLines := strings.Split(formatted, "\n")
+			truncateMessage := t.S().Muted.
+				Background(t.BgBaseLighter).
+				PaddingLeft(2).
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("… (%d lines in %d files)", len(contentLines)-responseContextHeight, len(meta.Files)))
+			formatted = strings.Join(contentLines[:responseContextHeight], "\n") + "\n" + truncateMessage
+		}
+		return formatted
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Task renderer
 // -----------------------------------------------------------------------------
@@ -864,8 +1100,14 @@ func prettifyToolName(name string) string {
 	switch name {
 	case agent.AgentToolName:
 		return "Agent"
+	case agent.ReadMCPResourceToolName:
+		return "Read MCP Resource"
 	case tools.BashToolName:
 		return "Bash"
+	case tools.BashOutputToolName:
+		return "Bash Output"
+	case tools.BashKillToolName:
+		return "Bash Kill"
 	case tools.DownloadToolName:
 		return "Download"
 	case tools.EditToolName:
@@ -884,6 +1126,20 @@ func prettifyToolName(name string) string {
 		return "Sourcegraph"
 	case tools.ViewToolName:
 		return "View"
+	case tools.DefinitionToolName:
+		return "Definition"
+	case tools.ReferencesToolName:
+		return "References"
+	case tools.HoverToolName:
+		return "Hover"
+	case tools.SymbolsToolName:
+		return "Symbols"
+	case tools.CallGraphToolName:
+		return "Call Graph"
+	case tools.RenameToolName:
+		return "Rename"
+	case tools.CodeActionToolName:
+		return "Code Action"
 	case tools.WriteToolName:
 		return "Write"
 	default:
@@ -59,6 +59,7 @@ type toolCallCmp struct {
 	cancelled           bool               // Whether the tool call was cancelled
 	permissionRequested bool
 	permissionGranted   bool
+	expanded            bool // Whether the full output is shown
 
 	// Animation state for pending tool calls
 	spinning bool       // Whether to show loading animation
@@ -168,6 +169,12 @@ func (m *toolCallCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if key.Matches(msg, CopyKey) {
 			return m, m.copyTool()
 		}
+		if key.Matches(msg, ForkKey) {
+			return m, util.CmdHandler(util.ForkSessionMsg{MessageID: m.parentMessageID})
+		}
+		if key.Matches(msg, ExpandKey) {
+			m.expanded = !m.expanded
+		}
 	}
 	return m, nil
 }
@@ -391,13 +398,16 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatEditResultForCopy()
 	case tools.MultiEditToolName:
 		return m.formatMultiEditResultForCopy()
+	case tools.RenameToolName, tools.CodeActionToolName:
+		return m.formatWorkspaceEditResultForCopy()
 	case tools.WriteToolName:
 		return m.formatWriteResultForCopy()
 	case tools.FetchToolName:
 		return m.formatFetchResultForCopy()
 	case agent.AgentToolName:
 		return m.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName,
+		tools.DefinitionToolName, tools.ReferencesToolName, tools.HoverToolName, tools.SymbolsToolName, tools.CallGraphToolName:
 		return fmt.Sprintf("```\n%s\n```", m.result.Content)
 	default:
 		return m.result.Content
@@ -542,6 +552,24 @@ func (m *toolCallCmp) formatMultiEditResultForCopy() string {
 	return result.String()
 }
 
+func (m *toolCallCmp) formatWorkspaceEditResultForCopy() string {
+	var meta tools.WorkspaceEditResponseMetadata
+	if m.result.Metadata == "" || json.Unmarshal([]byte(m.result.Metadata), &meta) != nil {
+		return m.result.Content
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Changes: +%d -%d\n", meta.Additions, meta.Removals))
+	result.WriteString("```diff\n")
+	for _, file := range meta.Files {
+		diffContent, _, _ := diff.GenerateDiff(file.OldContent, file.NewContent, fsext.PrettyPath(file.FilePath))
+		result.WriteString(diffContent)
+		result.WriteString("\n")
+	}
+	result.WriteString("```")
+	return result.String()
+}
+
 func (m *toolCallCmp) formatWriteResultForCopy() string {
 	var params tools.WriteParams
 	if json.Unmarshal([]byte(m.call.Input), &params) != nil {
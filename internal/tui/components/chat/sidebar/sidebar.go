@@ -17,6 +17,8 @@ import (
 	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/tui/components/background"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
@@ -44,7 +46,9 @@ const (
 	DefaultMaxFilesShown = 10
 	DefaultMaxLSPsShown  = 8
 	DefaultMaxMCPsShown  = 8
-	MinItemsPerSection   = 2 // Minimum items to show per section
+	// DefaultMaxBackgroundShown is the most background processes shown
+	DefaultMaxBackgroundShown = 5
+	MinItemsPerSection        = 2 // Minimum items to show per section
 )
 
 type SessionFile struct {
@@ -168,6 +172,10 @@ func (m *sidebarCmp) View() string {
 			"",
 			m.mcpBlock(),
 		)
+		// Background processes are only listed once the agent started some
+		if jobs := shell.BackgroundJobs(); len(jobs) > 0 {
+			parts = append(parts, "", m.backgroundBlock(jobs))
+		}
 	}
 
 	return style.Render(
@@ -505,6 +513,15 @@ func (m *sidebarCmp) mcpBlock() string {
 	}, true)
 }
 
+func (m *sidebarCmp) backgroundBlock(jobs []shell.BackgroundJob) string {
+	return background.RenderBackgroundBlock(jobs, background.RenderOptions{
+		MaxWidth:    m.getMaxWidth(),
+		MaxItems:    DefaultMaxBackgroundShown,
+		ShowSection: true,
+		SectionName: core.Section("Background", m.getMaxWidth()),
+	}, true)
+}
+
 func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
 	t := styles.CurrentTheme()
 	// Format tokens in human-readable format (e.g., 110K, 1.2M)
@@ -20,6 +20,7 @@ import (
 	lspcomponent "github.com/charmbracelet/crush/internal/tui/components/lsp"
 	"github.com/charmbracelet/crush/internal/tui/components/mcp"
 	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/crush/internal/version"
@@ -465,7 +466,7 @@ func (s *splashCmp) View() string {
 			bodyStyle.Render("When I initialize your codebase I examine the project and put the"),
 			bodyStyle.Render("result into a CRUSH.md file which serves as general context."),
 			"",
-			bodyStyle.Render("You can also initialize anytime via ")+shortcutStyle.Render("ctrl+p")+bodyStyle.Render("."),
+			bodyStyle.Render("You can also initialize anytime via ")+shortcutStyle.Render(keybindings.Binding("commands").Help().Key)+bodyStyle.Render("."),
 			"",
 			bodyStyle.Render("Would you like to initialize now?"),
 		)
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/lipgloss/v2"
@@ -85,7 +86,7 @@ func (p *panel) View() string {
 		Foreground(t.FgMuted).
 		Padding(0, 1).
 		Margin(0, 0, 0, 2)
-	keyHint := keyHintStyle.Render("shift+tab")
+	keyHint := keyHintStyle.Render(keybindings.Binding("debug_tab").Help().Key)
 
 	tabs := lipgloss.JoinHorizontal(lipgloss.Left, metricsTab, logsTab, keyHint)
 
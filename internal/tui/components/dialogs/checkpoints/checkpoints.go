@@ -0,0 +1,187 @@
+package checkpoints
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+const CheckpointsDialogID dialogs.DialogID = "checkpoints"
+
+// CheckpointSelectedMsg is sent when the user picks a checkpoint to revert
+// the session to.
+type CheckpointSelectedMsg struct {
+	SessionID string
+	MessageID string
+}
+
+// CheckpointsDialog interface for the checkpoint selection dialog
+type CheckpointsDialog interface {
+	dialogs.DialogModel
+}
+
+type CheckpointsList = list.FilterableList[list.CompletionItem[app.Checkpoint]]
+
+type checkpointsDialogCmp struct {
+	wWidth          int
+	wHeight         int
+	width           int
+	sessionID       string
+	keyMap          KeyMap
+	checkpointsList CheckpointsList
+	help            help.Model
+}
+
+// NewCheckpointsDialogCmp creates a new dialog listing the checkpoints of a
+// session, newest first.
+func NewCheckpointsDialogCmp(sessionID string, checkpoints []app.Checkpoint) CheckpointsDialog {
+	t := styles.CurrentTheme()
+	listKeyMap := list.DefaultKeyMap()
+	keyMap := DefaultKeyMap()
+	listKeyMap.Down.SetEnabled(false)
+	listKeyMap.Up.SetEnabled(false)
+	listKeyMap.DownOneItem = keyMap.Next
+	listKeyMap.UpOneItem = keyMap.Previous
+
+	items := make([]list.CompletionItem[app.Checkpoint], 0, len(checkpoints))
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		cp := checkpoints[i]
+		items = append(items, list.NewCompletionItem(checkpointTitle(cp), cp, list.WithCompletionID(cp.MessageID)))
+	}
+
+	inputStyle := t.S().Base.PaddingLeft(1).PaddingBottom(1)
+	checkpointsList := list.NewFilterableList(
+		items,
+		list.WithFilterPlaceholder("Enter a prompt"),
+		list.WithFilterInputStyle(inputStyle),
+		list.WithFilterListOptions(
+			list.WithKeyMap(listKeyMap),
+			list.WithWrapNavigation(),
+		),
+	)
+	help := help.New()
+	help.Styles = t.S().Help
+	return &checkpointsDialogCmp{
+		sessionID:       sessionID,
+		keyMap:          keyMap,
+		checkpointsList: checkpointsList,
+		help:            help,
+	}
+}
+
+func checkpointTitle(cp app.Checkpoint) string {
+	prompt, _, _ := strings.Cut(strings.TrimSpace(cp.Prompt), "\n")
+	return fmt.Sprintf("%s  %s", time.Unix(cp.CreatedAt, 0).Format(time.Kitchen), prompt)
+}
+
+func (c *checkpointsDialogCmp) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	cmds = append(cmds, c.checkpointsList.Init())
+	cmds = append(cmds, c.checkpointsList.Focus())
+	return tea.Sequence(cmds...)
+}
+
+func (c *checkpointsDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.wWidth = msg.Width
+		c.wHeight = msg.Height
+		c.width = min(120, c.wWidth-8)
+		c.checkpointsList.SetInputWidth(c.listWidth() - 2)
+		return c, c.checkpointsList.SetSize(c.listWidth(), c.listHeight())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keyMap.Select):
+			selectedItem := c.checkpointsList.SelectedItem()
+			if selectedItem != nil {
+				selected := (*selectedItem).Value()
+				return c, tea.Sequence(
+					util.CmdHandler(dialogs.CloseDialogMsg{}),
+					util.CmdHandler(CheckpointSelectedMsg{
+						SessionID: c.sessionID,
+						MessageID: selected.MessageID,
+					}),
+				)
+			}
+		case key.Matches(msg, c.keyMap.Close):
+			return c, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			u, cmd := c.checkpointsList.Update(msg)
+			c.checkpointsList = u.(CheckpointsList)
+			return c, cmd
+		}
+	}
+	return c, nil
+}
+
+func (c *checkpointsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	listView := c.checkpointsList.View()
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Revert to Checkpoint", c.width-4)),
+		listView,
+		"",
+		t.S().Base.Width(c.width-2).PaddingLeft(1).AlignHorizontal(lipgloss.Left).Render(c.help.View(c.keyMap)),
+	)
+
+	return c.style().Render(content)
+}
+
+func (c *checkpointsDialogCmp) Cursor() *tea.Cursor {
+	if cursor, ok := c.checkpointsList.(util.Cursor); ok {
+		cursor := cursor.Cursor()
+		if cursor != nil {
+			cursor = c.moveCursor(cursor)
+		}
+		return cursor
+	}
+	return nil
+}
+
+func (c *checkpointsDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(c.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (c *checkpointsDialogCmp) listHeight() int {
+	return c.wHeight/2 - 6 // 5 for the border, title and help
+}
+
+func (c *checkpointsDialogCmp) listWidth() int {
+	return c.width - 2 // 2 for the border
+}
+
+func (c *checkpointsDialogCmp) Position() (int, int) {
+	row := c.wHeight/4 - 2 // just a bit above the center
+	col := c.wWidth / 2
+	col -= c.width / 2
+	return row, col
+}
+
+func (c *checkpointsDialogCmp) moveCursor(cursor *tea.Cursor) *tea.Cursor {
+	row, col := c.Position()
+	offset := row + 3 // Border + title
+	cursor.Y += offset
+	cursor.X = cursor.X + col + 2
+	return cursor
+}
+
+// ID implements CheckpointsDialog.
+func (c *checkpointsDialogCmp) ID() dialogs.DialogID {
+	return CheckpointsDialogID
+}
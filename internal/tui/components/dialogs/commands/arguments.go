@@ -34,10 +34,11 @@ type commandArgumentsDialogCmp struct {
 	commandID  string
 	content    string
 	argNames   []string
+	onSubmit   func(args map[string]string) tea.Cmd
 	help       help.Model
 }
 
-func NewCommandArgumentsDialog(commandID, content string, argNames []string) CommandArgumentsDialog {
+func NewCommandArgumentsDialog(commandID, content string, argNames []string, onSubmit func(args map[string]string) tea.Cmd) CommandArgumentsDialog {
 	t := styles.CurrentTheme()
 	inputs := make([]textinput.Model, len(argNames))
 
@@ -65,6 +66,7 @@ func NewCommandArgumentsDialog(commandID, content string, argNames []string) Com
 		commandID:  commandID,
 		content:    content,
 		argNames:   argNames,
+		onSubmit:   onSubmit,
 		focusIndex: 0,
 		width:      60,
 		help:       help.New(),
@@ -86,6 +88,16 @@ func (c *commandArgumentsDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch {
 		case key.Matches(msg, c.keys.Confirm):
 			if c.focusIndex == len(c.inputs)-1 {
+				if c.onSubmit != nil {
+					args := make(map[string]string, len(c.argNames))
+					for i, name := range c.argNames {
+						args[name] = c.inputs[i].Value()
+					}
+					return c, tea.Sequence(
+						util.CmdHandler(dialogs.CloseDialogMsg{}),
+						c.onSubmit(args),
+					)
+				}
 				content := c.content
 				for i, name := range c.argNames {
 					value := c.inputs[i].Value()
@@ -14,6 +14,7 @@ import (
 	editordialog "github.com/charmbracelet/crush/internal/tui/components/dialogs/editor"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
 )
@@ -109,7 +110,7 @@ func (c *commandDialogCmp) Init() tea.Cmd {
 	if err != nil {
 		return util.ReportError(err)
 	}
-	c.userCommands = commands
+	c.userCommands = append(commands, LoadMCPPrompts()...)
 	return c.SetCommandType(c.commandType)
 }
 
@@ -260,7 +261,7 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			ID:          "new_session",
 			Title:       "New Session",
 			Description: "start a new session",
-			Shortcut:    "ctrl+n",
+			Shortcut:    keybindings.Binding("new_session").Help().Key,
 			Handler: func(cmd Command) tea.Cmd {
 				return util.CmdHandler(util.NewSessionsMsg{})
 			},
@@ -269,7 +270,7 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			ID:          "switch_session",
 			Title:       "Switch Session",
 			Description: "Switch to a different session",
-			Shortcut:    "ctrl+s",
+			Shortcut:    keybindings.Binding("sessions").Help().Key,
 			Handler: func(cmd Command) tea.Cmd {
 				return util.CmdHandler(util.SwitchSessionsMsg{})
 			},
@@ -282,6 +283,14 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				return util.CmdHandler(util.SwitchModelMsg{})
 			},
 		},
+		{
+			ID:          "switch_theme",
+			Title:       "Switch Theme",
+			Description: "Switch to a different theme",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(util.SwitchThemeMsg{})
+			},
+		},
 	}
 
 	// Only show compact command if there's an active session
@@ -314,6 +323,36 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				})
 			},
 		})
+		commands = append(commands, Command{
+			ID:          "revert_checkpoint",
+			Title:       "Revert to Checkpoint",
+			Description: "Restore files and conversation to the state before a prompt",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(util.RevertCheckpointMsg{
+					SessionID: c.sessionID,
+				})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "reset_shell",
+			Title:       "Reset Shell",
+			Description: "Start a new shell for the session in the working directory",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(util.ResetShellMsg{
+					SessionID: c.sessionID,
+				})
+			},
+		})
+		commands = append(commands, Command{
+			ID:          "export_session",
+			Title:       "Export Session",
+			Description: "Save the session transcript as Markdown, JSON or HTML",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(util.ExportSessionMsg{
+					SessionID: c.sessionID,
+				})
+			},
+		})
 	}
 
 	// Only show thinking toggle for Anthropic models that can reason
@@ -356,7 +395,7 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			commands = append(commands, Command{
 				ID:          "file_picker",
 				Title:       "Open File Picker",
-				Shortcut:    "ctrl+f",
+				Shortcut:    keybindings.Binding("add_attachment").Help().Key,
 				Description: "Open file picker",
 				Handler: func(cmd Command) tea.Cmd {
 					return util.CmdHandler(util.OpenFilePickerMsg{})
@@ -384,10 +423,18 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				return util.CmdHandler(util.ToggleYoloModeMsg{})
 			},
 		},
+		{
+			ID:          "manage_permissions",
+			Title:       "Manage Permissions",
+			Description: "List and revoke the permissions that are always allowed",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(util.ManagePermissionsMsg{})
+			},
+		},
 		{
 			ID:          "toggle_help",
 			Title:       "Toggle Help",
-			Shortcut:    "ctrl+g",
+			Shortcut:    keybindings.Binding("help").Help().Key,
 			Description: "Toggle help",
 			Handler: func(cmd Command) tea.Cmd {
 				return util.CmdHandler(util.ToggleHelpMsg{})
@@ -407,7 +454,7 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			ID:          "quit",
 			Title:       "Quit",
 			Description: "Quit",
-			Shortcut:    "ctrl+c",
+			Shortcut:    keybindings.Binding("quit").Help().Key,
 			Handler: func(cmd Command) tea.Cmd {
 				return util.CmdHandler(util.QuitMsg{})
 			},
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const MCPPromptPrefix = "mcp:"
+
+// LoadMCPPrompts returns a command for each prompt of the connected MCP
+// servers.
+func LoadMCPPrompts() []Command {
+	prompts := agent.GetMCPPrompts()
+	commands := make([]Command, 0, len(prompts))
+	for _, p := range prompts {
+		id := MCPPromptPrefix + p.MCPName + ":" + p.Prompt.Name
+		description := p.Prompt.Description
+		if description == "" {
+			description = fmt.Sprintf("Prompt from the %s MCP server", p.MCPName)
+		}
+		commands = append(commands, Command{
+			ID:          id,
+			Title:       id,
+			Description: description,
+			Handler:     createMCPPromptHandler(id, p),
+		})
+	}
+	return commands
+}
+
+func createMCPPromptHandler(id string, p agent.MCPPrompt) func(Command) tea.Cmd {
+	run := func(args map[string]string) tea.Cmd {
+		return func() tea.Msg {
+			content, err := agent.GetMCPPrompt(context.Background(), p.MCPName, p.Prompt.Name, args)
+			if err != nil {
+				return util.ReportError(err)()
+			}
+			return util.CommandRunCustomMsg{Content: content}
+		}
+	}
+	return func(cmd Command) tea.Cmd {
+		if len(p.Prompt.Arguments) == 0 {
+			return run(nil)
+		}
+		argNames := make([]string, len(p.Prompt.Arguments))
+		for i, arg := range p.Prompt.Arguments {
+			argNames[i] = arg.Name
+		}
+		return util.CmdHandler(util.ShowArgumentsDialogMsg{
+			CommandID: id,
+			ArgNames:  argNames,
+			OnSubmit:  run,
+		})
+	}
+}
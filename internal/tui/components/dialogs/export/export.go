@@ -0,0 +1,187 @@
+package export
+
+import (
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+const (
+	ExportDialogID dialogs.DialogID = "export"
+
+	defaultWidth int = 40
+)
+
+// ExportFormatSelectedMsg is sent when the user picks the format to export
+// the session to.
+type ExportFormatSelectedMsg struct {
+	SessionID string
+	Format    app.ExportFormat
+}
+
+// ExportDialog interface for the export format dialog
+type ExportDialog interface {
+	dialogs.DialogModel
+}
+
+type FormatsList = list.FilterableList[list.CompletionItem[app.ExportFormat]]
+
+type exportDialogCmp struct {
+	wWidth      int
+	wHeight     int
+	width       int
+	sessionID   string
+	keyMap      KeyMap
+	formatsList FormatsList
+	help        help.Model
+}
+
+var formatTitles = map[app.ExportFormat]string{
+	app.ExportFormatMarkdown: "Markdown",
+	app.ExportFormatJSON:     "JSON",
+	app.ExportFormatHTML:     "HTML",
+}
+
+// NewExportDialogCmp creates a new dialog to choose the format a session is
+// exported to.
+func NewExportDialogCmp(sessionID string) ExportDialog {
+	t := styles.CurrentTheme()
+	listKeyMap := list.DefaultKeyMap()
+	keyMap := DefaultKeyMap()
+	listKeyMap.Down.SetEnabled(false)
+	listKeyMap.Up.SetEnabled(false)
+	listKeyMap.DownOneItem = keyMap.Next
+	listKeyMap.UpOneItem = keyMap.Previous
+
+	items := make([]list.CompletionItem[app.ExportFormat], 0, len(app.ExportFormats))
+	for _, format := range app.ExportFormats {
+		items = append(items, list.NewCompletionItem(formatTitles[format], format, list.WithCompletionID(string(format))))
+	}
+
+	inputStyle := t.S().Base.PaddingLeft(1).PaddingBottom(1)
+	formatsList := list.NewFilterableList(
+		items,
+		list.WithFilterPlaceholder("Choose a format"),
+		list.WithFilterInputStyle(inputStyle),
+		list.WithFilterListOptions(
+			list.WithKeyMap(listKeyMap),
+			list.WithWrapNavigation(),
+		),
+	)
+	help := help.New()
+	help.Styles = t.S().Help
+	return &exportDialogCmp{
+		width:       defaultWidth,
+		sessionID:   sessionID,
+		keyMap:      keyMap,
+		formatsList: formatsList,
+		help:        help,
+	}
+}
+
+func (e *exportDialogCmp) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	cmds = append(cmds, e.formatsList.Init())
+	cmds = append(cmds, e.formatsList.Focus())
+	return tea.Sequence(cmds...)
+}
+
+func (e *exportDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.wWidth = msg.Width
+		e.wHeight = msg.Height
+		e.formatsList.SetInputWidth(e.listWidth() - 2)
+		return e, e.formatsList.SetSize(e.listWidth(), e.listHeight())
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, e.keyMap.Select):
+			selectedItem := e.formatsList.SelectedItem()
+			if selectedItem != nil {
+				selected := (*selectedItem).Value()
+				return e, tea.Sequence(
+					util.CmdHandler(dialogs.CloseDialogMsg{}),
+					util.CmdHandler(ExportFormatSelectedMsg{
+						SessionID: e.sessionID,
+						Format:    selected,
+					}),
+				)
+			}
+		case key.Matches(msg, e.keyMap.Close):
+			return e, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			u, cmd := e.formatsList.Update(msg)
+			e.formatsList = u.(FormatsList)
+			return e, cmd
+		}
+	}
+	return e, nil
+}
+
+func (e *exportDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	listView := e.formatsList.View()
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Export Session", e.width-4)),
+		listView,
+		"",
+		t.S().Base.Width(e.width-2).PaddingLeft(1).AlignHorizontal(lipgloss.Left).Render(e.help.View(e.keyMap)),
+	)
+
+	return e.style().Render(content)
+}
+
+func (e *exportDialogCmp) Cursor() *tea.Cursor {
+	if cursor, ok := e.formatsList.(util.Cursor); ok {
+		cursor := cursor.Cursor()
+		if cursor != nil {
+			cursor = e.moveCursor(cursor)
+		}
+		return cursor
+	}
+	return nil
+}
+
+func (e *exportDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(e.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (e *exportDialogCmp) listHeight() int {
+	return len(app.ExportFormats)
+}
+
+func (e *exportDialogCmp) listWidth() int {
+	return e.width - 2 // 2 for the border
+}
+
+func (e *exportDialogCmp) Position() (int, int) {
+	row := e.wHeight/4 - 2 // just a bit above the center
+	col := e.wWidth / 2
+	col -= e.width / 2
+	return row, col
+}
+
+func (e *exportDialogCmp) moveCursor(cursor *tea.Cursor) *tea.Cursor {
+	row, col := e.Position()
+	offset := row + 3 // Border + title
+	cursor.Y += offset
+	cursor.X = cursor.X + col + 2
+	return cursor
+}
+
+// ID implements ExportDialog.
+func (e *exportDialogCmp) ID() dialogs.DialogID {
+	return ExportDialogID
+}
@@ -1,6 +1,8 @@
 package sessions
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/v2/help"
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
@@ -44,11 +46,13 @@ func NewSessionDialogCmp(sessions []session.Session, selectedID string) SessionD
 	listKeyMap.DownOneItem = keyMap.Next
 	listKeyMap.UpOneItem = keyMap.Previous
 
-	items := make([]list.CompletionItem[session.Session], len(sessions))
-	if len(sessions) > 0 {
-		for i, session := range sessions {
-			items[i] = list.NewCompletionItem(session.Title, session, list.WithCompletionID(session.ID))
+	items := make([]list.CompletionItem[session.Session], 0, len(sessions))
+	for _, node := range sessionTree(sessions) {
+		title := strings.Repeat("  ", node.depth-1) + "└ " + node.session.Title
+		if node.depth == 0 {
+			title = node.session.Title
 		}
+		items = append(items, list.NewCompletionItem(title, node.session, list.WithCompletionID(node.session.ID)))
 	}
 
 	inputStyle := t.S().Base.PaddingLeft(1).PaddingBottom(1)
@@ -73,6 +77,42 @@ func NewSessionDialogCmp(sessions []session.Session, selectedID string) SessionD
 	return s
 }
 
+type sessionNode struct {
+	session session.Session
+	depth   int
+}
+
+// sessionTree orders sessions so that forks follow the session they were
+// forked from, keeping the order of the list otherwise.
+func sessionTree(sessions []session.Session) []sessionNode {
+	listed := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		listed[sess.ID] = true
+	}
+	children := make(map[string][]session.Session)
+	var roots []session.Session
+	for _, sess := range sessions {
+		if sess.ParentSessionID != "" && listed[sess.ParentSessionID] {
+			children[sess.ParentSessionID] = append(children[sess.ParentSessionID], sess)
+			continue
+		}
+		roots = append(roots, sess)
+	}
+
+	nodes := make([]sessionNode, 0, len(sessions))
+	var walk func(sess session.Session, depth int)
+	walk = func(sess session.Session, depth int) {
+		nodes = append(nodes, sessionNode{session: sess, depth: depth})
+		for _, child := range children[sess.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return nodes
+}
+
 func (s *sessionDialogCmp) Init() tea.Cmd {
 	var cmds []tea.Cmd
 	cmds = append(cmds, s.sessionsList.Init())
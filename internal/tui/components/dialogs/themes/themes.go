@@ -0,0 +1,171 @@
+package themes
+
+import (
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/exp/list"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+const ThemesDialogID dialogs.DialogID = "themes"
+
+// ThemeSelectedMsg is sent when a theme is selected in the dialog.
+type ThemeSelectedMsg struct {
+	Name string
+}
+
+// ThemeDialog interface for the theme switching dialog
+type ThemeDialog interface {
+	dialogs.DialogModel
+}
+
+type ThemesList = list.FilterableList[list.CompletionItem[string]]
+
+type themeDialogCmp struct {
+	wWidth     int
+	wHeight    int
+	width      int
+	keyMap     KeyMap
+	themesList ThemesList
+	help       help.Model
+}
+
+// NewThemeDialogCmp creates a new theme switching dialog
+func NewThemeDialogCmp() ThemeDialog {
+	t := styles.CurrentTheme()
+	listKeyMap := list.DefaultKeyMap()
+	keyMap := DefaultKeyMap()
+	listKeyMap.Down.SetEnabled(false)
+	listKeyMap.Up.SetEnabled(false)
+	listKeyMap.DownOneItem = keyMap.Next
+	listKeyMap.UpOneItem = keyMap.Previous
+
+	names := styles.DefaultManager().List()
+	items := make([]list.CompletionItem[string], 0, len(names))
+	for _, name := range names {
+		items = append(items, list.NewCompletionItem(name, name, list.WithCompletionID(name)))
+	}
+
+	inputStyle := t.S().Base.PaddingLeft(1).PaddingBottom(1)
+	themesList := list.NewFilterableList(
+		items,
+		list.WithFilterPlaceholder("Enter a theme name"),
+		list.WithFilterInputStyle(inputStyle),
+		list.WithFilterListOptions(
+			list.WithKeyMap(listKeyMap),
+			list.WithWrapNavigation(),
+		),
+	)
+	help := help.New()
+	help.Styles = t.S().Help
+	return &themeDialogCmp{
+		keyMap:     keyMap,
+		themesList: themesList,
+		help:       help,
+	}
+}
+
+func (s *themeDialogCmp) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	cmds = append(cmds, s.themesList.Init())
+	cmds = append(cmds, s.themesList.Focus())
+	return tea.Sequence(cmds...)
+}
+
+func (s *themeDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.wWidth = msg.Width
+		s.wHeight = msg.Height
+		s.width = min(60, s.wWidth-8)
+		s.themesList.SetInputWidth(s.listWidth() - 2)
+		return s, tea.Batch(
+			s.themesList.SetSize(s.listWidth(), s.listHeight()),
+			s.themesList.SetSelected(styles.CurrentTheme().Name),
+		)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, s.keyMap.Select):
+			selectedItem := s.themesList.SelectedItem()
+			if selectedItem != nil {
+				selected := *selectedItem
+				return s, tea.Sequence(
+					util.CmdHandler(dialogs.CloseDialogMsg{}),
+					util.CmdHandler(ThemeSelectedMsg{Name: selected.Value()}),
+				)
+			}
+		case key.Matches(msg, s.keyMap.Close):
+			return s, util.CmdHandler(dialogs.CloseDialogMsg{})
+		default:
+			u, cmd := s.themesList.Update(msg)
+			s.themesList = u.(ThemesList)
+			return s, cmd
+		}
+	}
+	return s, nil
+}
+
+func (s *themeDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		t.S().Base.Padding(0, 1, 1, 1).Render(core.Title("Switch Theme", s.width-4)),
+		s.themesList.View(),
+		"",
+		t.S().Base.Width(s.width-2).PaddingLeft(1).AlignHorizontal(lipgloss.Left).Render(s.help.View(s.keyMap)),
+	)
+
+	return s.style().Render(content)
+}
+
+func (s *themeDialogCmp) Cursor() *tea.Cursor {
+	if cursor, ok := s.themesList.(util.Cursor); ok {
+		cursor := cursor.Cursor()
+		if cursor != nil {
+			cursor = s.moveCursor(cursor)
+		}
+		return cursor
+	}
+	return nil
+}
+
+func (s *themeDialogCmp) style() lipgloss.Style {
+	t := styles.CurrentTheme()
+	return t.S().Base.
+		Width(s.width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus)
+}
+
+func (s *themeDialogCmp) listHeight() int {
+	return s.wHeight/2 - 6 // 5 for the border, title and help
+}
+
+func (s *themeDialogCmp) listWidth() int {
+	return s.width - 2 // 2 for the border
+}
+
+func (s *themeDialogCmp) Position() (int, int) {
+	row := s.wHeight/4 - 2 // just a bit above the center
+	col := s.wWidth / 2
+	col -= s.width / 2
+	return row, col
+}
+
+func (s *themeDialogCmp) moveCursor(cursor *tea.Cursor) *tea.Cursor {
+	row, col := s.Position()
+	offset := row + 3 // Border + title
+	cursor.Y += offset
+	cursor.X = cursor.X + col + 2
+	return cursor
+}
+
+// ID implements ThemeDialog.
+func (s *themeDialogCmp) ID() dialogs.DialogID {
+	return ThemesDialogID
+}
@@ -65,6 +65,9 @@ func RenderMCPList(opts RenderOptions) []string {
 			case agent.MCPStateStarting:
 				icon = t.ItemBusyIcon
 				description = t.S().Subtle.Render("starting...")
+			case agent.MCPStateAuthorizing:
+				icon = t.ItemBusyIcon
+				description = t.S().Subtle.Render("authorize in your browser...")
 			case agent.MCPStateConnected:
 				icon = t.ItemOnlineIcon
 				if state.ToolCount > 0 {
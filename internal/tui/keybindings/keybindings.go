@@ -0,0 +1,154 @@
+// Package keybindings holds the TUI actions whose keys can be configured in
+// the keybindings section of the config.
+package keybindings
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+)
+
+// Action is a TUI action with its default keys.
+type Action struct {
+	ID   string
+	Keys []string
+	// HelpKey is the key shown in the help, the first key by default.
+	HelpKey string
+	Desc    string
+}
+
+// Actions are the configurable actions. They are all active at the same time,
+// so a key can only be bound to one of them.
+var Actions = []Action{
+	{ID: "quit", Keys: []string{"ctrl+c"}, Desc: "quit"},
+	{ID: "help", Keys: []string{"ctrl+g"}, Desc: "more"},
+	{ID: "commands", Keys: []string{"ctrl+p"}, Desc: "commands"},
+	{ID: "suspend", Keys: []string{"ctrl+z"}, Desc: "suspend"},
+	{ID: "sessions", Keys: []string{"ctrl+s"}, Desc: "sessions"},
+	{ID: "new_session", Keys: []string{"ctrl+n"}, Desc: "new session"},
+	{ID: "add_attachment", Keys: []string{"ctrl+f"}, Desc: "add attachment"},
+	{ID: "cancel", Keys: []string{"esc"}, Desc: "cancel"},
+	{ID: "change_focus", Keys: []string{"tab"}, Desc: "change focus"},
+	{ID: "details", Keys: []string{"ctrl+d"}, Desc: "toggle details"},
+	{ID: "reload_last_prompt", Keys: []string{"ctrl+t"}, Desc: "reload last prompt"},
+	{ID: "debug_tab", Keys: []string{"shift+tab"}, Desc: "switch debug tab"},
+	{ID: "add_file", Keys: []string{"/"}, Desc: "add file"},
+	{ID: "send_message", Keys: []string{"enter"}, Desc: "send"},
+	{ID: "open_editor", Keys: []string{"ctrl+o"}, Desc: "open editor"},
+	// "ctrl+j" is a common keybinding for newline in many editors, the help
+	// shows "shift+enter" when the terminal supports it.
+	{ID: "newline", Keys: []string{"shift+enter", "ctrl+j"}, HelpKey: "ctrl+j", Desc: "newline"},
+}
+
+// reserved are the fixed keys active together with the actions, with what
+// they do. The config cannot bind them to an action.
+var reserved = map[string]string{
+	"ctrl+r":     "deleting attachments",
+	"ctrl+y":     "accepting completions",
+	"c":          "copying messages",
+	"y":          "copying messages",
+	"C":          "copying messages",
+	"Y":          "copying messages",
+	"F":          "forking sessions",
+	"e":          "expanding messages",
+	"g":          "scrolling messages",
+	"G":          "scrolling messages",
+	"j":          "scrolling messages",
+	"k":          "scrolling messages",
+	"J":          "scrolling messages",
+	"K":          "scrolling messages",
+	"d":          "scrolling messages",
+	"u":          "scrolling messages",
+	"f":          "scrolling messages",
+	"b":          "scrolling messages",
+	" ":          "scrolling messages",
+	"up":         "scrolling messages",
+	"down":       "scrolling messages",
+	"shift+up":   "scrolling messages",
+	"shift+down": "scrolling messages",
+	"pgup":       "scrolling messages",
+	"pgdown":     "scrolling messages",
+	"home":       "scrolling messages",
+	"end":        "scrolling messages",
+}
+
+// configured are the keys set in the config by action ID.
+var configured map[string][]string
+
+// Configure sets the keys of the actions from the config, where an empty list
+// disables an action. It fails on unknown actions, on reserved keys and on
+// keys bound to several actions.
+func Configure(bindings map[string][]string) error {
+	var errs []error
+	for _, id := range slices.Sorted(maps.Keys(bindings)) {
+		if action(id) == nil {
+			errs = append(errs, fmt.Errorf("unknown action %q", id))
+		}
+		for _, k := range bindings[id] {
+			if use, ok := reserved[k]; ok {
+				errs = append(errs, fmt.Errorf("key %q of %s is reserved for %s", k, id, use))
+			}
+		}
+	}
+
+	owners := make(map[string]string)
+	for _, a := range Actions {
+		keys := a.Keys
+		if k, ok := bindings[a.ID]; ok {
+			keys = k
+		}
+		for _, k := range keys {
+			if owner, ok := owners[k]; ok && owner != a.ID {
+				errs = append(errs, fmt.Errorf("key %q is bound to both %s and %s", k, owner, a.ID))
+				continue
+			}
+			owners[k] = a.ID
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	configured = bindings
+	return nil
+}
+
+// Binding returns the key binding of an action, with the keys set in the
+// config if any. The binding is disabled when the config removes its keys.
+func Binding(id string) key.Binding {
+	a := action(id)
+	if a == nil {
+		panic(fmt.Sprintf("unknown key binding action %q", id))
+	}
+	keys, helpKey := a.Keys, a.HelpKey
+	if k, ok := configured[id]; ok {
+		keys, helpKey = k, ""
+	}
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled(), key.WithHelp("", a.Desc))
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(cmp.Or(helpKey, keys[0]), a.Desc),
+	)
+}
+
+// Described returns the key binding of an action with another help
+// description.
+func Described(id, desc string) key.Binding {
+	b := Binding(id)
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+func action(id string) *Action {
+	i := slices.IndexFunc(Actions, func(a Action) bool { return a.ID == id })
+	if i < 0 {
+		return nil
+	}
+	return &Actions[i]
+}
@@ -0,0 +1,73 @@
+package keybindings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		bindings map[string][]string
+		err      string
+	}{
+		{
+			name:     "unknown action",
+			bindings: map[string][]string{"launch_rockets": {"ctrl+l"}},
+			err:      `unknown action "launch_rockets"`,
+		},
+		{
+			name:     "conflict with a default",
+			bindings: map[string][]string{"sessions": {"ctrl+p"}},
+			err:      `key "ctrl+p" is bound to both commands and sessions`,
+		},
+		{
+			name:     "reserved key",
+			bindings: map[string][]string{"details": {"e"}},
+			err:      `key "e" of details is reserved for expanding messages`,
+		},
+		{
+			name:     "conflict between remapped actions",
+			bindings: map[string][]string{"commands": {"ctrl+k"}, "sessions": {"ctrl+k"}},
+			err:      `key "ctrl+k" is bound to both commands and sessions`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.EqualError(t, Configure(tt.bindings), tt.err)
+		})
+	}
+}
+
+func TestBinding(t *testing.T) {
+	t.Cleanup(func() { configured = nil })
+
+	newline := Binding("newline")
+	require.Equal(t, []string{"shift+enter", "ctrl+j"}, newline.Keys())
+	require.Equal(t, "ctrl+j", newline.Help().Key)
+
+	require.NoError(t, Configure(map[string][]string{
+		"commands": {"ctrl+k"},
+		"sessions": {"ctrl+p", "alt+s"},
+		"suspend":  {},
+		"newline":  {"alt+enter"},
+	}))
+
+	commands := Binding("commands")
+	require.Equal(t, []string{"ctrl+k"}, commands.Keys())
+	require.Equal(t, "ctrl+k", commands.Help().Key)
+	require.Equal(t, "commands", commands.Help().Desc)
+
+	sessions := Described("sessions", "switch session")
+	require.Equal(t, []string{"ctrl+p", "alt+s"}, sessions.Keys())
+	require.Equal(t, "ctrl+p", sessions.Help().Key)
+	require.Equal(t, "switch session", sessions.Help().Desc)
+
+	require.False(t, Binding("suspend").Enabled())
+	require.Equal(t, "alt+enter", Binding("newline").Help().Key)
+	require.Equal(t, []string{"ctrl+n"}, Binding("new_session").Keys())
+}
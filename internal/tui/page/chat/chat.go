@@ -3,6 +3,7 @@ package chat
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/bubbles/v2/help"
@@ -29,6 +30,7 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/styles"
 	"github.com/charmbracelet/crush/internal/tui/util"
@@ -290,6 +292,7 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return p, tea.Batch(cmds...)
 	case pubsub.Event[message.Message],
+		pubsub.Event[message.Delta],
 		anim.StepMsg,
 		spinner.TickMsg:
 		if p.focusedPane == PanelTypeSplash {
@@ -308,6 +311,14 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		u, cmd := p.editor.Update(msg)
 		p.editor = u.(editor.Editor)
 		return p, cmd
+	case util.ThemeChangedMsg:
+		u, cmd := p.editor.Update(msg)
+		p.editor = u.(editor.Editor)
+		cmds = append(cmds, cmd)
+		u, cmd = p.chat.Update(msg)
+		p.chat = u.(chat.MessageListCmp)
+		cmds = append(cmds, cmd)
+		return p, tea.Batch(append(cmds, p.SetSize(p.width, p.height))...)
 	case pubsub.Event[history.File], sidebar.SessionFilesMsg:
 		u, cmd := p.sidebar.Update(msg)
 		p.sidebar = u.(sidebar.Sidebar)
@@ -748,10 +759,7 @@ func (p *chatPage) Bindings() []key.Binding {
 	if p.app.CoderAgent != nil && p.app.CoderAgent.IsBusy() {
 		cancelBinding := p.keyMap.Cancel
 		if p.isCanceling {
-			cancelBinding = key.NewBinding(
-				key.WithKeys("esc"),
-				key.WithHelp("esc", "press again to cancel"),
-			)
+			cancelBinding = keybindings.Described("cancel", "press again to cancel")
 		}
 		bindings = append([]key.Binding{cancelBinding}, bindings...)
 	}
@@ -759,18 +767,12 @@ func (p *chatPage) Bindings() []key.Binding {
 	switch p.focusedPane {
 	case PanelTypeChat:
 		bindings = append([]key.Binding{
-			key.NewBinding(
-				key.WithKeys("tab"),
-				key.WithHelp("tab", "focus editor"),
-			),
+			keybindings.Described("change_focus", "focus editor"),
 		}, bindings...)
 		bindings = append(bindings, p.chat.Bindings()...)
 	case PanelTypeEditor:
 		bindings = append([]key.Binding{
-			key.NewBinding(
-				key.WithKeys("tab"),
-				key.WithHelp("tab", "focus chat"),
-			),
+			keybindings.Described("change_focus", "focus chat"),
 		}, bindings...)
 		bindings = append(bindings, p.editor.Bindings()...)
 	case PanelTypeSplash:
@@ -797,10 +799,7 @@ func (p *chatPage) Help() help.KeyMap {
 				key.WithHelp("enter", "accept"),
 			),
 			// Quit
-			key.NewBinding(
-				key.WithKeys("ctrl+c"),
-				key.WithHelp("ctrl+c", "quit"),
-			),
+			keybindings.Binding("quit"),
 		)
 		// keep them the same
 		for _, v := range shortList {
@@ -825,10 +824,7 @@ func (p *chatPage) Help() help.KeyMap {
 		}
 		shortList = append(shortList,
 			// Quit
-			key.NewBinding(
-				key.WithKeys("ctrl+c"),
-				key.WithHelp("ctrl+c", "quit"),
-			),
+			keybindings.Binding("quit"),
 		)
 		// keep them the same
 		for _, v := range shortList {
@@ -836,10 +832,7 @@ func (p *chatPage) Help() help.KeyMap {
 		}
 	case p.isProjectInit:
 		shortList = append(shortList,
-			key.NewBinding(
-				key.WithKeys("ctrl+c"),
-				key.WithHelp("ctrl+c", "quit"),
-			),
+			keybindings.Binding("quit"),
 		)
 		// keep them the same
 		for _, v := range shortList {
@@ -867,21 +860,12 @@ func (p *chatPage) Help() help.KeyMap {
 			return core.NewSimpleHelp(shortList, fullList)
 		}
 		if p.app.CoderAgent != nil && p.app.CoderAgent.IsBusy() {
-			cancelBinding := key.NewBinding(
-				key.WithKeys("esc"),
-				key.WithHelp("esc", "cancel"),
-			)
+			cancelBinding := keybindings.Binding("cancel")
 			if p.isCanceling {
-				cancelBinding = key.NewBinding(
-					key.WithKeys("esc"),
-					key.WithHelp("esc", "press again to cancel"),
-				)
+				cancelBinding = keybindings.Described("cancel", "press again to cancel")
 			}
 			if p.app.CoderAgent != nil && p.app.CoderAgent.QueuedPrompts(p.session.ID) > 0 {
-				cancelBinding = key.NewBinding(
-					key.WithKeys("esc"),
-					key.WithHelp("esc", "clear queue"),
-				)
+				cancelBinding = keybindings.Described("cancel", "clear queue")
 			}
 			shortList = append(shortList, cancelBinding)
 			fullList = append(fullList,
@@ -893,40 +877,19 @@ func (p *chatPage) Help() help.KeyMap {
 		globalBindings := []key.Binding{}
 		// we are in a session
 		if p.session.ID != "" {
-			tabKey := key.NewBinding(
-				key.WithKeys("tab"),
-				key.WithHelp("tab", "focus chat"),
-			)
+			tabKey := keybindings.Described("change_focus", "focus chat")
 			if p.focusedPane == PanelTypeChat {
-				tabKey = key.NewBinding(
-					key.WithKeys("tab"),
-					key.WithHelp("tab", "focus editor"),
-				)
+				tabKey = keybindings.Described("change_focus", "focus editor")
 			}
 			shortList = append(shortList, tabKey)
 			globalBindings = append(globalBindings, tabKey)
 		}
-		commandsBinding := key.NewBinding(
-			key.WithKeys("ctrl+p"),
-			key.WithHelp("ctrl+p", "commands"),
-		)
-		helpBinding := key.NewBinding(
-			key.WithKeys("ctrl+g"),
-			key.WithHelp("ctrl+g", "more"),
-		)
+		commandsBinding := keybindings.Binding("commands")
+		helpBinding := keybindings.Binding("help")
 		globalBindings = append(globalBindings, commandsBinding)
-		globalBindings = append(globalBindings,
-			key.NewBinding(
-				key.WithKeys("ctrl+s"),
-				key.WithHelp("ctrl+s", "sessions"),
-			),
-		)
+		globalBindings = append(globalBindings, keybindings.Binding("sessions"))
 		if p.session.ID != "" {
-			globalBindings = append(globalBindings,
-				key.NewBinding(
-					key.WithKeys("ctrl+n"),
-					key.WithHelp("ctrl+n", "new sessions"),
-				))
+			globalBindings = append(globalBindings, keybindings.Described("new_session", "new sessions"))
 			globalBindings = append(globalBindings, p.keyMap.ReloadLastPrompt)
 		}
 		shortList = append(shortList,
@@ -943,6 +906,8 @@ func (p *chatPage) Help() help.KeyMap {
 					key.WithHelp("↑↓", "scroll"),
 				),
 				messages.CopyKey,
+				messages.ForkKey,
+				messages.ExpandKey,
 			)
 			fullList = append(fullList,
 				[]key.Binding{
@@ -983,36 +948,25 @@ func (p *chatPage) Help() help.KeyMap {
 				},
 				[]key.Binding{
 					messages.CopyKey,
+					messages.ForkKey,
+					messages.ExpandKey,
 					messages.ClearSelectionKey,
 				},
 			)
 		case PanelTypeEditor:
-			newLineBinding := key.NewBinding(
-				key.WithKeys("shift+enter", "ctrl+j"),
-				// "ctrl+j" is a common keybinding for newline in many editors. If
-				// the terminal supports "shift+enter", we substitute the help text
-				// to reflect that.
-				key.WithHelp("ctrl+j", "newline"),
-			)
-			if p.keyboardEnhancements.SupportsKeyDisambiguation() {
+			newLineBinding := keybindings.Binding("newline")
+			// If the terminal supports "shift+enter", we substitute the help
+			// text to reflect that.
+			if p.keyboardEnhancements.SupportsKeyDisambiguation() && slices.Contains(newLineBinding.Keys(), "shift+enter") {
 				newLineBinding.SetHelp("shift+enter", newLineBinding.Help().Desc)
 			}
 			shortList = append(shortList, newLineBinding)
 			fullList = append(fullList,
 				[]key.Binding{
 					newLineBinding,
-					key.NewBinding(
-						key.WithKeys("ctrl+f"),
-						key.WithHelp("ctrl+f", "add image"),
-					),
-					key.NewBinding(
-						key.WithKeys("/"),
-						key.WithHelp("/", "add file"),
-					),
-					key.NewBinding(
-						key.WithKeys("ctrl+o"),
-						key.WithHelp("ctrl+o", "open editor"),
-					),
+					keybindings.Described("add_attachment", "add image"),
+					keybindings.Binding("add_file"),
+					keybindings.Binding("open_editor"),
 				})
 
 			if p.editor.HasAttachments() {
@@ -1034,18 +988,12 @@ func (p *chatPage) Help() help.KeyMap {
 		}
 		shortList = append(shortList,
 			// Quit
-			key.NewBinding(
-				key.WithKeys("ctrl+c"),
-				key.WithHelp("ctrl+c", "quit"),
-			),
+			keybindings.Binding("quit"),
 			// Help
 			helpBinding,
 		)
 		fullList = append(fullList, []key.Binding{
-			key.NewBinding(
-				key.WithKeys("ctrl+g"),
-				key.WithHelp("ctrl+g", "less"),
-			),
+			keybindings.Described("help", "less"),
 		})
 	}
 
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/crush/internal/tui/keybindings"
 )
 
 type KeyMap struct {
@@ -16,33 +17,12 @@ type KeyMap struct {
 
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		NewSession: key.NewBinding(
-			key.WithKeys("ctrl+n"),
-			key.WithHelp("ctrl+n", "new session"),
-		),
-		AddAttachment: key.NewBinding(
-			key.WithKeys("ctrl+f"),
-			key.WithHelp("ctrl+f", "add attachment"),
-		),
-		Cancel: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "cancel"),
-		),
-		Tab: key.NewBinding(
-			key.WithKeys("tab"),
-			key.WithHelp("tab", "change focus"),
-		),
-		Details: key.NewBinding(
-			key.WithKeys("ctrl+d"),
-			key.WithHelp("ctrl+d", "toggle details"),
-		),
-		ReloadLastPrompt: key.NewBinding(
-			key.WithKeys("ctrl+t"),
-			key.WithHelp("ctrl+t", "reload last prompt"),
-		),
-		DebugTab: key.NewBinding(
-			key.WithKeys("shift+tab"),
-			key.WithHelp("shift+tab", "switch debug tab"),
-		),
+		NewSession:       keybindings.Binding("new_session"),
+		AddAttachment:    keybindings.Binding("add_attachment"),
+		Cancel:           keybindings.Binding("cancel"),
+		Tab:              keybindings.Binding("change_focus"),
+		Details:          keybindings.Binding("details"),
+		ReloadLastPrompt: keybindings.Binding("reload_last_prompt"),
+		DebugTab:         keybindings.Binding("debug_tab"),
 	}
 }
@@ -55,21 +55,44 @@ func NewCharmtoneTheme() *Theme {
 		RedDark:  charmtone.Sriracha,
 		RedLight: charmtone.Salmon,
 		Cherry:   charmtone.Cherry,
-	}
-
-	// Text selection.
-	t.TextSelection = lipgloss.NewStyle().Foreground(charmtone.Salt).Background(charmtone.Charple)
 
-	// LSP and MCP status.
-	t.ItemOfflineIcon = lipgloss.NewStyle().Foreground(charmtone.Squid).SetString("●")
-	t.ItemBusyIcon = t.ItemOfflineIcon.Foreground(charmtone.Citron)
-	t.ItemErrorIcon = t.ItemOfflineIcon.Foreground(charmtone.Coral)
-	t.ItemOnlineIcon = t.ItemOfflineIcon.Foreground(charmtone.Guac)
-
-	t.YoloIconFocused = lipgloss.NewStyle().Foreground(charmtone.Oyster).Background(charmtone.Citron).Bold(true).SetString(" ! ")
-	t.YoloIconBlurred = t.YoloIconFocused.Foreground(charmtone.Pepper).Background(charmtone.Squid)
-	t.YoloDotsFocused = lipgloss.NewStyle().Foreground(charmtone.Zest).SetString(":::")
-	t.YoloDotsBlurred = t.YoloDotsFocused.Foreground(charmtone.Squid)
+		// Diffs
+		DiffInsert:             lipgloss.Color("#629657"),
+		DiffInsertBg:           lipgloss.Color("#323931"),
+		DiffInsertLineNumberBg: lipgloss.Color("#2b322a"),
+		DiffDelete:             lipgloss.Color("#a45c59"),
+		DiffDeleteBg:           lipgloss.Color("#383030"),
+		DiffDeleteLineNumberBg: lipgloss.Color("#312929"),
+
+		Syntax: SyntaxColors{
+			Text:             charmtone.Smoke,
+			Error:            charmtone.Butter,
+			ErrorBg:          charmtone.Sriracha,
+			Comment:          charmtone.Oyster,
+			CommentPreproc:   charmtone.Bengal,
+			Keyword:          charmtone.Malibu,
+			KeywordReserved:  charmtone.Pony,
+			KeywordNamespace: charmtone.Pony,
+			KeywordType:      charmtone.Guppy,
+			Operator:         charmtone.Salmon,
+			Punctuation:      charmtone.Zest,
+			Name:             charmtone.Smoke,
+			NameBuiltin:      charmtone.Cheeky,
+			NameTag:          charmtone.Mauve,
+			NameAttribute:    charmtone.Hazy,
+			NameClass:        charmtone.Salt,
+			NameDecorator:    charmtone.Citron,
+			NameFunction:     charmtone.Guac,
+			Number:           charmtone.Julep,
+			String:           charmtone.Cumin,
+			StringEscape:     charmtone.Bok,
+			Deleted:          charmtone.Coral,
+			Inserted:         charmtone.Guac,
+			Subheading:       charmtone.Squid,
+			Background:       charmtone.Charcoal,
+		},
+	}
+	t.deriveStyles()
 
 	return t
 }
@@ -1,10 +1,43 @@
 package styles
 
 import (
+	"image/color"
+
 	"github.com/alecthomas/chroma/v2"
+	chromaStyles "github.com/alecthomas/chroma/v2/styles"
 	"github.com/charmbracelet/glamour/v2/ansi"
 )
 
+// SyntaxColors are the colors of the syntax highlighting of a theme, used
+// for code blocks, diffs and file views.
+type SyntaxColors struct {
+	Text             color.Color
+	Error            color.Color
+	ErrorBg          color.Color
+	Comment          color.Color
+	CommentPreproc   color.Color
+	Keyword          color.Color
+	KeywordReserved  color.Color
+	KeywordNamespace color.Color
+	KeywordType      color.Color
+	Operator         color.Color
+	Punctuation      color.Color
+	Name             color.Color
+	NameBuiltin      color.Color
+	NameTag          color.Color
+	NameAttribute    color.Color
+	NameClass        color.Color
+	NameDecorator    color.Color
+	NameFunction     color.Color
+	Number           color.Color
+	String           color.Color
+	StringEscape     color.Color
+	Deleted          color.Color
+	Inserted         color.Color
+	Subheading       color.Color
+	Background       color.Color
+}
+
 func chromaStyle(style ansi.StylePrimitive) string {
 	var s string
 
@@ -39,41 +72,54 @@ func chromaStyle(style ansi.StylePrimitive) string {
 	return s
 }
 
+// GetChromaStyle returns the syntax highlighting style of the current theme.
+func GetChromaStyle() *chroma.Style {
+	t := CurrentTheme()
+	if t.ChromaStyle != "" {
+		return chromaStyles.Get(t.ChromaStyle)
+	}
+	return chroma.MustNewStyle("crush", GetChromaTheme())
+}
+
 func GetChromaTheme() chroma.StyleEntries {
 	t := CurrentTheme()
-	rules := t.S().Markdown.CodeBlock
+	rules := t.S().Markdown.CodeBlock.Chroma
+	if rules == nil {
+		// The glamour style of the theme has no syntax highlighting.
+		rules = t.colorMarkdownStyle().CodeBlock.Chroma
+	}
 
 	return chroma.StyleEntries{
-		chroma.Text:                chromaStyle(rules.Chroma.Text),
-		chroma.Error:               chromaStyle(rules.Chroma.Error),
-		chroma.Comment:             chromaStyle(rules.Chroma.Comment),
-		chroma.CommentPreproc:      chromaStyle(rules.Chroma.CommentPreproc),
-		chroma.Keyword:             chromaStyle(rules.Chroma.Keyword),
-		chroma.KeywordReserved:     chromaStyle(rules.Chroma.KeywordReserved),
-		chroma.KeywordNamespace:    chromaStyle(rules.Chroma.KeywordNamespace),
-		chroma.KeywordType:         chromaStyle(rules.Chroma.KeywordType),
-		chroma.Operator:            chromaStyle(rules.Chroma.Operator),
-		chroma.Punctuation:         chromaStyle(rules.Chroma.Punctuation),
-		chroma.Name:                chromaStyle(rules.Chroma.Name),
-		chroma.NameBuiltin:         chromaStyle(rules.Chroma.NameBuiltin),
-		chroma.NameTag:             chromaStyle(rules.Chroma.NameTag),
-		chroma.NameAttribute:       chromaStyle(rules.Chroma.NameAttribute),
-		chroma.NameClass:           chromaStyle(rules.Chroma.NameClass),
-		chroma.NameConstant:        chromaStyle(rules.Chroma.NameConstant),
-		chroma.NameDecorator:       chromaStyle(rules.Chroma.NameDecorator),
-		chroma.NameException:       chromaStyle(rules.Chroma.NameException),
-		chroma.NameFunction:        chromaStyle(rules.Chroma.NameFunction),
-		chroma.NameOther:           chromaStyle(rules.Chroma.NameOther),
-		chroma.Literal:             chromaStyle(rules.Chroma.Literal),
-		chroma.LiteralNumber:       chromaStyle(rules.Chroma.LiteralNumber),
-		chroma.LiteralDate:         chromaStyle(rules.Chroma.LiteralDate),
-		chroma.LiteralString:       chromaStyle(rules.Chroma.LiteralString),
-		chroma.LiteralStringEscape: chromaStyle(rules.Chroma.LiteralStringEscape),
-		chroma.GenericDeleted:      chromaStyle(rules.Chroma.GenericDeleted),
-		chroma.GenericEmph:         chromaStyle(rules.Chroma.GenericEmph),
-		chroma.GenericInserted:     chromaStyle(rules.Chroma.GenericInserted),
-		chroma.GenericStrong:       chromaStyle(rules.Chroma.GenericStrong),
-		chroma.GenericSubheading:   chromaStyle(rules.Chroma.GenericSubheading),
-		chroma.Background:          chromaStyle(rules.Chroma.Background),
+		chroma.Text:                chromaStyle(rules.Text),
+		chroma.Error:               chromaStyle(rules.Error),
+		chroma.Comment:             chromaStyle(rules.Comment),
+		chroma.CommentPreproc:      chromaStyle(rules.CommentPreproc),
+		chroma.Keyword:             chromaStyle(rules.Keyword),
+		chroma.KeywordReserved:     chromaStyle(rules.KeywordReserved),
+		chroma.KeywordNamespace:    chromaStyle(rules.KeywordNamespace),
+		chroma.KeywordType:         chromaStyle(rules.KeywordType),
+		chroma.Operator:            chromaStyle(rules.Operator),
+		chroma.Punctuation:         chromaStyle(rules.Punctuation),
+		chroma.Name:                chromaStyle(rules.Name),
+		chroma.NameBuiltin:         chromaStyle(rules.NameBuiltin),
+		chroma.NameTag:             chromaStyle(rules.NameTag),
+		chroma.NameAttribute:       chromaStyle(rules.NameAttribute),
+		chroma.NameClass:           chromaStyle(rules.NameClass),
+		chroma.NameConstant:        chromaStyle(rules.NameConstant),
+		chroma.NameDecorator:       chromaStyle(rules.NameDecorator),
+		chroma.NameException:       chromaStyle(rules.NameException),
+		chroma.NameFunction:        chromaStyle(rules.NameFunction),
+		chroma.NameOther:           chromaStyle(rules.NameOther),
+		chroma.Literal:             chromaStyle(rules.Literal),
+		chroma.LiteralNumber:       chromaStyle(rules.LiteralNumber),
+		chroma.LiteralDate:         chromaStyle(rules.LiteralDate),
+		chroma.LiteralString:       chromaStyle(rules.LiteralString),
+		chroma.LiteralStringEscape: chromaStyle(rules.LiteralStringEscape),
+		chroma.GenericDeleted:      chromaStyle(rules.GenericDeleted),
+		chroma.GenericEmph:         chromaStyle(rules.GenericEmph),
+		chroma.GenericInserted:     chromaStyle(rules.GenericInserted),
+		chroma.GenericStrong:       chromaStyle(rules.GenericStrong),
+		chroma.GenericSubheading:   chromaStyle(rules.GenericSubheading),
+		chroma.Background:          chromaStyle(rules.Background),
 	}
 }
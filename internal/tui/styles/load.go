@@ -0,0 +1,190 @@
+package styles
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	chromaStyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// themeFile is a user theme as stored in a JSON file. It overrides the colors
+// of the theme it extends.
+type themeFile struct {
+	Name         string            `json:"name"`
+	Extends      string            `json:"extends,omitempty"`
+	IsDark       *bool             `json:"is_dark,omitempty"`
+	ChromaStyle  string            `json:"chroma_style,omitempty"`
+	GlamourStyle string            `json:"glamour_style,omitempty"`
+	Colors       map[string]string `json:"colors,omitempty"`
+	Syntax       map[string]string `json:"syntax,omitempty"`
+}
+
+// LoadThemes registers the themes defined in the JSON files of dir. A theme
+// can extend the built-in themes and the themes of the files sorted before
+// its own. Invalid files are skipped and reported in the returned error.
+func (m *Manager) LoadThemes(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	slices.Sort(paths)
+
+	var errs []error
+	for _, path := range paths {
+		theme, err := m.loadTheme(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme %s: %w", path, err))
+			continue
+		}
+		m.Register(theme)
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) loadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file themeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if file.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	base, ok := m.themes[cmp.Or(file.Extends, "charmtone")]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q to extend", file.Extends)
+	}
+	theme := *base
+	theme.styles = nil
+	theme.Name = file.Name
+	if file.IsDark != nil {
+		theme.IsDark = *file.IsDark
+	}
+
+	if file.ChromaStyle != "" {
+		if _, ok := chromaStyles.Registry[file.ChromaStyle]; !ok {
+			return nil, fmt.Errorf("unknown chroma style %q", file.ChromaStyle)
+		}
+		theme.ChromaStyle = file.ChromaStyle
+	}
+	if file.GlamourStyle != "" {
+		glamourStyle := file.GlamourStyle
+		if strings.HasSuffix(glamourStyle, ".json") && !filepath.IsAbs(glamourStyle) {
+			glamourStyle = filepath.Join(filepath.Dir(path), glamourStyle)
+		}
+		if _, err := loadGlamourStyle(glamourStyle); err != nil {
+			return nil, err
+		}
+		theme.GlamourStyle = glamourStyle
+	}
+
+	if err := setColors(theme.colorFields(), file.Colors); err != nil {
+		return nil, err
+	}
+	if err := setColors(theme.Syntax.colorFields(), file.Syntax); err != nil {
+		return nil, fmt.Errorf("syntax: %w", err)
+	}
+	theme.deriveStyles()
+	return &theme, nil
+}
+
+func setColors(fields map[string]*color.Color, colors map[string]string) error {
+	for name, value := range colors {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown color %q", name)
+		}
+		if _, err := colorful.Hex(value); err != nil {
+			return fmt.Errorf("invalid color %q for %s", value, name)
+		}
+		*field = lipgloss.Color(value)
+	}
+	return nil
+}
+
+// colorFields returns the colors of the theme by their name in theme files.
+func (t *Theme) colorFields() map[string]*color.Color {
+	return map[string]*color.Color{
+		"primary":                    &t.Primary,
+		"secondary":                  &t.Secondary,
+		"tertiary":                   &t.Tertiary,
+		"accent":                     &t.Accent,
+		"bg_base":                    &t.BgBase,
+		"bg_base_lighter":            &t.BgBaseLighter,
+		"bg_subtle":                  &t.BgSubtle,
+		"bg_overlay":                 &t.BgOverlay,
+		"fg_base":                    &t.FgBase,
+		"fg_muted":                   &t.FgMuted,
+		"fg_half_muted":              &t.FgHalfMuted,
+		"fg_subtle":                  &t.FgSubtle,
+		"fg_selected":                &t.FgSelected,
+		"border":                     &t.Border,
+		"border_focus":               &t.BorderFocus,
+		"success":                    &t.Success,
+		"error":                      &t.Error,
+		"warning":                    &t.Warning,
+		"info":                       &t.Info,
+		"white":                      &t.White,
+		"blue_light":                 &t.BlueLight,
+		"blue":                       &t.Blue,
+		"yellow":                     &t.Yellow,
+		"citron":                     &t.Citron,
+		"green":                      &t.Green,
+		"green_dark":                 &t.GreenDark,
+		"green_light":                &t.GreenLight,
+		"red":                        &t.Red,
+		"red_dark":                   &t.RedDark,
+		"red_light":                  &t.RedLight,
+		"cherry":                     &t.Cherry,
+		"diff_insert":                &t.DiffInsert,
+		"diff_insert_bg":             &t.DiffInsertBg,
+		"diff_insert_line_number_bg": &t.DiffInsertLineNumberBg,
+		"diff_delete":                &t.DiffDelete,
+		"diff_delete_bg":             &t.DiffDeleteBg,
+		"diff_delete_line_number_bg": &t.DiffDeleteLineNumberBg,
+	}
+}
+
+// colorFields returns the syntax colors by their name in theme files.
+func (s *SyntaxColors) colorFields() map[string]*color.Color {
+	return map[string]*color.Color{
+		"text":              &s.Text,
+		"error":             &s.Error,
+		"error_bg":          &s.ErrorBg,
+		"comment":           &s.Comment,
+		"comment_preproc":   &s.CommentPreproc,
+		"keyword":           &s.Keyword,
+		"keyword_reserved":  &s.KeywordReserved,
+		"keyword_namespace": &s.KeywordNamespace,
+		"keyword_type":      &s.KeywordType,
+		"operator":          &s.Operator,
+		"punctuation":       &s.Punctuation,
+		"name":              &s.Name,
+		"name_builtin":      &s.NameBuiltin,
+		"name_tag":          &s.NameTag,
+		"name_attribute":    &s.NameAttribute,
+		"name_class":        &s.NameClass,
+		"name_decorator":    &s.NameDecorator,
+		"name_function":     &s.NameFunction,
+		"number":            &s.Number,
+		"string":            &s.String,
+		"string_escape":     &s.StringEscape,
+		"deleted":           &s.Deleted,
+		"inserted":          &s.Inserted,
+		"subheading":        &s.Subheading,
+		"background":        &s.Background,
+	}
+}
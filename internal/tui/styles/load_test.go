@@ -0,0 +1,78 @@
+package styles
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/stretchr/testify/require"
+)
+
+func hex(t *testing.T, c color.Color) string {
+	t.Helper()
+	cc, ok := colorful.MakeColor(c)
+	require.True(t, ok)
+	return cc.Hex()
+}
+
+func TestLoadThemes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a-mine.json"), []byte(`{
+		"name": "mine",
+		"extends": "solarized-dark",
+		"chroma_style": "monokai",
+		"colors": {"primary": "#ff0000"},
+		"syntax": {"keyword": "#00ff00"}
+	}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b-light-mine.json"), []byte(`{
+		"name": "light-mine",
+		"extends": "mine",
+		"is_dark": false
+	}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c-broken.json"), []byte(`{
+		"name": "broken",
+		"colors": {"primary": "red"}
+	}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "d-unknown.json"), []byte(`{
+		"name": "unknown",
+		"colors": {"purple": "#800080"}
+	}`), 0o644))
+
+	m := NewManager()
+	err := m.LoadThemes(dir)
+	require.ErrorContains(t, err, `invalid color "red" for primary`)
+	require.ErrorContains(t, err, `unknown color "purple"`)
+	require.Equal(t, []string{
+		"charmtone",
+		"high-contrast",
+		"light",
+		"light-mine",
+		"mine",
+		"solarized-dark",
+		"solarized-light",
+	}, m.List())
+
+	require.NoError(t, m.SetTheme("light-mine"))
+	theme := m.Current()
+	require.False(t, theme.IsDark)
+	require.Equal(t, "monokai", theme.ChromaStyle)
+	require.Equal(t, "#ff0000", hex(t, theme.Primary))
+	require.Equal(t, "#00ff00", hex(t, theme.Syntax.Keyword))
+	require.Equal(t, "#002b36", hex(t, theme.BgBase))
+	require.Equal(t, "monokai", theme.S().Markdown.CodeBlock.Theme)
+
+	// The extended theme is unchanged.
+	require.Equal(t, "#6c71c4", hex(t, m.themes["solarized-dark"].Primary))
+}
+
+func TestLoadThemesMissingDir(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	require.NoError(t, m.LoadThemes(filepath.Join(t.TempDir(), "missing")))
+	require.Len(t, m.List(), 5)
+}
@@ -1,7 +1,16 @@
 package styles
 
 import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"os"
+
 	"github.com/charmbracelet/glamour/v2"
+	"github.com/charmbracelet/glamour/v2/ansi"
+	glamourStyles "github.com/charmbracelet/glamour/v2/styles"
+	"github.com/lucasb-eyer/go-colorful"
 )
 
 // Helper functions for style pointers
@@ -9,6 +18,11 @@ func boolPtr(b bool) *bool       { return &b }
 func stringPtr(s string) *string { return &s }
 func uintPtr(u uint) *uint       { return &u }
 
+func colorPtr(c color.Color) *string {
+	cc, _ := colorful.MakeColor(c)
+	return stringPtr(cc.Hex())
+}
+
 // returns a glamour TermRenderer configured with the current theme
 func GetMarkdownRenderer(width int) *glamour.TermRenderer {
 	t := CurrentTheme()
@@ -18,3 +32,253 @@ func GetMarkdownRenderer(width int) *glamour.TermRenderer {
 	)
 	return r
 }
+
+// markdownStyle returns the glamour style of the theme: the one named by
+// GlamourStyle if any, or the one built from the theme colors.
+func (t *Theme) markdownStyle() ansi.StyleConfig {
+	style := t.colorMarkdownStyle()
+	if t.GlamourStyle != "" {
+		var err error
+		if style, err = loadGlamourStyle(t.GlamourStyle); err != nil {
+			// User themes are validated when they are loaded, so this only
+			// happens when the style file was removed since.
+			slog.Warn("Failed to load glamour style", "theme", t.Name, "error", err)
+			style = t.colorMarkdownStyle()
+		}
+	}
+	if t.ChromaStyle != "" {
+		style.CodeBlock.Chroma = nil
+		style.CodeBlock.Theme = t.ChromaStyle
+	}
+	return style
+}
+
+// colorMarkdownStyle returns the glamour style built from the theme colors.
+func (t *Theme) colorMarkdownStyle() ansi.StyleConfig {
+	s := t.Syntax
+	return ansi.StyleConfig{
+		Document: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				// BlockPrefix: "\n",
+				// BlockSuffix: "\n",
+				Color: colorPtr(t.FgHalfMuted),
+			},
+			// Margin: uintPtr(defaultMargin),
+		},
+		BlockQuote: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{},
+			Indent:         uintPtr(1),
+			IndentToken:    stringPtr("│ "),
+		},
+		List: ansi.StyleList{
+			LevelIndent: defaultListIndent,
+		},
+		Heading: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				BlockSuffix: "\n",
+				Color:       colorPtr(t.Blue),
+				Bold:        boolPtr(true),
+			},
+		},
+		H1: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix:          " ",
+				Suffix:          " ",
+				Color:           colorPtr(t.Accent),
+				BackgroundColor: colorPtr(t.Primary),
+				Bold:            boolPtr(true),
+			},
+		},
+		H2: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix: "## ",
+			},
+		},
+		H3: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix: "### ",
+			},
+		},
+		H4: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix: "#### ",
+			},
+		},
+		H5: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix: "##### ",
+			},
+		},
+		H6: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix: "###### ",
+				Color:  colorPtr(t.GreenDark),
+				Bold:   boolPtr(false),
+			},
+		},
+		Strikethrough: ansi.StylePrimitive{
+			CrossedOut: boolPtr(true),
+		},
+		Emph: ansi.StylePrimitive{
+			Italic: boolPtr(true),
+		},
+		Strong: ansi.StylePrimitive{
+			Bold: boolPtr(true),
+		},
+		HorizontalRule: ansi.StylePrimitive{
+			Color:  colorPtr(t.Border),
+			Format: "\n--------\n",
+		},
+		Item: ansi.StylePrimitive{
+			BlockPrefix: "• ",
+		},
+		Enumeration: ansi.StylePrimitive{
+			BlockPrefix: ". ",
+		},
+		Task: ansi.StyleTask{
+			StylePrimitive: ansi.StylePrimitive{},
+			Ticked:         "[✓] ",
+			Unticked:       "[ ] ",
+		},
+		Link: ansi.StylePrimitive{
+			Color:     colorPtr(t.FgMuted),
+			Underline: boolPtr(true),
+		},
+		LinkText: ansi.StylePrimitive{
+			Color: colorPtr(t.GreenDark),
+			Bold:  boolPtr(true),
+		},
+		Image: ansi.StylePrimitive{
+			Color:     colorPtr(t.Secondary),
+			Underline: boolPtr(true),
+		},
+		ImageText: ansi.StylePrimitive{
+			Color:  colorPtr(t.FgMuted),
+			Format: "Image: {{.text}} →",
+		},
+		Code: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Prefix:          " ",
+				Suffix:          " ",
+				Color:           colorPtr(t.Red),
+				BackgroundColor: colorPtr(t.BgSubtle),
+			},
+		},
+		CodeBlock: ansi.StyleCodeBlock{
+			StyleBlock: ansi.StyleBlock{
+				StylePrimitive: ansi.StylePrimitive{
+					Color: colorPtr(t.Border),
+				},
+				Margin: uintPtr(defaultMargin),
+			},
+			Chroma: &ansi.Chroma{
+				Text: ansi.StylePrimitive{
+					Color: colorPtr(s.Text),
+				},
+				Error: ansi.StylePrimitive{
+					Color:           colorPtr(s.Error),
+					BackgroundColor: colorPtr(s.ErrorBg),
+				},
+				Comment: ansi.StylePrimitive{
+					Color: colorPtr(s.Comment),
+				},
+				CommentPreproc: ansi.StylePrimitive{
+					Color: colorPtr(s.CommentPreproc),
+				},
+				Keyword: ansi.StylePrimitive{
+					Color: colorPtr(s.Keyword),
+				},
+				KeywordReserved: ansi.StylePrimitive{
+					Color: colorPtr(s.KeywordReserved),
+				},
+				KeywordNamespace: ansi.StylePrimitive{
+					Color: colorPtr(s.KeywordNamespace),
+				},
+				KeywordType: ansi.StylePrimitive{
+					Color: colorPtr(s.KeywordType),
+				},
+				Operator: ansi.StylePrimitive{
+					Color: colorPtr(s.Operator),
+				},
+				Punctuation: ansi.StylePrimitive{
+					Color: colorPtr(s.Punctuation),
+				},
+				Name: ansi.StylePrimitive{
+					Color: colorPtr(s.Name),
+				},
+				NameBuiltin: ansi.StylePrimitive{
+					Color: colorPtr(s.NameBuiltin),
+				},
+				NameTag: ansi.StylePrimitive{
+					Color: colorPtr(s.NameTag),
+				},
+				NameAttribute: ansi.StylePrimitive{
+					Color: colorPtr(s.NameAttribute),
+				},
+				NameClass: ansi.StylePrimitive{
+					Color:     colorPtr(s.NameClass),
+					Underline: boolPtr(true),
+					Bold:      boolPtr(true),
+				},
+				NameDecorator: ansi.StylePrimitive{
+					Color: colorPtr(s.NameDecorator),
+				},
+				NameFunction: ansi.StylePrimitive{
+					Color: colorPtr(s.NameFunction),
+				},
+				LiteralNumber: ansi.StylePrimitive{
+					Color: colorPtr(s.Number),
+				},
+				LiteralString: ansi.StylePrimitive{
+					Color: colorPtr(s.String),
+				},
+				LiteralStringEscape: ansi.StylePrimitive{
+					Color: colorPtr(s.StringEscape),
+				},
+				GenericDeleted: ansi.StylePrimitive{
+					Color: colorPtr(s.Deleted),
+				},
+				GenericEmph: ansi.StylePrimitive{
+					Italic: boolPtr(true),
+				},
+				GenericInserted: ansi.StylePrimitive{
+					Color: colorPtr(s.Inserted),
+				},
+				GenericStrong: ansi.StylePrimitive{
+					Bold: boolPtr(true),
+				},
+				GenericSubheading: ansi.StylePrimitive{
+					Color: colorPtr(s.Subheading),
+				},
+				Background: ansi.StylePrimitive{
+					BackgroundColor: colorPtr(s.Background),
+				},
+			},
+		},
+		Table: ansi.StyleTable{
+			StyleBlock: ansi.StyleBlock{
+				StylePrimitive: ansi.StylePrimitive{},
+			},
+		},
+		DefinitionDescription: ansi.StylePrimitive{
+			BlockPrefix: "\n ",
+		},
+	}
+}
+
+// loadGlamourStyle returns the built-in glamour style with the given name,
+// or the style in the JSON file at that path.
+func loadGlamourStyle(name string) (ansi.StyleConfig, error) {
+	if style, ok := glamourStyles.DefaultStyles[name]; ok {
+		return *style, nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("unknown glamour style %q", name)
+	}
+	var style ansi.StyleConfig
+	if err := json.Unmarshal(data, &style); err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("invalid glamour style %s: %w", name, err)
+	}
+	return style, nil
+}
@@ -0,0 +1,364 @@
+package styles
+
+import (
+	"image/color"
+
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// builtinThemes returns the themes shipped with Crush.
+func builtinThemes() []*Theme {
+	return []*Theme{
+		NewCharmtoneTheme(),
+		NewLightTheme(),
+		NewHighContrastTheme(),
+		NewSolarizedDarkTheme(),
+		NewSolarizedLightTheme(),
+	}
+}
+
+func NewLightTheme() *Theme {
+	t := &Theme{
+		Name:   "light",
+		IsDark: false,
+
+		Primary:   lipgloss.Color("#6b50ff"),
+		Secondary: lipgloss.Color("#c2389b"),
+		Tertiary:  lipgloss.Color("#0b7a5e"),
+		Accent:    lipgloss.Color("#a86400"),
+
+		// Backgrounds
+		BgBase:        lipgloss.Color("#fafafa"),
+		BgBaseLighter: lipgloss.Color("#f0f0f2"),
+		BgSubtle:      lipgloss.Color("#e6e6ea"),
+		BgOverlay:     lipgloss.Color("#e0e0e6"),
+
+		// Foregrounds
+		FgBase:      lipgloss.Color("#2d2c35"),
+		FgMuted:     lipgloss.Color("#6e6c7a"),
+		FgHalfMuted: lipgloss.Color("#4a4857"),
+		FgSubtle:    lipgloss.Color("#8e8c99"),
+		FgSelected:  lipgloss.Color("#ffffff"),
+
+		// Borders
+		Border:      lipgloss.Color("#d0d0d8"),
+		BorderFocus: lipgloss.Color("#6b50ff"),
+
+		// Status
+		Success: lipgloss.Color("#0b7a3e"),
+		Error:   lipgloss.Color("#c4213f"),
+		Warning: lipgloss.Color("#8a6d00"),
+		Info:    lipgloss.Color("#1f6fc4"),
+
+		// Colors
+		White: lipgloss.Color("#ffffff"),
+
+		BlueLight: lipgloss.Color("#2f86d6"),
+		Blue:      lipgloss.Color("#1f6fc4"),
+
+		Yellow: lipgloss.Color("#8a6d00"),
+		Citron: lipgloss.Color("#9a8a00"),
+
+		Green:      lipgloss.Color("#0b7a5e"),
+		GreenDark:  lipgloss.Color("#0b7a3e"),
+		GreenLight: lipgloss.Color("#2a9d74"),
+
+		Red:      lipgloss.Color("#c4213f"),
+		RedDark:  lipgloss.Color("#a0142f"),
+		RedLight: lipgloss.Color("#d9546c"),
+		Cherry:   lipgloss.Color("#b3174f"),
+
+		// Diffs
+		DiffInsert:             lipgloss.Color("#2f7d32"),
+		DiffInsertBg:           lipgloss.Color("#e4f2e4"),
+		DiffInsertLineNumberBg: lipgloss.Color("#d6ead6"),
+		DiffDelete:             lipgloss.Color("#b3261e"),
+		DiffDeleteBg:           lipgloss.Color("#fae6e6"),
+		DiffDeleteLineNumberBg: lipgloss.Color("#f2d6d6"),
+
+		Syntax: SyntaxColors{
+			Text:             lipgloss.Color("#24292e"),
+			Error:            lipgloss.Color("#ffffff"),
+			ErrorBg:          lipgloss.Color("#d73a49"),
+			Comment:          lipgloss.Color("#6a737d"),
+			CommentPreproc:   lipgloss.Color("#d73a49"),
+			Keyword:          lipgloss.Color("#d73a49"),
+			KeywordReserved:  lipgloss.Color("#d73a49"),
+			KeywordNamespace: lipgloss.Color("#d73a49"),
+			KeywordType:      lipgloss.Color("#6f42c1"),
+			Operator:         lipgloss.Color("#d73a49"),
+			Punctuation:      lipgloss.Color("#24292e"),
+			Name:             lipgloss.Color("#24292e"),
+			NameBuiltin:      lipgloss.Color("#005cc5"),
+			NameTag:          lipgloss.Color("#22863a"),
+			NameAttribute:    lipgloss.Color("#6f42c1"),
+			NameClass:        lipgloss.Color("#6f42c1"),
+			NameDecorator:    lipgloss.Color("#e36209"),
+			NameFunction:     lipgloss.Color("#6f42c1"),
+			Number:           lipgloss.Color("#005cc5"),
+			String:           lipgloss.Color("#032f62"),
+			StringEscape:     lipgloss.Color("#22863a"),
+			Deleted:          lipgloss.Color("#b31d28"),
+			Inserted:         lipgloss.Color("#22863a"),
+			Subheading:       lipgloss.Color("#6a737d"),
+			Background:       lipgloss.Color("#f0f0f2"),
+		},
+	}
+	t.deriveStyles()
+
+	return t
+}
+
+func NewHighContrastTheme() *Theme {
+	t := &Theme{
+		Name:   "high-contrast",
+		IsDark: true,
+
+		Primary:   lipgloss.Color("#ffd700"),
+		Secondary: lipgloss.Color("#00e5ff"),
+		Tertiary:  lipgloss.Color("#00ff87"),
+		Accent:    lipgloss.Color("#ff9e00"),
+
+		// Backgrounds
+		BgBase:        lipgloss.Color("#000000"),
+		BgBaseLighter: lipgloss.Color("#121212"),
+		BgSubtle:      lipgloss.Color("#1f1f1f"),
+		BgOverlay:     lipgloss.Color("#000000"),
+
+		// Foregrounds
+		FgBase:      lipgloss.Color("#ffffff"),
+		FgMuted:     lipgloss.Color("#d0d0d0"),
+		FgHalfMuted: lipgloss.Color("#e8e8e8"),
+		FgSubtle:    lipgloss.Color("#b8b8b8"),
+		FgSelected:  lipgloss.Color("#000000"),
+
+		// Borders
+		Border:      lipgloss.Color("#ffffff"),
+		BorderFocus: lipgloss.Color("#ffd700"),
+
+		// Status
+		Success: lipgloss.Color("#00ff87"),
+		Error:   lipgloss.Color("#ff6b6b"),
+		Warning: lipgloss.Color("#ffd700"),
+		Info:    lipgloss.Color("#00e5ff"),
+
+		// Colors. White is the text drawn on colored backgrounds, black
+		// reads best on the bright colors of this theme.
+		White: lipgloss.Color("#000000"),
+
+		BlueLight: lipgloss.Color("#8ecbff"),
+		Blue:      lipgloss.Color("#5cb8ff"),
+
+		Yellow: lipgloss.Color("#ffd700"),
+		Citron: lipgloss.Color("#e6ff00"),
+
+		Green:      lipgloss.Color("#00ff87"),
+		GreenDark:  lipgloss.Color("#00d26a"),
+		GreenLight: lipgloss.Color("#8cffc1"),
+
+		Red:      lipgloss.Color("#ff6b6b"),
+		RedDark:  lipgloss.Color("#ff3b3b"),
+		RedLight: lipgloss.Color("#ff9e9e"),
+		Cherry:   lipgloss.Color("#ff3d8b"),
+
+		// Diffs
+		DiffInsert:             lipgloss.Color("#00ff87"),
+		DiffInsertBg:           lipgloss.Color("#003d1f"),
+		DiffInsertLineNumberBg: lipgloss.Color("#002a15"),
+		DiffDelete:             lipgloss.Color("#ff6b6b"),
+		DiffDeleteBg:           lipgloss.Color("#4a0000"),
+		DiffDeleteLineNumberBg: lipgloss.Color("#330000"),
+
+		Syntax: SyntaxColors{
+			Text:             lipgloss.Color("#ffffff"),
+			Error:            lipgloss.Color("#ffffff"),
+			ErrorBg:          lipgloss.Color("#d00000"),
+			Comment:          lipgloss.Color("#b8b8b8"),
+			CommentPreproc:   lipgloss.Color("#ff9e00"),
+			Keyword:          lipgloss.Color("#5cb8ff"),
+			KeywordReserved:  lipgloss.Color("#ff7ad9"),
+			KeywordNamespace: lipgloss.Color("#ff7ad9"),
+			KeywordType:      lipgloss.Color("#00e5ff"),
+			Operator:         lipgloss.Color("#ff9e00"),
+			Punctuation:      lipgloss.Color("#ffd700"),
+			Name:             lipgloss.Color("#ffffff"),
+			NameBuiltin:      lipgloss.Color("#ff7ad9"),
+			NameTag:          lipgloss.Color("#ff9e9e"),
+			NameAttribute:    lipgloss.Color("#c3a6ff"),
+			NameClass:        lipgloss.Color("#ffffff"),
+			NameDecorator:    lipgloss.Color("#e6ff00"),
+			NameFunction:     lipgloss.Color("#00ff87"),
+			Number:           lipgloss.Color("#8cffc1"),
+			String:           lipgloss.Color("#ffd27a"),
+			StringEscape:     lipgloss.Color("#00ffcc"),
+			Deleted:          lipgloss.Color("#ff6b6b"),
+			Inserted:         lipgloss.Color("#00ff87"),
+			Subheading:       lipgloss.Color("#d0d0d0"),
+			Background:       lipgloss.Color("#1f1f1f"),
+		},
+	}
+	t.deriveStyles()
+
+	return t
+}
+
+// solarized holds the colors of the Solarized palette.
+var solarized = struct {
+	base03, base02, base01, base00 color.Color
+	base0, base1, base2, base3     color.Color
+
+	yellow, orange, red, magenta color.Color
+	violet, blue, cyan, green    color.Color
+}{
+	base03: lipgloss.Color("#002b36"),
+	base02: lipgloss.Color("#073642"),
+	base01: lipgloss.Color("#586e75"),
+	base00: lipgloss.Color("#657b83"),
+	base0:  lipgloss.Color("#839496"),
+	base1:  lipgloss.Color("#93a1a1"),
+	base2:  lipgloss.Color("#eee8d5"),
+	base3:  lipgloss.Color("#fdf6e3"),
+
+	yellow:  lipgloss.Color("#b58900"),
+	orange:  lipgloss.Color("#cb4b16"),
+	red:     lipgloss.Color("#dc322f"),
+	magenta: lipgloss.Color("#d33682"),
+	violet:  lipgloss.Color("#6c71c4"),
+	blue:    lipgloss.Color("#268bd2"),
+	cyan:    lipgloss.Color("#2aa198"),
+	green:   lipgloss.Color("#859900"),
+}
+
+func NewSolarizedDarkTheme() *Theme {
+	s := solarized
+	t := newSolarizedTheme()
+	t.Name = "solarized-dark"
+	t.IsDark = true
+
+	t.BgBase = s.base03
+	t.BgBaseLighter = s.base02
+	t.BgSubtle = s.base02
+	t.BgOverlay = lipgloss.Color("#0b3c49")
+
+	t.FgBase = s.base0
+	t.FgMuted = s.base01
+	t.FgHalfMuted = s.base1
+	t.FgSubtle = s.base00
+	t.FgSelected = s.base3
+
+	t.Border = s.base01
+
+	t.DiffInsertBg = lipgloss.Color("#17392a")
+	t.DiffInsertLineNumberBg = lipgloss.Color("#113326")
+	t.DiffDeleteBg = lipgloss.Color("#3b2232")
+	t.DiffDeleteLineNumberBg = lipgloss.Color("#321d2c")
+
+	t.Syntax.Text = s.base0
+	t.Syntax.Comment = s.base01
+	t.Syntax.Punctuation = s.base0
+	t.Syntax.Name = s.base0
+	t.Syntax.Subheading = s.base01
+	t.Syntax.Background = s.base02
+	t.deriveStyles()
+
+	return t
+}
+
+func NewSolarizedLightTheme() *Theme {
+	s := solarized
+	t := newSolarizedTheme()
+	t.Name = "solarized-light"
+	t.IsDark = false
+
+	t.BgBase = s.base3
+	t.BgBaseLighter = s.base2
+	t.BgSubtle = s.base2
+	t.BgOverlay = lipgloss.Color("#e4ddc8")
+
+	t.FgBase = s.base00
+	t.FgMuted = s.base1
+	t.FgHalfMuted = s.base01
+	t.FgSubtle = s.base0
+	t.FgSelected = s.base3
+
+	t.Border = s.base1
+
+	t.DiffInsertBg = lipgloss.Color("#e8eccb")
+	t.DiffInsertLineNumberBg = lipgloss.Color("#dfe5bd")
+	t.DiffDeleteBg = lipgloss.Color("#f8dccf")
+	t.DiffDeleteLineNumberBg = lipgloss.Color("#f1d1c3")
+
+	t.Syntax.Text = s.base00
+	t.Syntax.Comment = s.base1
+	t.Syntax.Punctuation = s.base00
+	t.Syntax.Name = s.base00
+	t.Syntax.Subheading = s.base1
+	t.Syntax.Background = s.base2
+	t.deriveStyles()
+
+	return t
+}
+
+// newSolarizedTheme returns the accent colors shared by both Solarized
+// themes.
+func newSolarizedTheme() *Theme {
+	s := solarized
+	return &Theme{
+		Primary:   s.violet,
+		Secondary: s.magenta,
+		Tertiary:  s.cyan,
+		Accent:    s.yellow,
+
+		BorderFocus: s.blue,
+
+		// Status
+		Success: s.green,
+		Error:   s.red,
+		Warning: s.yellow,
+		Info:    s.blue,
+
+		// Colors
+		White: s.base3,
+
+		BlueLight: s.cyan,
+		Blue:      s.blue,
+
+		Yellow: s.yellow,
+		Citron: s.yellow,
+
+		Green:      s.green,
+		GreenDark:  s.green,
+		GreenLight: s.cyan,
+
+		Red:      s.red,
+		RedDark:  s.red,
+		RedLight: s.orange,
+		Cherry:   s.magenta,
+
+		// Diffs
+		DiffInsert: s.green,
+		DiffDelete: s.red,
+
+		Syntax: SyntaxColors{
+			Error:            s.base3,
+			ErrorBg:          s.red,
+			CommentPreproc:   s.orange,
+			Keyword:          s.green,
+			KeywordReserved:  s.green,
+			KeywordNamespace: s.orange,
+			KeywordType:      s.yellow,
+			Operator:         s.green,
+			NameBuiltin:      s.blue,
+			NameTag:          s.blue,
+			NameAttribute:    s.cyan,
+			NameClass:        s.blue,
+			NameDecorator:    s.orange,
+			NameFunction:     s.blue,
+			Number:           s.cyan,
+			String:           s.cyan,
+			StringEscape:     s.orange,
+			Deleted:          s.red,
+			Inserted:         s.green,
+		},
+	}
+}
@@ -3,6 +3,9 @@ package tui
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -10,10 +13,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/llm/provider"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/shell"
 	cmpChat "github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/chat/splash"
 	"github.com/charmbracelet/crush/internal/tui/components/completions"
@@ -22,13 +27,17 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/status"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/clear"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/checkpoints"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/compact"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/export"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/grants"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/permissions"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/quit"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/sessions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/themes"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/page/chat"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -147,6 +156,7 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					msg.CommandID,
 					msg.Content,
 					msg.ArgNames,
+					msg.OnSubmit,
 				),
 			},
 		)
@@ -181,6 +191,14 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Model: models.NewModelDialogCmp(),
 			},
 		)
+	case util.SwitchThemeMsg:
+		return a, util.CmdHandler(
+			dialogs.OpenDialogMsg{
+				Model: themes.NewThemeDialogCmp(),
+			},
+		)
+	case themes.ThemeSelectedMsg:
+		return a, a.handleThemeSelected(msg.Name)
 	// Compact
 	case util.CompactMsg:
 		return a, util.CmdHandler(dialogs.OpenDialogMsg{
@@ -188,6 +206,52 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		})
 	case util.ClearContextMsg:
 		return a, a.handleClearContextConfirmed(msg.SessionID)
+	case util.RevertCheckpointMsg:
+		return a, func() tea.Msg {
+			cps, err := a.app.Checkpoints(context.Background(), msg.SessionID)
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			if len(cps) == 0 {
+				return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No checkpoints in this session"}
+			}
+			return dialogs.OpenDialogMsg{
+				Model: checkpoints.NewCheckpointsDialogCmp(msg.SessionID, cps),
+			}
+		}
+	case checkpoints.CheckpointSelectedMsg:
+		return a, a.handleRevertCheckpoint(msg.SessionID, msg.MessageID)
+	case util.ForkSessionMsg:
+		return a, a.handleForkSession(msg.MessageID)
+	case util.ExportSessionMsg:
+		return a, util.CmdHandler(dialogs.OpenDialogMsg{
+			Model: export.NewExportDialogCmp(msg.SessionID),
+		})
+	case export.ExportFormatSelectedMsg:
+		return a, a.handleExportSession(msg.SessionID, msg.Format)
+	case util.ResetShellMsg:
+		shell.ResetSessionShell(msg.SessionID)
+		return a, util.ReportInfo("Shell reset")
+	case util.ManagePermissionsMsg:
+		return a, func() tea.Msg {
+			list, err := a.app.Permissions.ListGrants(context.Background())
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			if len(list) == 0 {
+				return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No permissions are always allowed"}
+			}
+			return dialogs.OpenDialogMsg{
+				Model: grants.NewGrantsDialogCmp(list),
+			}
+		}
+	case grants.RevokeGrantMsg:
+		return a, func() tea.Msg {
+			if err := a.app.Permissions.RevokeGrant(context.Background(), msg.Grant.ID); err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Permission revoked"}
+		}
 	case util.ExecutionStartMsg:
 		// Track execution start time for debugging metrics
 		a.executionStartTime[msg.SessionID] = time.Now()
@@ -254,10 +318,19 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.app.Permissions.Grant(msg.Permission)
 		case permissions.PermissionAllowForSession:
 			a.app.Permissions.GrantPersistent(msg.Permission)
+		case permissions.PermissionAllowForProject:
+			a.app.Permissions.GrantScoped(msg.Permission, permission.GrantScopeProject)
+		case permissions.PermissionAllowGlobally:
+			a.app.Permissions.GrantScoped(msg.Permission, permission.GrantScopeGlobal)
 		case permissions.PermissionDeny:
 			a.app.Permissions.Deny(msg.Permission)
 		}
 		return a, nil
+	// MCP Events
+	case pubsub.Event[agent.MCPEvent]:
+		if msg.Payload.State == agent.MCPStateAuthorizing && msg.Payload.AuthURL != "" {
+			cmds = append(cmds, util.ReportInfo(fmt.Sprintf("Authorize the %s MCP server in your browser: %s", msg.Payload.Name, msg.Payload.AuthURL)))
+		}
 	// Agent Events
 	case pubsub.Event[agent.AgentEvent]:
 		payload := msg.Payload
@@ -377,6 +450,27 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, tea.Batch(cmds...)
 }
 
+// handleThemeSelected switches to a theme, saves it in the config and
+// repaints the components.
+func (a *appModel) handleThemeSelected(name string) tea.Cmd {
+	if err := styles.DefaultManager().SetTheme(name); err != nil {
+		return util.ReportError(err)
+	}
+	cmds := []tea.Cmd{util.ReportInfo(fmt.Sprintf("Theme changed to %s", name))}
+	if err := config.Get().SetTheme(name); err != nil {
+		cmds = append(cmds, util.ReportError(fmt.Errorf("failed to save theme: %w", err)))
+	}
+	for p, page := range a.pages {
+		updated, pageCmd := page.Update(util.ThemeChangedMsg{})
+		if model, ok := updated.(util.Model); ok {
+			a.pages[p] = model
+		}
+		cmds = append(cmds, pageCmd)
+	}
+	cmds = append(cmds, a.handleWindowResize(a.wWidth, a.wHeight))
+	return tea.Batch(cmds...)
+}
+
 // handleWindowResize processes window resize events and updates all components.
 func (a *appModel) handleWindowResize(width, height int) tea.Cmd {
 	var cmds []tea.Cmd
@@ -463,6 +557,57 @@ func (a *appModel) handleClearContextConfirmed(sessionID string) tea.Cmd {
 	)
 }
 
+// handleForkSession forks the current session from a message and switches to
+// the fork.
+func (a *appModel) handleForkSession(messageID string) tea.Cmd {
+	if a.selectedSessionID == "" {
+		return nil
+	}
+	sessionID := a.selectedSessionID
+	return func() tea.Msg {
+		fork, err := a.app.Sessions.Fork(context.Background(), sessionID, messageID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("failed to fork session: %v", err)}
+		}
+		shell.InheritSessionShell(sessionID, fork.ID)
+		return cmpChat.SessionSelectedMsg(fork)
+	}
+}
+
+// handleExportSession saves the transcript of a session in the exports
+// directory of the data directory.
+func (a *appModel) handleExportSession(sessionID string, format app.ExportFormat) tea.Cmd {
+	return func() tea.Msg {
+		dir := filepath.Join(a.app.Config().Options.DataDirectory, "exports")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("failed to create exports directory: %v", err)}
+		}
+		path := filepath.Join(dir, sessionID+"."+string(format))
+		f, err := os.Create(path)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("failed to create export file: %v", err)}
+		}
+		defer f.Close()
+		if err := a.app.ExportSession(context.Background(), f, sessionID, format); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("failed to export session: %v", err)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Session exported to " + fsext.PrettyPath(path)}
+	}
+}
+
+// handleRevertCheckpoint reverts the session in the background, as it
+// rewrites files.
+func (a *appModel) handleRevertCheckpoint(sessionID, messageID string) tea.Cmd {
+	return func() tea.Msg {
+		restored, err := a.app.RevertSession(context.Background(), sessionID, messageID)
+		if err != nil {
+			slog.Error("Failed to revert session", "error", err)
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("failed to revert session: %v", err)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Reverted to checkpoint, restored %d files", len(restored))}
+	}
+}
+
 // handleKeyPressMsg processes keyboard input and routes to appropriate handlers.
 func (a *appModel) handleKeyPressMsg(msg tea.KeyPressMsg) tea.Cmd {
 	if a.completions.Open() {
@@ -659,6 +804,7 @@ func (a *appModel) View() tea.View {
 
 // New creates and initializes a new TUI application model.
 func New(app *app.App) tea.Model {
+	loadTheme()
 	chatPage := chat.New(app)
 	keyMap := DefaultKeyMap()
 	keyMap.pageBindings = chatPage.Bindings()
@@ -682,6 +828,19 @@ func New(app *app.App) tea.Model {
 	return model
 }
 
+// loadTheme loads the user themes and applies the configured theme.
+func loadTheme() {
+	manager := styles.DefaultManager()
+	if err := manager.LoadThemes(config.GlobalThemesDir()); err != nil {
+		slog.Warn("Failed to load themes", "error", err)
+	}
+	if tui := config.Get().Options.TUI; tui != nil && tui.Theme != "" {
+		if err := manager.SetTheme(tui.Theme); err != nil {
+			slog.Warn("Failed to apply theme", "error", err)
+		}
+	}
+}
+
 // updateDebugMetrics updates the debug panel with execution metrics
 func (a *appModel) updateDebugMetrics(agentEvent agent.AgentEvent) {
 	sessionID := a.selectedSessionID
@@ -66,6 +66,8 @@ type (
 	SwitchSessionsMsg    struct{}
 	NewSessionsMsg       struct{}
 	SwitchModelMsg       struct{}
+	SwitchThemeMsg       struct{}
+	ThemeChangedMsg      struct{}
 	QuitMsg              struct{}
 	OpenFilePickerMsg    struct{}
 	ToggleHelpMsg        struct{}
@@ -78,6 +80,21 @@ type (
 	ClearContextMsg struct {
 		SessionID string
 	}
+	RevertCheckpointMsg struct {
+		SessionID string
+	}
+	// ResetShellMsg replaces the shell of a session with a new one.
+	ResetShellMsg struct {
+		SessionID string
+	}
+	ManagePermissionsMsg struct{}
+	ExportSessionMsg     struct {
+		SessionID string
+	}
+	// ForkSessionMsg forks the current session from a message.
+	ForkSessionMsg struct {
+		MessageID string
+	}
 	CommandRunCustomMsg struct {
 		Content string
 	}
@@ -85,6 +102,9 @@ type (
 		CommandID string
 		Content   string
 		ArgNames  []string
+		// OnSubmit, when set, runs the command with the collected arguments
+		// instead of substituting them into Content.
+		OnSubmit func(args map[string]string) tea.Cmd
 	}
 	CloseArgumentsDialogMsg struct {
 		Submit    bool